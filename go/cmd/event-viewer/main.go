@@ -5,28 +5,13 @@ import (
 	"log"
 
 	tea "github.com/charmbracelet/bubbletea"
-	"github.com/charmbracelet/lipgloss"
 	"github.com/durapensa/ks/pkg/config"
 	"github.com/durapensa/ks/pkg/events"
+	"github.com/durapensa/ks/pkg/ui"
 )
 
-var (
-	titleStyle = lipgloss.NewStyle().
-			Bold(true).
-			Foreground(lipgloss.Color("205")).
-			MarginBottom(1)
-
-	selectedStyle = lipgloss.NewStyle().
-			Background(lipgloss.Color("237")).
-			Foreground(lipgloss.Color("255"))
-
-	normalStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("252"))
-
-	helpStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("241")).
-			MarginTop(1)
-)
+// theme is resolved in main, before the program takes over the terminal
+var theme = ui.New(ui.Dark)
 
 type model struct {
 	events   []*events.Event
@@ -114,7 +99,7 @@ func (m model) View() string {
 		return fmt.Sprintf("Error: %v\n\nPress q to quit.", m.error)
 	}
 
-	s := titleStyle.Render("Knowledge System Event Viewer") + "\n\n"
+	s := theme.Title.Render("Knowledge System Event Viewer") + "\n\n"
 
 	if len(m.events) == 0 {
 		s += "No events found.\n"
@@ -132,35 +117,39 @@ func (m model) View() string {
 
 		for i := start; i < len(m.events) && i < start+visibleEvents; i++ {
 			event := m.events[i]
+			// Time and type take 30 columns
 			line := fmt.Sprintf("%s | %-8s | %s",
 				event.FormatTime(),
 				event.Type,
-				truncate(event.Content, 60))
+				truncate(event.Content, ui.Width(m.width)-30))
 
 			if i == m.selected {
-				s += selectedStyle.Render(line) + "\n"
+				s += theme.Selected.Render(line) + "\n"
 			} else {
-				s += normalStyle.Render(line) + "\n"
+				s += theme.Normal.Render(line) + "\n"
 			}
 		}
 	}
 
-	s += helpStyle.Render("\n↑/↓: navigate • q: quit")
+	s += theme.Help.Render("\n↑/↓: navigate • q: quit")
 	return s
 }
 
 func truncate(s string, max int) string {
-	if len(s) <= max {
+	runes := []rune(s)
+	if len(runes) <= max {
 		return s
 	}
-	return s[:max-3] + "..."
+	return string(runes[:max-3]) + "..."
 }
 
 func main() {
 	// Simple integration test message
 	fmt.Println("Testing Go integration with knowledge system...")
-	fmt.Println("Loading events from hot.jsonl...\n")
+	fmt.Println("Loading events from hot.jsonl...")
+	fmt.Println()
 
+	theme = ui.New(ui.Auto)
 	p := tea.NewProgram(initialModel())
 	if _, err := p.Run(); err != nil {
 		log.Fatal(err)
@@ -0,0 +1,228 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/durapensa/ks/pkg/kg"
+)
+
+// analysis is the JSON form of kg analyze
+type analysis struct {
+	Database    string      `json:"database"`
+	EdgeTypes   []string    `json:"edge_types,omitempty"`
+	Directed    bool        `json:"directed"`
+	Concepts    int         `json:"concepts"`
+	Edges       int         `json:"edges"`
+	PageRank    []scoreJSON `json:"pagerank"`
+	Betweenness []scoreJSON `json:"betweenness"`
+	Components  [][]string  `json:"components"`
+	Communities communities `json:"communities"`
+}
+
+type scoreJSON struct {
+	ID     string  `json:"id"`
+	Name   string  `json:"name"`
+	Score  float64 `json:"score"`
+	Degree int     `json:"degree"`
+}
+
+type communities struct {
+	Modularity float64    `json:"modularity"`
+	Groups     [][]string `json:"groups"`
+}
+
+// routeJSON is the JSON form of kg path
+type routeJSON struct {
+	Concepts []string   `json:"concepts"`
+	Edges    []edgeJSON `json:"edges"`
+	Strength float64    `json:"strength"`
+}
+
+type edgeJSON struct {
+	Source   string  `json:"source"`
+	Target   string  `json:"target"`
+	Type     string  `json:"type"`
+	Strength float64 `json:"strength"`
+}
+
+func runAnalyze(opts options) error {
+	if len(opts.args) > 0 {
+		return usageError{fmt.Sprintf("analyze takes no arguments, got %q", opts.args[0])}
+	}
+	format, err := opts.reportFormat()
+	if err != nil {
+		return err
+	}
+	db, err := opts.open()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	g, err := db.Graph(opts.filter)
+	if err != nil {
+		return err
+	}
+
+	a := analysis{
+		Database:    db.Path,
+		EdgeTypes:   opts.filter.Types,
+		Directed:    opts.filter.Directed,
+		Concepts:    len(g.Concepts),
+		Edges:       len(g.Edges),
+		PageRank:    topScores(g, g.PageRank(), opts.top),
+		Betweenness: topScores(g, g.Betweenness(), opts.top),
+	}
+	for _, c := range g.Components() {
+		a.Components = append(a.Components, conceptNames(c))
+	}
+	groups, q := g.Communities()
+	a.Communities.Modularity = q
+	for _, c := range groups {
+		a.Communities.Groups = append(a.Communities.Groups, conceptNames(c))
+	}
+
+	if format == "json" {
+		return writeJSON(a)
+	}
+	writeAnalysisText(a)
+	return nil
+}
+
+func runPath(opts options) error {
+	if len(opts.args) != 2 {
+		return usageError{"path needs two concepts: FROM TO"}
+	}
+	format, err := opts.reportFormat()
+	if err != nil {
+		return err
+	}
+	db, err := opts.open()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	from, err := resolve(db, opts.args[0])
+	if err != nil {
+		return err
+	}
+	to, err := resolve(db, opts.args[1])
+	if err != nil {
+		return err
+	}
+	g, err := db.Graph(opts.filter)
+	if err != nil {
+		return err
+	}
+
+	var route *kg.Route
+	if opts.strongest {
+		route, err = g.StrongestPath(from.ID, to.ID)
+	} else {
+		route, err = g.ShortestPath(from.ID, to.ID)
+	}
+	if errors.Is(err, kg.ErrNoPath) {
+		return fmt.Errorf("%s to %s: %w", from.Name, to.Name, err)
+	}
+	if err != nil {
+		return err
+	}
+
+	// Undirected routes can take edges against their direction, so each
+	// edge is printed the way it is stored
+	r := routeJSON{Concepts: conceptNames(route.Concepts), Strength: route.Strength}
+	for _, e := range route.Edges {
+		r.Edges = append(r.Edges, edgeJSON{
+			Source:   g.Concept(e.SourceID).Name,
+			Target:   g.Concept(e.TargetID).Name,
+			Type:     e.Type,
+			Strength: e.Strength,
+		})
+	}
+	if format == "json" {
+		return writeJSON(r)
+	}
+
+	fmt.Printf("%s (%d steps, strength %.3f)\n", strings.Join(r.Concepts, " → "), len(r.Edges), r.Strength)
+	for _, e := range r.Edges {
+		fmt.Printf("  %s -%s-> %s  %.2f\n", e.Source, e.Type, e.Target, e.Strength)
+	}
+	return nil
+}
+
+func writeAnalysisText(a analysis) {
+	fmt.Println("Knowledge Graph Analysis")
+	fmt.Println("────────────────────────")
+	fmt.Printf("Database:     %s\n", a.Database)
+	types := "all"
+	if len(a.EdgeTypes) > 0 {
+		types = strings.Join(a.EdgeTypes, ", ")
+	}
+	direction := "undirected"
+	if a.Directed {
+		direction = "directed"
+	}
+	fmt.Printf("Edge types:   %s (%s)\n", types, direction)
+	fmt.Printf("Graph:        %d concepts, %d edges\n", a.Concepts, a.Edges)
+
+	fmt.Println("\nHubs by PageRank:")
+	writeScores(a.PageRank)
+	fmt.Println("\nBridges by betweenness:")
+	writeScores(a.Betweenness)
+
+	fmt.Printf("\nConnected components: %d\n", len(a.Components))
+	writeGroups(a.Components)
+	fmt.Printf("\nCommunities: %d (modularity %.3f)\n", len(a.Communities.Groups), a.Communities.Modularity)
+	writeGroups(a.Communities.Groups)
+}
+
+func writeScores(scores []scoreJSON) {
+	if len(scores) == 0 {
+		fmt.Println("  None")
+	}
+	for i, s := range scores {
+		fmt.Printf("  %2d. %-40s %.4f  degree %d\n", i+1, s.Name, s.Score, s.Degree)
+	}
+}
+
+// writeGroups lists groups of more than one concept and counts the others
+func writeGroups(groups [][]string) {
+	singles := 0
+	for i, group := range groups {
+		if len(group) == 1 {
+			singles++
+			continue
+		}
+		fmt.Printf("  %2d. (%d) %s\n", i+1, len(group), strings.Join(group, ", "))
+	}
+	if singles > 0 {
+		fmt.Printf("  %d concepts on their own\n", singles)
+	}
+}
+
+func writeJSON(v interface{}) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+func topScores(g *kg.Graph, scores []kg.Score, top int) []scoreJSON {
+	result := []scoreJSON{}
+	for _, s := range scores[:min(top, len(scores))] {
+		result = append(result, scoreJSON{ID: s.Concept.ID, Name: s.Concept.Name, Score: s.Value, Degree: g.Degree(s.Concept.ID)})
+	}
+	return result
+}
+
+func conceptNames(concepts []*kg.Concept) []string {
+	names := make([]string, len(concepts))
+	for i, c := range concepts {
+		names[i] = c.Name
+	}
+	return names
+}
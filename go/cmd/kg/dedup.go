@@ -0,0 +1,268 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/durapensa/ks/pkg/kg"
+)
+
+// proposalJSON is the JSON form of a kg dedup proposal
+type proposalJSON struct {
+	Keep      conceptJSON `json:"keep"`
+	Merge     conceptJSON `json:"merge"`
+	KeepName  string      `json:"keep_name"`
+	MergeName string      `json:"merge_name"`
+	Score     float64     `json:"score"`
+	Spelling  float64     `json:"spelling"`
+	Tokens    float64     `json:"tokens"`
+	MergeID   int64       `json:"merge_id,omitempty"` // Set by --apply
+}
+
+type conceptJSON struct {
+	ID     string  `json:"id"`
+	Name   string  `json:"name"`
+	Weight float64 `json:"weight"`
+}
+
+// mergeJSON is the JSON form of a recorded merge
+type mergeJSON struct {
+	ID         int64   `json:"id"`
+	KeptID     string  `json:"kept_id"`
+	KeptName   string  `json:"kept_name,omitempty"`
+	MergedID   string  `json:"merged_id"`
+	MergedName string  `json:"merged_name"`
+	Score      float64 `json:"score,omitempty"`
+	MergedAt   string  `json:"merged_at"`
+	UndoneAt   string  `json:"undone_at,omitempty"`
+}
+
+func runDedup(opts options) error {
+	if len(opts.args) > 0 {
+		return usageError{fmt.Sprintf("dedup takes no arguments, got %q", opts.args[0])}
+	}
+	format, err := opts.reportFormat()
+	if err != nil {
+		return err
+	}
+	// Merging everything is only safe at a threshold chosen for the graph
+	if opts.apply && opts.minScore == 0 {
+		return usageError{"dedup --apply needs an explicit --min-score; review the proposals first"}
+	}
+	minScore := opts.minScore
+	if minScore == 0 {
+		minScore = kg.DefaultMinScore
+	}
+	open := opts.open
+	if opts.apply {
+		open = opts.openWrite
+	}
+	db, err := open()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	proposals, err := db.ProposeMerges(minScore)
+	if err != nil {
+		return err
+	}
+	result := []proposalJSON{}
+	for _, p := range proposals {
+		result = append(result, proposalJSON{
+			Keep:      conceptJSON{ID: p.Keep.ID, Name: p.Keep.Name, Weight: p.Keep.Weight},
+			Merge:     conceptJSON{ID: p.Merge.ID, Name: p.Merge.Name, Weight: p.Merge.Weight},
+			KeepName:  p.KeepName,
+			MergeName: p.MergeName,
+			Score:     p.Score,
+			Spelling:  p.Spelling,
+			Tokens:    p.Tokens,
+		})
+	}
+
+	if opts.apply {
+		// A concept merged away earlier in the pass drops its other
+		// proposals; the graph is re-proposed on the next run
+		gone := map[string]bool{}
+		var applied []proposalJSON
+		for _, p := range result {
+			if gone[p.Keep.ID] || gone[p.Merge.ID] {
+				continue
+			}
+			m, err := db.MergeConcepts(p.Keep.ID, p.Merge.ID, p.Score)
+			if err != nil {
+				return err
+			}
+			gone[p.Merge.ID] = true
+			p.MergeID = m.ID
+			applied = append(applied, p)
+		}
+		result = append([]proposalJSON{}, applied...)
+	}
+
+	if format == "json" {
+		return writeJSON(result)
+	}
+	if len(result) == 0 {
+		fmt.Printf("No near-duplicates scoring %.2f or more\n", minScore)
+		return nil
+	}
+	for i, p := range result {
+		fmt.Printf("%3d. %.2f  %s → %s", i+1, p.Score, p.Merge.Name, p.Keep.Name)
+		if p.MergeName != p.Merge.Name || p.KeepName != p.Keep.Name {
+			fmt.Printf("  (as %q ~ %q)", p.MergeName, p.KeepName)
+		}
+		fmt.Printf("  spelling %.2f, words %.2f\n", p.Spelling, p.Tokens)
+		if p.MergeID != 0 {
+			fmt.Printf("     merged as #%d\n", p.MergeID)
+		}
+	}
+	if !opts.apply {
+		fmt.Printf("\nMerge one with: kg merge KEEP OTHER, or all of them with: kg dedup --apply --min-score %.2f\n", minScore)
+	}
+	return nil
+}
+
+func runMerge(opts options) error {
+	if len(opts.args) != 2 {
+		return usageError{"merge needs two concepts: KEEP OTHER"}
+	}
+	format, err := opts.reportFormat()
+	if err != nil {
+		return err
+	}
+	db, err := opts.openWrite()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	keep, err := resolve(db, opts.args[0])
+	if err != nil {
+		return err
+	}
+	other, err := resolve(db, opts.args[1])
+	if err != nil {
+		return err
+	}
+	if keep.ID == other.ID {
+		return fmt.Errorf("%s and %s are the same concept", opts.args[0], opts.args[1])
+	}
+	m, err := db.MergeConcepts(keep.ID, other.ID, 0)
+	if err != nil {
+		return err
+	}
+
+	if format == "json" {
+		return writeJSON(toMergeJSON(m, keep.Name))
+	}
+	fmt.Printf("Merged %s into %s as #%d (undo with: kg unmerge %d)\n", other.Name, keep.Name, m.ID, m.ID)
+	return nil
+}
+
+func runMerges(opts options) error {
+	if len(opts.args) > 0 {
+		return usageError{fmt.Sprintf("merges takes no arguments, got %q", opts.args[0])}
+	}
+	format, err := opts.reportFormat()
+	if err != nil {
+		return err
+	}
+	// The audit table may need its migration first
+	db, err := opts.openWrite()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	merges, err := db.Merges()
+	if err != nil {
+		return err
+	}
+	// A kept concept may since have been merged away itself
+	mergedNames := map[string]string{}
+	for _, m := range merges {
+		if m.UndoneAt.IsZero() {
+			mergedNames[m.MergedID] = m.MergedName
+		}
+	}
+	result := []mergeJSON{}
+	missing := map[string]bool{}
+	for _, m := range merges {
+		keptName := mergedNames[m.KeptID]
+		if c, err := db.Concept(m.KeptID); err == nil {
+			keptName = c.Name
+		} else if errors.Is(err, kg.ErrNotFound) {
+			missing[m.KeptID] = true
+		} else {
+			return err
+		}
+		result = append(result, toMergeJSON(m, keptName))
+	}
+
+	if format == "json" {
+		return writeJSON(result)
+	}
+	if len(result) == 0 {
+		fmt.Println("No merges")
+		return nil
+	}
+	for _, m := range result {
+		kept := m.KeptName
+		switch {
+		case kept == "":
+			kept = m.KeptID + " (deleted)"
+		case missing[m.KeptID] && m.UndoneAt == "":
+			kept += " (merged since)"
+		}
+		score := "by hand"
+		if m.Score > 0 {
+			score = fmt.Sprintf("score %.2f", m.Score)
+		}
+		fmt.Printf("#%-4d %s  %s → %s  (%s)", m.ID, m.MergedAt, m.MergedName, kept, score)
+		if m.UndoneAt != "" {
+			fmt.Printf("  undone %s", m.UndoneAt)
+		}
+		fmt.Println()
+	}
+	return nil
+}
+
+func runUnmerge(opts options) error {
+	if len(opts.args) != 1 {
+		return usageError{"unmerge needs a merge ID, see kg merges"}
+	}
+	id, err := strconv.ParseInt(opts.args[0], 10, 64)
+	if err != nil {
+		return usageError{fmt.Sprintf("invalid merge ID %q", opts.args[0])}
+	}
+	db, err := opts.openWrite()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	m, err := db.UndoMerge(id)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Restored %s from merge #%d\n", m.MergedName, m.ID)
+	return nil
+}
+
+func toMergeJSON(m *kg.Merge, keptName string) mergeJSON {
+	j := mergeJSON{
+		ID:         m.ID,
+		KeptID:     m.KeptID,
+		KeptName:   keptName,
+		MergedID:   m.MergedID,
+		MergedName: m.MergedName,
+		Score:      m.Score,
+		MergedAt:   m.MergedAt.UTC().Format(kg.TimeFormat),
+	}
+	if !m.UndoneAt.IsZero() {
+		j.UndoneAt = m.UndoneAt.UTC().Format(kg.TimeFormat)
+	}
+	return j
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/durapensa/ks/pkg/fsutil"
+	"github.com/durapensa/ks/pkg/kg"
+)
+
+func runExport(opts options) error {
+	if len(opts.args) > 0 {
+		return usageError{fmt.Sprintf("export takes no arguments, got %q", opts.args[0])}
+	}
+	format := opts.format
+	if format == "" && opts.output != "" {
+		format = kg.ExportExtensions[strings.ToLower(filepath.Ext(opts.output))]
+	}
+	if !contains(kg.ExportFormats, format) {
+		if format == "" {
+			return usageError{fmt.Sprintf("export needs --format (%s) or a known --output extension", strings.Join(kg.ExportFormats, ", "))}
+		}
+		return usageError{fmt.Sprintf("unknown --format %q (want %s)", format, strings.Join(kg.ExportFormats, ", "))}
+	}
+
+	db, err := opts.open()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	if opts.output == "" {
+		return db.Export(os.Stdout, format, opts.filter)
+	}
+	// Written whole, so a failed export leaves an earlier file intact
+	var buf bytes.Buffer
+	if err := db.Export(&buf, format, opts.filter); err != nil {
+		return err
+	}
+	if err := fsutil.WriteFileAtomic(opts.output, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("writing %s: %w", opts.output, err)
+	}
+	return nil
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
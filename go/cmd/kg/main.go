@@ -0,0 +1,274 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/durapensa/ks/pkg/config"
+	"github.com/durapensa/ks/pkg/kg"
+)
+
+func usage() {
+	fmt.Println("Description: Analyze, export and deduplicate the knowledge graph database")
+	fmt.Println("")
+	fmt.Println("Usage: kg SUBCOMMAND [options]")
+	fmt.Println("")
+	fmt.Println("Subcommands:")
+	fmt.Println("  analyze              Hubs (PageRank, betweenness), components and communities")
+	fmt.Println("  path FROM TO         Shortest path between two concepts (names, aliases or IDs)")
+	fmt.Println("  export               Write the graph as dot, graphml, gexf or cytoscape (Cytoscape.js JSON)")
+	fmt.Println("  dedup                Propose merges of near-duplicate concepts by spelling and shared words")
+	fmt.Println("  merge KEEP OTHER     Merge OTHER into KEEP: edges re-pointed, aliases and weights combined")
+	fmt.Println("  merges               List recorded merges, newest first")
+	fmt.Println("  unmerge ID           Undo a merge, restoring both concepts")
+	fmt.Println("")
+	fmt.Println("Options:")
+	fmt.Println("  --type TYPE          Only edges of TYPE, e.g. contradicts (repeat or comma-separate)")
+	fmt.Println("  --min-strength N     Only edges at least N strong")
+	fmt.Println("  --min-weight N       Only concepts at least N heavy, and the edges between them")
+	fmt.Println("  --directed           Follow edges from source to target only")
+	fmt.Println("  --experiment NAME    Use the graph of a conversation in KS_EXPERIMENTS_DIR")
+	fmt.Println("  --db PATH            Use this kg.db")
+	fmt.Println("  --format FMT         text or json (default: text); export: dot, graphml, gexf, cytoscape")
+	fmt.Println("  --output FILE        export: write FILE instead of stdout; its extension picks the format")
+	fmt.Println("                       (.dot/.gv, .graphml, .gexf, .json/.cyjs)")
+	fmt.Println("  --top N              Concepts per ranking for analyze (default: 10)")
+	fmt.Println("  --strongest          path: maximise the product of edge strengths instead")
+	fmt.Println("  --min-score N        dedup: similarity from 0 to 1 to propose a merge (default: 0.8)")
+	fmt.Println("  --apply              dedup: merge every proposal, best first; needs --min-score")
+	fmt.Println("  --help, -h           Show this help message")
+	fmt.Println("")
+	fmt.Println("Without --experiment or --db the graph is the conversation's own in a conversation")
+	fmt.Println("directory; elsewhere it is found like tools/kg/query: ./knowledge/kg.db if the current")
+	fmt.Println("directory has a knowledge directory, $KS_ROOT/knowledge/kg.db otherwise.")
+	fmt.Println("")
+	fmt.Println("Examples:")
+	fmt.Println("  kg analyze --experiment my-experiment --top 5")
+	fmt.Println("  kg analyze --type contradicts --format json")
+	fmt.Println("  kg path emergence complexity --strongest")
+	fmt.Println("  kg export --experiment my-experiment --min-weight 0.5 --output graph.gexf")
+	fmt.Println("  kg dedup --min-score 0.8")
+	fmt.Println("  kg merge emergence \"emergent behaviour\"")
+}
+
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
+
+// run dispatches a subcommand and returns the exit code
+func run(args []string) int {
+	if len(args) == 0 {
+		usage()
+		return 2
+	}
+	for _, arg := range args {
+		if arg == "--help" || arg == "-h" {
+			usage()
+			return 0
+		}
+	}
+
+	opts, err := parseOptions(args[1:])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "kg: %v\n", err)
+		return 2
+	}
+
+	switch args[0] {
+	case "analyze":
+		err = runAnalyze(opts)
+	case "path":
+		err = runPath(opts)
+	case "export":
+		err = runExport(opts)
+	case "dedup":
+		err = runDedup(opts)
+	case "merge":
+		err = runMerge(opts)
+	case "merges":
+		err = runMerges(opts)
+	case "unmerge":
+		err = runUnmerge(opts)
+	default:
+		fmt.Fprintf(os.Stderr, "kg: unknown subcommand %q\n", args[0])
+		return 2
+	}
+	var usageErr usageError
+	if errors.As(err, &usageErr) {
+		fmt.Fprintf(os.Stderr, "kg: %v\n", err)
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "kg: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// usageError is a mistake on the command line
+type usageError struct {
+	msg string
+}
+
+func (e usageError) Error() string {
+	return e.msg
+}
+
+// options are shared by every subcommand; args are the positional
+// arguments
+type options struct {
+	filter     kg.GraphFilter
+	experiment string
+	db         string
+	format     string
+	output     string
+	top        int
+	strongest  bool
+	minScore   float64 // 0 unless --min-score is given
+	apply      bool
+	args       []string
+}
+
+func parseOptions(args []string) (options, error) {
+	opts := options{top: 10}
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if !strings.HasPrefix(arg, "-") {
+			opts.args = append(opts.args, arg)
+			continue
+		}
+		name, value, hasValue := strings.Cut(arg, "=")
+		needValue := func() (string, error) {
+			if hasValue {
+				return value, nil
+			}
+			if i+1 >= len(args) {
+				return "", fmt.Errorf("%s requires a value", name)
+			}
+			i++
+			return args[i], nil
+		}
+
+		var err error
+		switch name {
+		case "--type":
+			if value, err = needValue(); err == nil {
+				for _, t := range strings.Split(value, ",") {
+					if t = strings.TrimSpace(t); t != "" {
+						opts.filter.Types = append(opts.filter.Types, t)
+					}
+				}
+			}
+		case "--min-weight":
+			if value, err = needValue(); err == nil {
+				opts.filter.MinWeight, err = strconv.ParseFloat(value, 64)
+				if err != nil {
+					err = fmt.Errorf("invalid --min-weight %q", value)
+				}
+			}
+		case "--min-strength":
+			if value, err = needValue(); err == nil {
+				opts.filter.MinStrength, err = strconv.ParseFloat(value, 64)
+				if err != nil {
+					err = fmt.Errorf("invalid --min-strength %q", value)
+				}
+			}
+		case "--directed":
+			opts.filter.Directed = true
+		case "--experiment":
+			opts.experiment, err = needValue()
+		case "--db":
+			opts.db, err = needValue()
+		case "--format":
+			opts.format, err = needValue()
+		case "--output", "-o":
+			opts.output, err = needValue()
+		case "--top":
+			if value, err = needValue(); err == nil {
+				opts.top, err = strconv.Atoi(value)
+				if err != nil || opts.top < 1 {
+					err = fmt.Errorf("invalid --top %q", value)
+				}
+			}
+		case "--strongest":
+			opts.strongest = true
+		case "--min-score":
+			if value, err = needValue(); err == nil {
+				opts.minScore, err = strconv.ParseFloat(value, 64)
+				if err != nil || opts.minScore <= 0 || opts.minScore > 1 {
+					err = fmt.Errorf("invalid --min-score %q", value)
+				}
+			}
+		case "--apply":
+			opts.apply = true
+		default:
+			err = fmt.Errorf("unknown option %s", name)
+		}
+		if err != nil {
+			return opts, err
+		}
+	}
+	return opts, nil
+}
+
+// reportFormat checks the --format of analyze and path
+func (o options) reportFormat() (string, error) {
+	switch o.format {
+	case "", "text":
+		return "text", nil
+	case "json":
+		return "json", nil
+	}
+	return "", usageError{fmt.Sprintf("unknown --format %q (want text, json)", o.format)}
+}
+
+// dbPath picks the graph the options point at
+func (o options) dbPath() (string, error) {
+	if o.db != "" {
+		return o.db, nil
+	}
+	cfg, err := config.LoadKSEnv()
+	if err != nil {
+		return "", err
+	}
+	if o.experiment != "" {
+		if cfg.ExperimentsDir == "" {
+			return "", fmt.Errorf("KS_EXPERIMENTS_DIR is not set")
+		}
+		return kg.ExperimentPath(cfg, o.experiment), nil
+	}
+	return kg.Path(cfg), nil
+}
+
+// open opens the graph read-only
+func (o options) open() (*kg.DB, error) {
+	path, err := o.dbPath()
+	if err != nil {
+		return nil, err
+	}
+	return kg.OpenReadOnly(path)
+}
+
+// openWrite opens an existing graph for changes, migrating it first
+func (o options) openWrite() (*kg.DB, error) {
+	path, err := o.dbPath()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("opening knowledge graph: %w", err)
+	}
+	return kg.Open(path)
+}
+
+// resolve finds a concept by ID, name or alias
+func resolve(db *kg.DB, ref string) (*kg.Concept, error) {
+	c, err := db.Concept(ref)
+	if errors.Is(err, kg.ErrNotFound) {
+		return db.ConceptByName(ref)
+	}
+	return c, err
+}
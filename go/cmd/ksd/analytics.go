@@ -0,0 +1,184 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/durapensa/ks/pkg/analytics"
+	"github.com/durapensa/ks/pkg/config"
+)
+
+// analyticsWindows are the selectable windows in days; 0 covers every event
+var analyticsWindows = []int{7, 14, 30, 90, 0}
+
+// defaultAnalyticsWindow is the index of the 30 day window
+const defaultAnalyticsWindow = 2
+
+// Analytics layout
+const (
+	analyticsTopN   = 8
+	analyticsBarMax = 30
+)
+
+// analyticsView is the state of the Analytics screen
+type analyticsView struct {
+	window  int
+	stats   *analytics.Stats
+	err     error
+	loading bool
+}
+
+func (a analyticsView) days() int {
+	return analyticsWindows[a.window]
+}
+
+type analyticsMsg struct {
+	stats *analytics.Stats
+	err   error
+}
+
+// Recompute the statistics of the last days days from the hot log, archives
+// and the [Claude] stream
+func loadAnalytics(cfg *config.Config, days int) tea.Cmd {
+	return func() tea.Msg {
+		stats, err := analytics.New(cfg).Compute(days, time.Now())
+		return analyticsMsg{stats: stats, err: err}
+	}
+}
+
+// handleAnalyticsKey switches the window; everything else falls through to
+// the global keys
+func (m model) handleAnalyticsKey(key string) (model, tea.Cmd, bool) {
+	a := &m.analytics
+	switch key {
+	case "left", "-":
+		if a.window > 0 {
+			a.window--
+			a.loading = true
+			return m, loadAnalytics(m.config, a.days()), true
+		}
+		return m, nil, true
+	case "right", "+", "=":
+		if a.window < len(analyticsWindows)-1 {
+			a.window++
+			a.loading = true
+			return m, loadAnalytics(m.config, a.days()), true
+		}
+		return m, nil, true
+	case "f":
+		a.loading = true
+		return m, loadAnalytics(m.config, a.days()), true
+	}
+	return m, nil, false
+}
+
+func (m model) renderAnalytics() string {
+	a := m.analytics
+	content := headerStyle.Render("KNOWLEDGE SYSTEM ANALYTICS") + "\n"
+
+	var windows []string
+	for i, days := range analyticsWindows {
+		label := "all"
+		if days > 0 {
+			label = fmt.Sprintf("%dd", days)
+		}
+		if i == a.window {
+			windows = append(windows, readyStyle.Render("["+label+"]"))
+		} else {
+			windows = append(windows, statusStyle.Render(" "+label+" "))
+		}
+	}
+	content += labelStyle.Render("Window: ") + strings.Join(windows, " ")
+	if a.loading {
+		content += statusStyle.Render("  recalculating...")
+	}
+	content += "\n\n"
+
+	if a.err != nil {
+		return content + pendingStyle.Render("Error: "+a.err.Error()) + "\n"
+	}
+	s := a.stats
+	if s == nil {
+		return content + statusStyle.Render("Loading...") + "\n"
+	}
+	if s.Total == 0 {
+		return content + statusStyle.Render("No events in this window") + "\n"
+	}
+
+	content += fmt.Sprintf("Events: %s   Human: %d (%s)   Claude: %d (%s)\n",
+		readyStyle.Render(fmt.Sprint(s.Total)),
+		s.Human, percent(s.Human, s.Total),
+		s.Claude, percent(s.Claude, s.Total))
+	if s.Skipped > 0 {
+		content += statusStyle.Render(fmt.Sprintf("%d unreadable lines skipped", s.Skipped)) + "\n"
+	}
+	content += "\n"
+
+	// Timelines, one character per day up to the screen width
+	sparklineWidth := m.layoutWidth() - 12
+	var total, human, claude []int
+	for _, day := range s.PerDay {
+		total = append(total, day.Total())
+		human = append(human, day.Human)
+		claude = append(claude, day.Claude)
+	}
+	content += labelStyle.Render("PER DAY") + "\n"
+	content += "  All     " + highlightStyle.Render(analytics.Sparkline(total, sparklineWidth)) + "\n"
+	content += "  Human   " + analytics.Sparkline(human, sparklineWidth) + "\n"
+	content += "  Claude  " + analytics.Sparkline(claude, sparklineWidth) + "\n"
+	if len(s.PerDay) > 0 {
+		first := s.PerDay[0].Date.Format("01-02")
+		last := s.PerDay[len(s.PerDay)-1].Date.Format("01-02")
+		span := min(len(s.PerDay), sparklineWidth)
+		gap := max(span-len(first)-len(last), 1)
+		content += "          " + statusStyle.Render(first+strings.Repeat(" ", gap)+last) + "\n"
+	}
+	content += "\n"
+
+	content += labelStyle.Render("PER HOUR") + "\n"
+	content += "  All     " + highlightStyle.Render(analytics.Sparkline(s.PerHour[:], 0)) + "\n"
+	content += "          " + statusStyle.Render("0     6     12    18   23") + "\n\n"
+
+	// Breakdowns
+	content += labelStyle.Render("TYPES") + "\n"
+	content += renderCounts(s.Types, s.Total)
+	content += "\n" + labelStyle.Render("TOP TOPICS") + "\n"
+	content += renderCounts(s.Topics, s.Total)
+
+	content += "\n" + labelStyle.Render("TAG CO-OCCURRENCE") + "\n"
+	if len(s.TagPairs) == 0 {
+		content += statusStyle.Render("  No events with more than one tag") + "\n"
+	}
+	for _, pair := range s.TagPairs[:min(len(s.TagPairs), analyticsTopN)] {
+		content += fmt.Sprintf("  %-40s %d\n", truncate(pair.A+" + "+pair.B, 40), pair.Count)
+	}
+
+	return content
+}
+
+// renderCounts lists the top counts with a bar relative to the largest
+func renderCounts(counts []analytics.Count, total int) string {
+	if len(counts) == 0 {
+		return statusStyle.Render("  None") + "\n"
+	}
+	peak := counts[0].Count
+	var content string
+	for _, c := range counts[:min(len(counts), analyticsTopN)] {
+		bar := strings.Repeat("█", max(c.Count*analyticsBarMax/peak, 1))
+		content += fmt.Sprintf("  %-20s %5d %s %s\n",
+			truncate(c.Name, 20), c.Count, readyStyle.Render(bar), statusStyle.Render(percent(c.Count, total)))
+	}
+	if len(counts) > analyticsTopN {
+		content += statusStyle.Render(fmt.Sprintf("  ... %d more", len(counts)-analyticsTopN)) + "\n"
+	}
+	return content
+}
+
+func percent(n, total int) string {
+	if total == 0 {
+		return "0%"
+	}
+	return fmt.Sprintf("%d%%", n*100/total)
+}
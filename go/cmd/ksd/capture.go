@@ -0,0 +1,342 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/durapensa/ks/pkg/config"
+	"github.com/durapensa/ks/pkg/events"
+	"github.com/durapensa/ks/pkg/triggers"
+)
+
+// Capture form fields, in focus order
+type captureField int
+
+const (
+	captureType captureField = iota
+	captureTopic
+	captureContent
+	captureTags
+	captureMetadata
+	captureFieldCount
+)
+
+// maxTopicSuggestions bounds the autocomplete list under the topic field
+const maxTopicSuggestions = 5
+
+// captureForm is the state of the Capture screen
+type captureForm struct {
+	editing  bool
+	focus    captureField
+	typeIdx  int
+	topic    string
+	content  string
+	tags     string
+	metadata string
+
+	topics     []string
+	suggestion int
+	note       string
+	err        error
+}
+
+// Capture screen messages
+type topicsMsg struct {
+	topics []string
+}
+
+type captureSavedMsg struct {
+	note        string
+	triggerNote string
+}
+
+type captureErrorMsg struct {
+	err error
+}
+
+// Load existing topics from the hot log for autocomplete
+func loadTopics(cfg *config.Config) tea.Cmd {
+	return func() tea.Msg {
+		topics, err := events.Topics(cfg.HotLog)
+		if err != nil {
+			return captureErrorMsg{fmt.Errorf("reading topics: %w", err)}
+		}
+		return topicsMsg{topics: topics}
+	}
+}
+
+// Write the event through the native writer and run the event triggers,
+// as tools/capture/events does
+func saveCapture(cfg *config.Config, event *events.Event) tea.Cmd {
+	return func() tea.Msg {
+		path, err := events.NewWriter(cfg.HotLog, cfg.DerivedDir).Write(event)
+		if err != nil {
+			return captureErrorMsg{err}
+		}
+
+		note := fmt.Sprintf("Event logged: %s/%s", event.Type, event.Topic)
+		if path != cfg.HotLog {
+			note = fmt.Sprintf("Event logged to stream: %s/%s", event.Type, event.Topic)
+		}
+
+		var triggerNote string
+		runs, err := triggers.New(cfg).Check()
+		if err != nil {
+			triggerNote = fmt.Sprintf("Trigger check failed: %v", err)
+		} else if len(runs) > 0 {
+			var names []string
+			for _, run := range runs {
+				names = append(names, run.Analysis.Label)
+			}
+			triggerNote = "Spawned " + strings.Join(names, ", ") + " analysis"
+		}
+		return captureSavedMsg{note: note, triggerNote: triggerNote}
+	}
+}
+
+// build validates the form and turns it into an event
+func (f *captureForm) build() (*events.Event, error) {
+	topic := strings.TrimSpace(f.topic)
+	if topic == "" {
+		return nil, fmt.Errorf("topic is required")
+	}
+	content := strings.TrimSpace(f.content)
+	if content == "" {
+		return nil, fmt.Errorf("content is required")
+	}
+
+	event := &events.Event{
+		Type:    events.ValidTypes[f.typeIdx],
+		Topic:   topic,
+		Content: content,
+	}
+	for _, tag := range strings.Split(f.tags, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			event.Tags = append(event.Tags, tag)
+		}
+	}
+
+	metadata, err := parseMetadata(f.metadata)
+	if err != nil {
+		return nil, err
+	}
+	event.Metadata = metadata
+	return event, nil
+}
+
+// parseMetadata reads comma-separated key=value pairs
+func parseMetadata(input string) (map[string]interface{}, error) {
+	metadata := map[string]interface{}{}
+	for _, pair := range strings.Split(input, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(pair, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("metadata %q is not key=value", pair)
+		}
+		metadata[key] = strings.TrimSpace(value)
+	}
+	return metadata, nil
+}
+
+// suggestions returns known topics matching the typed prefix, then any
+// containing it
+func (f *captureForm) suggestions() []string {
+	typed := strings.ToLower(strings.TrimSpace(f.topic))
+	if typed == "" {
+		if len(f.topics) > maxTopicSuggestions {
+			return f.topics[:maxTopicSuggestions]
+		}
+		return f.topics
+	}
+
+	var prefix, contains []string
+	for _, topic := range f.topics {
+		lower := strings.ToLower(topic)
+		switch {
+		case lower == typed:
+		case strings.HasPrefix(lower, typed):
+			prefix = append(prefix, topic)
+		case strings.Contains(lower, typed):
+			contains = append(contains, topic)
+		}
+	}
+	matches := append(prefix, contains...)
+	if len(matches) > maxTopicSuggestions {
+		matches = matches[:maxTopicSuggestions]
+	}
+	return matches
+}
+
+// field returns the text field under focus, or nil for the type picker
+func (f *captureForm) field() *string {
+	switch f.focus {
+	case captureTopic:
+		return &f.topic
+	case captureContent:
+		return &f.content
+	case captureTags:
+		return &f.tags
+	case captureMetadata:
+		return &f.metadata
+	}
+	return nil
+}
+
+func (f *captureForm) moveFocus(delta int) {
+	f.focus = captureField((int(f.focus) + delta + int(captureFieldCount)) % int(captureFieldCount))
+	f.suggestion = 0
+}
+
+// handleCaptureKey handles every key while the capture form is being edited
+func (m model) handleCaptureKey(msg tea.KeyMsg) (model, tea.Cmd) {
+	f := &m.capture
+
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc":
+		f.editing = false
+		return m, nil
+	case "ctrl+s":
+		event, err := f.build()
+		if err != nil {
+			f.err = err
+			return m, nil
+		}
+		f.err = nil
+		return m, saveCapture(m.config, event)
+	case "shift+tab":
+		f.moveFocus(-1)
+		return m, nil
+	case "tab":
+		// Complete the topic first, then move on
+		if f.focus == captureTopic {
+			if matches := f.suggestions(); f.suggestion < len(matches) && matches[f.suggestion] != f.topic {
+				f.topic = matches[f.suggestion]
+				f.suggestion = 0
+				return m, nil
+			}
+		}
+		f.moveFocus(1)
+		return m, nil
+	}
+
+	switch f.focus {
+	case captureType:
+		switch msg.String() {
+		case "left", "up":
+			f.typeIdx = (f.typeIdx + len(events.ValidTypes) - 1) % len(events.ValidTypes)
+		case "right", "down", " ":
+			f.typeIdx = (f.typeIdx + 1) % len(events.ValidTypes)
+		case "enter":
+			f.moveFocus(1)
+		}
+		return m, nil
+	case captureTopic:
+		switch msg.String() {
+		case "up":
+			if f.suggestion > 0 {
+				f.suggestion--
+			}
+			return m, nil
+		case "down":
+			if f.suggestion < len(f.suggestions())-1 {
+				f.suggestion++
+			}
+			return m, nil
+		}
+	}
+
+	text := f.field()
+	switch msg.Type {
+	case tea.KeyEnter:
+		if f.focus == captureContent {
+			*text += "\n"
+		} else {
+			f.moveFocus(1)
+		}
+	case tea.KeyBackspace:
+		if runes := []rune(*text); len(runes) > 0 {
+			*text = string(runes[:len(runes)-1])
+		}
+	case tea.KeySpace:
+		*text += " "
+	case tea.KeyRunes:
+		*text += string(msg.Runes)
+	}
+	if f.focus == captureTopic {
+		f.suggestion = 0
+	}
+	return m, nil
+}
+
+func (m model) renderCapture() string {
+	f := &m.capture
+	content := headerStyle.Render("CAPTURE EVENT") + "\n\n"
+
+	label := func(field captureField, name string) string {
+		if f.editing && f.focus == field {
+			return selectedStyle.Render("▸ " + name)
+		}
+		return normalStyle.Render("  " + name)
+	}
+	cursor := func(field captureField) string {
+		if f.editing && f.focus == field {
+			return "█"
+		}
+		return ""
+	}
+
+	// Type picker
+	var types []string
+	for i, t := range events.ValidTypes {
+		if i == f.typeIdx {
+			types = append(types, readyStyle.Render("["+t+"]"))
+		} else {
+			types = append(types, statusStyle.Render(" "+t+" "))
+		}
+	}
+	content += label(captureType, "Type:     ") + " " + strings.Join(types, " ") + "\n"
+
+	// Topic with autocomplete
+	content += label(captureTopic, "Topic:    ") + " " + f.topic + cursor(captureTopic) + "\n"
+	if f.editing && f.focus == captureTopic {
+		for i, topic := range f.suggestions() {
+			if i == f.suggestion {
+				content += "             " + selectedStyle.Render(topic) + "\n"
+			} else {
+				content += "             " + statusStyle.Render(topic) + "\n"
+			}
+		}
+	}
+
+	// Multi-line content
+	lines := strings.Split(f.content+cursor(captureContent), "\n")
+	content += label(captureContent, "Content:  ") + " " + lines[0] + "\n"
+	for _, line := range lines[1:] {
+		content += "             " + line + "\n"
+	}
+
+	content += label(captureTags, "Tags:     ") + " " + f.tags + cursor(captureTags) + "\n"
+	content += label(captureMetadata, "Metadata: ") + " " + f.metadata + cursor(captureMetadata) + "\n"
+	content += statusStyle.Render("             tags: a, b  •  metadata: key=value, key=value") + "\n"
+
+	if f.err != nil {
+		content += "\n" + pendingStyle.Render("Error: "+f.err.Error()) + "\n"
+	}
+	if f.note != "" {
+		content += "\n" + readyStyle.Render(f.note) + "\n"
+	}
+	if m.triggerNote != "" {
+		content += statusStyle.Render(m.triggerNote) + "\n"
+	}
+	content += "\n" + statusStyle.Render("Writes to "+filepath.Base(m.config.HotLog)+" in "+filepath.Dir(m.config.HotLog))
+
+	return content
+}
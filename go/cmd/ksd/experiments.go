@@ -0,0 +1,245 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/durapensa/ks/pkg/config"
+	"github.com/durapensa/ks/pkg/events"
+	"github.com/durapensa/ks/pkg/index"
+	"github.com/durapensa/ks/pkg/logex"
+)
+
+// experimentsRefresh is how often the Experiments screen re-reads the logs
+// while it is open
+const experimentsRefresh = 2 * time.Second
+
+// experimentsView is the state of the Experiments screen
+type experimentsView struct {
+	list   []*logex.Experiment
+	cursor int
+	err    error
+	note   string
+	// tick tags refresh timers so only the latest loop keeps running
+	tick int
+}
+
+// Experiments screen messages
+type experimentsMsg struct {
+	list []*logex.Experiment
+	err  error
+}
+
+type experimentsTickMsg struct {
+	tick int
+}
+
+// contextMsg carries the configuration of a conversation to switch to
+type contextMsg struct {
+	config *config.Config
+	err    error
+}
+
+// Discover the logex conversations under KS_EXPERIMENTS_DIR
+func loadExperiments(cfg *config.Config) tea.Cmd {
+	return func() tea.Msg {
+		list, err := logex.Discover(cfg)
+		return experimentsMsg{list: list, err: err}
+	}
+}
+
+func experimentsTick(tick int) tea.Cmd {
+	return tea.Tick(experimentsRefresh, func(time.Time) tea.Msg {
+		return experimentsTickMsg{tick: tick}
+	})
+}
+
+// openExperiments shows the screen and starts a fresh refresh loop
+func (m model) openExperiments() (model, tea.Cmd) {
+	m.currentScreen = experimentsScreen
+	m.experiments.tick++
+	return m, tea.Batch(loadExperiments(m.home), experimentsTick(m.experiments.tick))
+}
+
+// Resolve the configuration of a directory the way ksd started from it
+// would
+func loadContext(dir string) tea.Cmd {
+	return func() tea.Msg {
+		cfg, err := config.LoadKSEnvAt(dir)
+		return contextMsg{config: cfg, err: err}
+	}
+}
+
+// switchContext points every screen at cfg without restarting: the hot log
+// follower and search index are reopened and per-context state is dropped
+func (m model) switchContext(cfg *config.Config) (model, tea.Cmd) {
+	if m.follower != nil {
+		m.follower.Close()
+	}
+	follower, err := events.FollowFromEnd(cfg.HotLog)
+	if err != nil {
+		m.experiments.note = fmt.Sprintf("Not following %s: %v", cfg.HotLog, err)
+		follower = nil
+	}
+	searchIndex, indexErr := index.Open(searchIndexPath(cfg))
+	previous := m.searchIndex
+	saveIndex := func() tea.Msg {
+		if previous == nil {
+			return nil
+		}
+		return indexUpdatedMsg{err: previous.Save()}
+	}
+
+	m.config = cfg
+	m.follower = follower
+	m.searchIndex = searchIndex
+	m.indexErr = indexErr
+	m.dashboard = dashboardData{}
+	m.searchResults, m.searchTotal, m.searchTerm = nil, 0, ""
+	m.search = searchView{filters: m.prefs.searchFilters()}
+	m.processes, m.processCursor, m.processNote = nil, 0, ""
+	m.triggerNote = ""
+	m.capture = captureForm{}
+	m.review = reviewView{options: m.review.options}
+	m.analytics = analyticsView{window: m.analytics.window}
+	m.panes = panesView{layout: m.panes.layout, path: m.panes.path}
+	m.kg = kgView{}
+	m.currentScreen = dashboardScreen
+
+	return m, tea.Batch(loadDashboardDataWithConfig(cfg), waitForEvent(follower), saveIndex)
+}
+
+// handleExperimentsKey handles keys specific to the Experiments screen
+func (m model) handleExperimentsKey(key string) (model, tea.Cmd, bool) {
+	x := &m.experiments
+	switch key {
+	case "up":
+		if x.cursor > 0 {
+			x.cursor--
+		}
+		return m, nil, true
+	case "down":
+		if x.cursor < len(x.list)-1 {
+			x.cursor++
+		}
+		return m, nil, true
+	case "enter":
+		if x.cursor < len(x.list) {
+			return m, loadContext(x.list[x.cursor].Dir), true
+		}
+		return m, nil, true
+	case "l":
+		if x.cursor < len(x.list) {
+			next, cmd := m.openTranscript(x.list[x.cursor].Dir, x.list[x.cursor].Config)
+			return next, cmd, true
+		}
+		return m, nil, true
+	case "esc":
+		if m.config != m.home {
+			next, cmd := m.switchContext(m.home)
+			return next, cmd, true
+		}
+		return m, nil, true
+	case "f":
+		return m, loadExperiments(m.home), true
+	}
+	return m, nil, false
+}
+
+func (m model) renderExperiments() string {
+	x := m.experiments
+	content := headerStyle.Render("EXPERIMENTS") + "\n"
+	content += statusStyle.Render("Conversations in "+m.home.ExperimentsDir) + "\n\n"
+
+	if x.err != nil {
+		return content + pendingStyle.Render("Error: "+x.err.Error()) + "\n"
+	}
+	if len(x.list) == 0 {
+		return content + statusStyle.Render("No directories with "+logex.NewLayout(m.home).ConfigFile+" found") + "\n"
+	}
+
+	now := time.Now()
+	content += labelStyle.Render(fmt.Sprintf("  %-28s %-12s %-8s %-10s %7s  %s", "NAME", "STATE", "TURNS", "SPEAKER", "EVENTS", "ORCHESTRATION LOG")) + "\n"
+	for i, e := range x.list {
+		turns := fmt.Sprintf("%d/%d", e.Turns, e.TurnLimit)
+		if e.TurnLimit == 0 {
+			turns = fmt.Sprintf("%d/?", e.Turns)
+		}
+		speaker := e.LastSpeaker
+		if speaker == "" {
+			speaker = "-"
+		}
+
+		marker := "  "
+		if m.config.IsConversation && m.config.ConversationDir == e.Dir {
+			marker = "● "
+		}
+		line := fmt.Sprintf("%s%-28s %-12s %-8s %-10s %7d  %s",
+			marker, truncate(e.Name, 28), e.State(now), turns, truncate(speaker, 10), e.Events, logStatus(e, now))
+
+		switch {
+		case i == x.cursor:
+			content += selectedStyle.Render(line) + "\n"
+		case e.ConfigErr != nil || e.LogErr != nil || e.Failures > 0:
+			content += pendingStyle.Render(line) + "\n"
+		case e.State(now) == "running":
+			content += readyStyle.Render(line) + "\n"
+		default:
+			content += normalStyle.Render(line) + "\n"
+		}
+	}
+
+	if x.cursor < len(x.list) {
+		content += "\n" + theme.Rule("─", m.layoutWidth()) + "\n"
+		content += renderExperimentDetail(x.list[x.cursor])
+	}
+
+	if x.note != "" {
+		content += "\n" + statusStyle.Render(x.note) + "\n"
+	}
+	if m.config != m.home {
+		content += "\n" + statusStyle.Render("Viewing "+m.config.ContextName+" • [Esc] returns to the main knowledge base") + "\n"
+	}
+	return content
+}
+
+// logStatus summarises the orchestration log of an experiment
+func logStatus(e *logex.Experiment, now time.Time) string {
+	switch {
+	case e.LogErr != nil:
+		return "unreadable"
+	case e.LastEvent == nil:
+		return "empty"
+	}
+	status := fmt.Sprintf("%d events, %s", e.LogEvents, e.LastEvent.Type)
+	if !e.LastActivity.IsZero() {
+		status += " " + formatDuration(max(now.Sub(e.LastActivity), 0)) + " ago"
+	}
+	return status
+}
+
+func renderExperimentDetail(e *logex.Experiment) string {
+	content := labelStyle.Render(e.Name) + "  " + statusStyle.Render(e.Dir) + "\n"
+	if c := e.Config; c != nil {
+		content += fmt.Sprintf("Topic: %s\n", c.Conversation.Topic)
+		content += fmt.Sprintf("Conversants: %s | Starter: %s\n", strings.Join(c.ConversantNames(), ", "), c.Dialogue.Starter)
+	}
+	if e.ConfigErr != nil {
+		content += pendingStyle.Render("Config: "+e.ConfigErr.Error()) + "\n"
+	}
+	if e.LogErr != nil {
+		content += pendingStyle.Render("Orchestration log: "+e.LogErr.Error()) + "\n"
+	}
+	if e.LogSkipped > 0 {
+		content += statusStyle.Render(fmt.Sprintf("%d unparseable orchestration log lines", e.LogSkipped)) + "\n"
+	}
+	if e.Failures > 0 {
+		content += pendingStyle.Render(fmt.Sprintf("%d failed sessions or tools", e.Failures)) + "\n"
+	}
+	if e.StopRequested {
+		content += pendingStyle.Render("Stop requested") + "\n"
+	}
+	return content
+}
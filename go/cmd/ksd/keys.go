@@ -0,0 +1,199 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Actions that can be rebound in the [keys] table of ksd.toml
+const (
+	actionQuit          = "quit"
+	actionDashboard     = "dashboard"
+	actionSearch        = "search"
+	actionAnalytics     = "analytics"
+	actionProcesses     = "processes"
+	actionCapture       = "capture"
+	actionReview        = "review"
+	actionExperiments   = "experiments"
+	actionTranscript    = "transcript"
+	actionPanes         = "panes"
+	actionKG            = "kg"
+	actionRefresh       = "refresh"
+	actionOpenReview    = "open_review"
+	actionCheckTriggers = "check_triggers"
+	actionFx            = "fx"
+	actionKGStats       = "kg_stats"
+	actionUp            = "up"
+	actionDown          = "down"
+)
+
+// defaultBindings are the built-in keys. "k" and "j" move like they do in
+// event-viewer, so KG stats live on "g".
+var defaultBindings = map[string][]string{
+	actionQuit:          {"q", "ctrl+c"},
+	actionDashboard:     {"1", "d"},
+	actionSearch:        {"2", "s"},
+	actionAnalytics:     {"3", "a"},
+	actionProcesses:     {"4", "p"},
+	actionCapture:       {"5", "c"},
+	actionReview:        {"6", "v"},
+	actionExperiments:   {"7", "e"},
+	actionTranscript:    {"8", "l"},
+	actionPanes:         {"9", "w"},
+	actionKG:            {"0", "m"},
+	actionRefresh:       {"f"},
+	actionOpenReview:    {"r"},
+	actionCheckTriggers: {"t"},
+	actionFx:            {"x"},
+	actionKGStats:       {"g"},
+	actionUp:            {"up", "k"},
+	actionDown:          {"down", "j"},
+}
+
+// screenKeys are the fixed keys each screen handles before the bindings,
+// so a binding on one of them is shadowed there, except for the keys that
+// canonical rewrites first
+var screenKeys = map[string][]string{
+	"Search":      {"/", "enter", "left", "right", "pgup", "pgdown", "home", "end", "T", "O", "F", "U", "X"},
+	"Analytics":   {"left", "right", "-", "+", "="},
+	"Processes":   {"K", "C", "D"},
+	"Review":      {"enter", "y", "n", " ", "esc", "f"},
+	"Experiments": {"enter", "esc", "l", "f"},
+	"Transcript":  {"enter", " ", "home", "end", "E", "S"},
+	"Panes":       {"tab", "shift+tab", "[", "]", "{", "}", "o", "="},
+	"KG":          {"enter", "esc", "backspace", "home", "E", "H", "T"},
+}
+
+// keyMap resolves keys to actions
+type keyMap struct {
+	bindings map[string][]string
+	actions  map[string]string
+}
+
+// newKeyMap applies overrides on top of the defaults and reports keys
+// bound to more than one action or shadowed by a screen
+func newKeyMap(overrides map[string][]string) (keyMap, []string) {
+	var warnings []string
+	k := keyMap{bindings: map[string][]string{}, actions: map[string]string{}}
+	for action, keys := range defaultBindings {
+		k.bindings[action] = keys
+	}
+	for action, keys := range overrides {
+		if _, ok := defaultBindings[action]; !ok {
+			warnings = append(warnings, fmt.Sprintf("unknown action %q in [keys]", action))
+			continue
+		}
+		k.bindings[action] = keys
+	}
+
+	// Resolve in a fixed order so conflicts are reported the same way
+	// every time
+	actions := make([]string, 0, len(k.bindings))
+	for action := range k.bindings {
+		actions = append(actions, action)
+	}
+	sort.Strings(actions)
+	for _, action := range actions {
+		for _, key := range k.bindings[action] {
+			if other, taken := k.actions[key]; taken && other != action {
+				warnings = append(warnings, fmt.Sprintf("key %q is bound to both %s and %s; using %s", key, other, action, other))
+				continue
+			}
+			k.actions[key] = action
+		}
+	}
+
+	screens := make([]string, 0, len(screenKeys))
+	for screen := range screenKeys {
+		screens = append(screens, screen)
+	}
+	sort.Strings(screens)
+	for _, screen := range screens {
+		for _, key := range screenKeys[screen] {
+			action, bound := k.actions[key]
+			if !bound || contains(defaultBindings[action], key) {
+				continue
+			}
+			if rewritten[action] {
+				warnings = append(warnings, fmt.Sprintf("key %q for %s wins on the %s screen; the screen's own %q is unreachable there", key, action, screen, key))
+				continue
+			}
+			warnings = append(warnings, fmt.Sprintf("key %q for %s is shadowed on the %s screen", key, action, screen))
+		}
+	}
+	return k, warnings
+}
+
+// rewritten are the actions canonical turns into screen keys, so their
+// bindings reach the screens before the screens' own keys do
+var rewritten = map[string]bool{actionUp: true, actionDown: true, actionRefresh: true}
+
+// action returns the action bound to a key, if any
+func (k keyMap) action(key string) string {
+	return k.actions[key]
+}
+
+// canonical rewrites keys bound to up, down or refresh into the keys the
+// screens handle themselves
+func (k keyMap) canonical(msg tea.KeyMsg) tea.KeyMsg {
+	switch k.action(msg.String()) {
+	case actionUp:
+		return tea.KeyMsg{Type: tea.KeyUp}
+	case actionDown:
+		return tea.KeyMsg{Type: tea.KeyDown}
+	case actionRefresh:
+		return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'f'}}
+	}
+	return msg
+}
+
+// label is the first key of an action as shown in help text
+func (k keyMap) label(action string) string {
+	keys := k.bindings[action]
+	if len(keys) == 0 {
+		return "unbound"
+	}
+	key := keys[0]
+	if len([]rune(key)) == 1 && strings.ToUpper(key) != key {
+		return strings.ToUpper(key)
+	}
+	if len([]rune(key)) == 1 && strings.ToLower(key) != key {
+		return "Shift+" + key
+	}
+	return key
+}
+
+// screenActions open the screens, in the order of their digit keys
+var screenActions = []string{
+	actionDashboard, actionSearch, actionAnalytics, actionProcesses, actionCapture,
+	actionReview, actionExperiments, actionTranscript, actionPanes, actionKG,
+}
+
+// screensLabel shows the screen keys in help text: "0-9" while the digits
+// still open the screens, else the first key of each
+func (k keyMap) screensLabel() string {
+	digits := true
+	labels := make([]string, len(screenActions))
+	for i, action := range screenActions {
+		if !contains(k.bindings[action], fmt.Sprint((i+1)%10)) {
+			digits = false
+		}
+		labels[i] = k.label(action)
+	}
+	if digits {
+		return "0-9"
+	}
+	return strings.Join(labels, "/")
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
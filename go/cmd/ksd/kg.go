@@ -0,0 +1,474 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/durapensa/ks/pkg/config"
+	"github.com/durapensa/ks/pkg/kg"
+)
+
+// kgBarWidth is the width of the human/AI contribution bar
+const kgBarWidth = 12
+
+// kgView is the state of the KG screen: the concept list, and the concepts
+// walked to from it, most recent last
+type kgView struct {
+	concepts []*kg.Concept
+	cursor   int
+	trail    []kgStep
+	hubs     kgHubsView
+	loading  bool
+	err      error
+}
+
+// kgStep is one concept on the breadcrumb trail with the edge selected on it
+type kgStep struct {
+	detail *kgDetail
+	cursor int
+}
+
+// kgDetail is a concept with its aliases and edges
+type kgDetail struct {
+	concept *kg.Concept
+	aliases []*kg.Alias
+	// links are outbound then inbound edges, grouped by type and ordered by
+	// strength within each group
+	links []kgLink
+}
+
+// kgLink is an edge seen from one of its ends
+type kgLink struct {
+	edge    *kg.Edge
+	inbound bool
+	other   string // ID of the concept at the far end
+	name    string // Its name, or the ID if it no longer exists
+	missing bool
+}
+
+// KG screen messages
+type kgStatsMsg struct {
+	note string
+}
+
+type kgConceptsMsg struct {
+	concepts []*kg.Concept
+	err      error
+}
+
+type kgDetailMsg struct {
+	detail *kgDetail
+	// replace swaps the detail in place instead of walking to it
+	replace bool
+	err     error
+}
+
+// loadKGStats summarises kg.db for the dashboard, like tools/kg/query --stats
+func loadKGStats(cfg *config.Config) tea.Cmd {
+	return func() tea.Msg {
+		path := kg.Path(cfg)
+		db, err := kg.OpenReadOnly(path)
+		if err != nil {
+			return kgStatsMsg{note: fmt.Sprintf("Knowledge graph: %v", err)}
+		}
+		defer db.Close()
+
+		stats, err := db.Stats()
+		if err != nil {
+			return kgStatsMsg{note: fmt.Sprintf("Knowledge graph: %v", err)}
+		}
+		note := fmt.Sprintf("Knowledge graph: %d concepts, %d edges, %d aliases, %d distillation runs",
+			stats.Concepts, stats.Edges, stats.Aliases, stats.DistillationRuns)
+		if stats.LastRunStatus != "" {
+			note += fmt.Sprintf(" (last %s %s)", stats.LastRunStatus, stats.LastRunAt)
+		}
+		return kgStatsMsg{note: note}
+	}
+}
+
+// Read every concept, heaviest first
+func loadKGConcepts(cfg *config.Config) tea.Cmd {
+	return func() tea.Msg {
+		db, err := kg.OpenReadOnly(kg.Path(cfg))
+		if err != nil {
+			return kgConceptsMsg{err: err}
+		}
+		defer db.Close()
+
+		concepts, err := db.Concepts(kg.ConceptFilter{})
+		return kgConceptsMsg{concepts: concepts, err: err}
+	}
+}
+
+// Read a concept with its aliases and the concepts at the other end of its
+// edges
+func loadKGDetail(cfg *config.Config, id string, replace bool) tea.Cmd {
+	return func() tea.Msg {
+		db, err := kg.OpenReadOnly(kg.Path(cfg))
+		if err != nil {
+			return kgDetailMsg{err: err}
+		}
+		defer db.Close()
+
+		concept, err := db.Concept(id)
+		if err != nil {
+			return kgDetailMsg{err: err}
+		}
+		aliases, err := db.Aliases(id)
+		if err != nil {
+			return kgDetailMsg{err: err}
+		}
+		edges, err := db.Edges(kg.EdgeFilter{Concept: id})
+		if err != nil {
+			return kgDetailMsg{err: err}
+		}
+
+		detail := &kgDetail{concept: concept, aliases: aliases}
+		names := map[string]*kg.Concept{id: concept}
+		for _, e := range edges {
+			// A self-loop is listed once, as outbound
+			link := kgLink{edge: e, inbound: e.SourceID != id, other: e.TargetID}
+			if link.inbound {
+				link.other = e.SourceID
+			}
+			other, seen := names[link.other]
+			if !seen {
+				other, err = db.Concept(link.other)
+				if err != nil && !errors.Is(err, kg.ErrNotFound) {
+					return kgDetailMsg{err: err}
+				}
+				names[link.other] = other
+			}
+			link.name, link.missing = link.other, other == nil
+			if other != nil {
+				link.name = other.Name
+			}
+			detail.links = append(detail.links, link)
+		}
+		// Edges arrive strongest first, so a stable sort keeps that order
+		// within each group
+		sort.SliceStable(detail.links, func(i, j int) bool {
+			a, b := detail.links[i], detail.links[j]
+			if a.inbound != b.inbound {
+				return !a.inbound
+			}
+			return a.edge.Type < b.edge.Type
+		})
+		return kgDetailMsg{detail: detail, replace: replace}
+	}
+}
+
+// openKG shows the concept list, keeping any trail walked before
+func (m model) openKG() (model, tea.Cmd) {
+	m.currentScreen = kgScreen
+	m.kg.loading = true
+	return m, m.reloadKG()
+}
+
+// reloadKG re-reads the concept list and the concept being viewed
+func (m model) reloadKG() tea.Cmd {
+	cmds := []tea.Cmd{loadKGConcepts(m.config)}
+	if m.kg.hubs.show {
+		cmds = append(cmds, loadKGHubs(m.config, m.kg.hubs.edgeType))
+	}
+	if step := m.kg.current(); step != nil {
+		cmds = append(cmds, loadKGDetail(m.config, step.detail.concept.ID, true))
+	}
+	return tea.Batch(cmds...)
+}
+
+// current is the step at the end of the trail, or nil on the concept list
+func (k *kgView) current() *kgStep {
+	if len(k.trail) == 0 {
+		return nil
+	}
+	return &k.trail[len(k.trail)-1]
+}
+
+// handleKGKey handles keys specific to the KG screen
+func (m model) handleKGKey(key string) (model, tea.Cmd, bool) {
+	k := &m.kg
+	step := k.current()
+	if step == nil && k.hubs.show {
+		if next, cmd, handled := m.handleHubsKey(key); handled {
+			return next, cmd, true
+		}
+	}
+
+	switch key {
+	case "up":
+		if step != nil && step.cursor > 0 {
+			step.cursor--
+		} else if step == nil && k.cursor > 0 {
+			k.cursor--
+		}
+	case "down":
+		if step != nil && step.cursor < len(step.detail.links)-1 {
+			step.cursor++
+		} else if step == nil && k.cursor < len(k.concepts)-1 {
+			k.cursor++
+		}
+	case "enter":
+		switch {
+		case step == nil && k.cursor < len(k.concepts):
+			return m, loadKGDetail(m.config, k.concepts[k.cursor].ID, false), true
+		case step != nil && step.cursor < len(step.detail.links):
+			link := step.detail.links[step.cursor]
+			if !link.missing {
+				return m, loadKGDetail(m.config, link.other, false), true
+			}
+		}
+	case "esc", "backspace":
+		if step == nil {
+			return m, nil, false
+		}
+		k.trail = k.trail[:len(k.trail)-1]
+		k.err = nil
+	case "home":
+		k.trail = nil
+		k.err = nil
+	case "H":
+		k.trail = nil
+		k.hubs.show = !k.hubs.show
+		if k.hubs.show {
+			return m, loadKGHubs(m.config, k.hubs.edgeType), true
+		}
+	case "T":
+		if !k.hubs.show {
+			return m, nil, true
+		}
+		k.trail = nil
+		k.hubs.edgeType = k.hubs.nextEdgeType()
+		k.hubs.result, k.hubs.cursor = nil, 0
+		return m, loadKGHubs(m.config, k.hubs.edgeType), true
+	case "E":
+		if step == nil {
+			if k.cursor >= len(k.concepts) {
+				return m, nil, true
+			}
+			// The list has no aliases loaded, so search the name alone
+			next, cmd := m.searchMentions(&kgDetail{concept: k.concepts[k.cursor]})
+			return next, cmd, true
+		}
+		next, cmd := m.searchMentions(step.detail)
+		return next, cmd, true
+	default:
+		return m, nil, false
+	}
+	return m, nil, true
+}
+
+// searchMentions opens Search on the events mentioning a concept by name
+// or alias
+func (m model) searchMentions(d *kgDetail) (model, tea.Cmd) {
+	terms := []string{regexp.QuoteMeta(d.concept.Name)}
+	for _, a := range d.aliases {
+		terms = append(terms, regexp.QuoteMeta(a.Alias))
+	}
+	m.currentScreen = searchScreen
+	m.inputMode = false
+	m.search.detail = false
+	// A regex alternation matches any of the names as a whole; the index
+	// would match their words separately
+	m.search.scan = true
+	m.searchTerm = strings.Join(terms, "|")
+	return m, m.runSearch()
+}
+
+// handleKGMsg applies loaded concepts and details
+func (m model) handleKGMsg(msg tea.Msg) model {
+	k := &m.kg
+	switch msg := msg.(type) {
+	case kgConceptsMsg:
+		k.loading = false
+		k.concepts, k.err = msg.concepts, msg.err
+		if k.cursor >= len(k.concepts) {
+			k.cursor = max(len(k.concepts)-1, 0)
+		}
+	case kgDetailMsg:
+		if msg.err != nil {
+			k.err = msg.err
+			return m
+		}
+		k.err = nil
+		if step := k.current(); msg.replace && step != nil {
+			step.detail = msg.detail
+			step.cursor = min(step.cursor, max(len(msg.detail.links)-1, 0))
+			return m
+		}
+		k.trail = append(k.trail, kgStep{detail: msg.detail})
+	case kgHubsMsg:
+		h := &k.hubs
+		h.err = msg.err
+		if msg.err == nil {
+			h.result, h.types = msg.hubs, msg.types
+			h.cursor = min(h.cursor, max(len(msg.hubs.ranks)-1, 0))
+		}
+	}
+	return m
+}
+
+func (m model) renderKG() string {
+	k := m.kg
+	content := headerStyle.Render("KNOWLEDGE GRAPH") + "\n"
+	content += statusStyle.Render(kg.Path(m.config)) + "\n"
+	content += m.renderKGTrail() + "\n\n"
+
+	if k.err != nil {
+		if errors.Is(k.err, os.ErrNotExist) {
+			content += statusStyle.Render("No knowledge graph yet; tools/kg/run-distillation builds one") + "\n"
+		} else {
+			content += pendingStyle.Render("Error: "+k.err.Error()) + "\n"
+		}
+		if len(k.trail) == 0 {
+			return content
+		}
+		content += "\n"
+	}
+	if step := k.current(); step != nil {
+		return content + m.renderKGDetail(*step)
+	}
+	if k.hubs.show {
+		return content + m.renderHubs()
+	}
+	if k.loading && k.concepts == nil {
+		return content + statusStyle.Render("Loading...") + "\n"
+	}
+	if len(k.concepts) == 0 {
+		return content + statusStyle.Render("No concepts") + "\n"
+	}
+
+	nameWidth := max(m.layoutWidth()-kgBarWidth-30, 16)
+	content += labelStyle.Render(fmt.Sprintf("  %-*s %7s  %-*s %s", nameWidth, "CONCEPT", "WEIGHT", kgBarWidth, "HUMAN/AI", "SPLIT")) + "\n"
+	start, end := listWindow(k.cursor, len(k.concepts), m.kgPageSize())
+	for i := start; i < end; i++ {
+		c := k.concepts[i]
+		line := fmt.Sprintf("  %-*s %7.2f  ", nameWidth, truncate(c.Name, nameWidth), c.Weight)
+		if i == k.cursor {
+			line = selectedStyle.Render(line)
+		} else {
+			line = normalStyle.Render(line)
+		}
+		content += line + contributionBar(c) + " " + statusStyle.Render(contributionSplit(c)) + "\n"
+	}
+	if start > 0 || end < len(k.concepts) {
+		content += statusStyle.Render(fmt.Sprintf("  %d-%d of %d concepts", start+1, end, len(k.concepts))) + "\n"
+	}
+	return content
+}
+
+// renderKGTrail is the breadcrumb from the concept list to the concept
+// being viewed
+func (m model) renderKGTrail() string {
+	crumbs := []string{"Concepts"}
+	if m.kg.hubs.show {
+		crumbs[0] = "Hubs"
+	}
+	for _, step := range m.kg.trail {
+		crumbs = append(crumbs, step.detail.concept.Name)
+	}
+	last := len(crumbs) - 1
+	crumbs[last] = labelStyle.Render(crumbs[last])
+	return strings.Join(crumbs, statusStyle.Render(" › "))
+}
+
+func (m model) renderKGDetail(step kgStep) string {
+	d := step.detail
+	c := d.concept
+	content := labelStyle.Render(c.Name) + "  " + statusStyle.Render(c.ID) + "\n"
+	content += fmt.Sprintf("Weight: %.2f   Contribution: %s %s\n", c.Weight, contributionBar(c), contributionSplit(c))
+	content += statusStyle.Render(fmt.Sprintf("First seen %s, updated %s", c.Created.Format("2006-01-02 15:04"), c.Updated.Format("2006-01-02 15:04"))) + "\n\n"
+
+	content += labelStyle.Render("ALIASES") + "\n"
+	if len(d.aliases) == 0 {
+		content += statusStyle.Render("  None") + "\n"
+	}
+	for _, a := range d.aliases {
+		source := ""
+		if a.Source != "" {
+			source = " " + statusStyle.Render("("+a.Source+")")
+		}
+		content += fmt.Sprintf("  %s ×%d%s\n", a.Alias, a.Count, source)
+	}
+	content += "\n"
+
+	if len(d.links) == 0 {
+		return content + labelStyle.Render("EDGES") + "\n" + statusStyle.Render("  None") + "\n"
+	}
+
+	// Only the edges around the cursor fit, so group headers are repeated
+	// for the first visible edge of each group
+	nameWidth := max(m.layoutWidth()-30, 16)
+	start, end := listWindow(step.cursor, len(d.links), m.kgPageSize()-len(d.aliases)-4)
+	for i := start; i < end; i++ {
+		link := d.links[i]
+		if i == start || link.inbound != d.links[i-1].inbound || link.edge.Type != d.links[i-1].edge.Type {
+			direction := "OUTBOUND"
+			if link.inbound {
+				direction = "INBOUND"
+			}
+			content += labelStyle.Render(fmt.Sprintf("%s %s", direction, link.edge.Type)) + "\n"
+		}
+		arrow := "→"
+		if link.inbound {
+			arrow = "←"
+		}
+		name := link.name
+		if link.missing {
+			name += " (missing)"
+		}
+		name = truncate(name, nameWidth)
+		line := fmt.Sprintf("  %s %-*s %5.2f ", arrow, nameWidth, name, link.edge.Strength)
+		if i == step.cursor {
+			line = selectedStyle.Render(line)
+		} else {
+			line = normalStyle.Render(line)
+		}
+		content += line + readyStyle.Render(strings.Repeat("█", int(math.Round(link.edge.Strength*10)))) + "\n"
+	}
+	if start > 0 || end < len(d.links) {
+		content += statusStyle.Render(fmt.Sprintf("  %d-%d of %d edges", start+1, end, len(d.links))) + "\n"
+	}
+	return content
+}
+
+// kgPageSize fits the list to the terminal height
+func (m model) kgPageSize() int {
+	if m.height == 0 {
+		return 15
+	}
+	return max(m.height-16, 5)
+}
+
+// listWindow is the range of a list of n items shown around the cursor
+func listWindow(cursor, n, size int) (int, int) {
+	size = max(size, 3)
+	if n <= size {
+		return 0, n
+	}
+	start := min(max(cursor-size/2, 0), n-size)
+	return start, start + size
+}
+
+// contributionBar splits a bar between human and AI contribution in
+// proportion; a concept with neither is shown empty
+func contributionBar(c *kg.Concept) string {
+	total := c.HumanWeight + c.AIWeight
+	if total <= 0 {
+		return statusStyle.Render(strings.Repeat("·", kgBarWidth))
+	}
+	// Different glyphs keep the halves apart without colour
+	human := int(math.Round(c.HumanWeight / total * kgBarWidth))
+	return theme.SeriesStyle(0).Render(strings.Repeat("█", human)) +
+		theme.SeriesStyle(1).Render(strings.Repeat("▒", kgBarWidth-human))
+}
+
+func contributionSplit(c *kg.Concept) string {
+	return fmt.Sprintf("H %.0f%% / AI %.0f%%", c.HumanWeight*100, c.AIWeight*100)
+}
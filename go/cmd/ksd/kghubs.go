@@ -0,0 +1,173 @@
+package main
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/durapensa/ks/pkg/config"
+	"github.com/durapensa/ks/pkg/kg"
+)
+
+// kgHubsView is the graph analysis shown instead of the concept list
+type kgHubsView struct {
+	show     bool
+	edgeType string // Empty analyses every edge type
+	types    []string
+	result   *kgHubs
+	cursor   int
+	err      error
+}
+
+// kgHubs ranks concepts by PageRank alongside their other measures
+type kgHubs struct {
+	ranks       []kg.Score
+	betweenness map[string]float64
+	degree      map[string]int
+	community   map[string]int // Community number, largest first
+	edges       int
+	components  int
+	communities int
+	modularity  float64
+}
+
+type kgHubsMsg struct {
+	hubs  *kgHubs
+	types []string
+	err   error
+}
+
+// Analyse the graph, keeping only edges of edgeType if set
+func loadKGHubs(cfg *config.Config, edgeType string) tea.Cmd {
+	return func() tea.Msg {
+		db, err := kg.OpenReadOnly(kg.Path(cfg))
+		if err != nil {
+			return kgHubsMsg{err: err}
+		}
+		defer db.Close()
+
+		types, err := db.EdgeTypes()
+		if err != nil {
+			return kgHubsMsg{err: err}
+		}
+		filter := kg.GraphFilter{}
+		if edgeType != "" {
+			filter.Types = []string{edgeType}
+		}
+		g, err := db.Graph(filter)
+		if err != nil {
+			return kgHubsMsg{err: err}
+		}
+
+		hubs := &kgHubs{
+			ranks:       g.PageRank(),
+			betweenness: map[string]float64{},
+			degree:      map[string]int{},
+			community:   map[string]int{},
+			edges:       len(g.Edges),
+			components:  len(g.Components()),
+		}
+		for _, s := range g.Betweenness() {
+			hubs.betweenness[s.Concept.ID] = s.Value
+		}
+		for _, c := range g.Concepts {
+			hubs.degree[c.ID] = g.Degree(c.ID)
+		}
+		groups, q := g.Communities()
+		hubs.communities, hubs.modularity = len(groups), q
+		for i, group := range groups {
+			for _, c := range group {
+				hubs.community[c.ID] = i + 1
+			}
+		}
+		return kgHubsMsg{hubs: hubs, types: types}
+	}
+}
+
+// handleHubsKey handles the keys of the hubs list
+func (m model) handleHubsKey(key string) (model, tea.Cmd, bool) {
+	h := &m.kg.hubs
+	var ranks []kg.Score
+	if h.result != nil {
+		ranks = h.result.ranks
+	}
+
+	switch key {
+	case "up":
+		if h.cursor > 0 {
+			h.cursor--
+		}
+	case "down":
+		if h.cursor < len(ranks)-1 {
+			h.cursor++
+		}
+	case "enter":
+		if h.cursor < len(ranks) {
+			return m, loadKGDetail(m.config, ranks[h.cursor].Concept.ID, false), true
+		}
+	case "E":
+		if h.cursor < len(ranks) {
+			next, cmd := m.searchMentions(&kgDetail{concept: ranks[h.cursor].Concept})
+			return next, cmd, true
+		}
+	default:
+		return m, nil, false
+	}
+	return m, nil, true
+}
+
+// nextEdgeType cycles through every edge type and back to all of them
+func (h kgHubsView) nextEdgeType() string {
+	if h.edgeType == "" {
+		if len(h.types) == 0 {
+			return ""
+		}
+		return h.types[0]
+	}
+	for i, t := range h.types {
+		if t == h.edgeType && i+1 < len(h.types) {
+			return h.types[i+1]
+		}
+	}
+	return ""
+}
+
+func (m model) renderHubs() string {
+	h := m.kg.hubs
+	edgeType := "all"
+	if h.edgeType != "" {
+		edgeType = h.edgeType
+	}
+	content := labelStyle.Render("HUBS") + "  " + statusStyle.Render("Edge types: "+edgeType) + "\n"
+
+	if h.err != nil {
+		return content + pendingStyle.Render("Error: "+h.err.Error()) + "\n"
+	}
+	r := h.result
+	if r == nil {
+		return content + statusStyle.Render("Analysing...") + "\n"
+	}
+	content += fmt.Sprintf("%d concepts, %d edges • %d components • %d communities (modularity %.3f)\n\n",
+		len(r.ranks), r.edges, r.components, r.communities, r.modularity)
+	if len(r.ranks) == 0 {
+		return content + statusStyle.Render("No concepts") + "\n"
+	}
+
+	nameWidth := max(m.layoutWidth()-44, 16)
+	content += labelStyle.Render(fmt.Sprintf("  %4s %-*s %9s %11s %6s %9s", "RANK", nameWidth, "CONCEPT", "PAGERANK", "BETWEENNESS", "DEGREE", "COMMUNITY")) + "\n"
+	start, end := listWindow(h.cursor, len(r.ranks), m.kgPageSize()-2)
+	for i := start; i < end; i++ {
+		s := r.ranks[i]
+		id := s.Concept.ID
+		line := fmt.Sprintf("  %4d %-*s %9.4f %11.4f %6d %9d",
+			i+1, nameWidth, truncate(s.Concept.Name, nameWidth), s.Value, r.betweenness[id], r.degree[id], r.community[id])
+		if i == h.cursor {
+			content += selectedStyle.Render(line) + "\n"
+		} else {
+			content += normalStyle.Render(line) + "\n"
+		}
+	}
+	if start > 0 || end < len(r.ranks) {
+		content += statusStyle.Render(fmt.Sprintf("  %d-%d of %d concepts", start+1, end, len(r.ranks))) + "\n"
+	}
+	return content
+}
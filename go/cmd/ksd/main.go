@@ -1,19 +1,26 @@
 package main
 
 import (
-	"encoding/json"
 	"fmt"
 	"log"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
 
-	"github.com/fsnotify/fsnotify"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/durapensa/ks/pkg/config"
+	"github.com/durapensa/ks/pkg/events"
+	"github.com/durapensa/ks/pkg/index"
+	"github.com/durapensa/ks/pkg/process"
+	"github.com/durapensa/ks/pkg/query"
+	"github.com/durapensa/ks/pkg/queue"
+	"github.com/durapensa/ks/pkg/review"
+	"github.com/durapensa/ks/pkg/triggers"
+	"github.com/durapensa/ks/pkg/ui"
 )
 
 var (
@@ -31,58 +38,48 @@ const (
 	analyticsScreen
 	processScreen
 	captureScreen
+	reviewScreen
+	experimentsScreen
+	transcriptScreen
+	panesScreen
+	kgScreen
 )
 
-// Styles
+// Styles of the active theme, set by useTheme
 var (
-	titleStyle = lipgloss.NewStyle().
-		Bold(true).
-		Foreground(lipgloss.Color("205")).
-		Align(lipgloss.Center).
-		MarginBottom(1)
-
-	headerStyle = lipgloss.NewStyle().
-		Bold(true).
-		Foreground(lipgloss.Color("212")).
-		MarginBottom(1)
-
-	statusStyle = lipgloss.NewStyle().
-		Foreground(lipgloss.Color("248"))
-
-	pendingStyle = lipgloss.NewStyle().
-		Foreground(lipgloss.Color("214"))
-
-	readyStyle = lipgloss.NewStyle().
-		Foreground(lipgloss.Color("46"))
-
-	helpStyle = lipgloss.NewStyle().
-		Foreground(lipgloss.Color("241")).
-		MarginTop(1)
-
-	separatorStyle = lipgloss.NewStyle().
-		Foreground(lipgloss.Color("237"))
-
-	selectedStyle = lipgloss.NewStyle().
-		Background(lipgloss.Color("237")).
-		Foreground(lipgloss.Color("255"))
-
-	normalStyle = lipgloss.NewStyle().
-		Foreground(lipgloss.Color("252"))
+	theme ui.Theme
+
+	titleStyle     lipgloss.Style
+	headerStyle    lipgloss.Style
+	labelStyle     lipgloss.Style
+	statusStyle    lipgloss.Style
+	pendingStyle   lipgloss.Style
+	readyStyle     lipgloss.Style
+	helpStyle      lipgloss.Style
+	separatorStyle lipgloss.Style
+	selectedStyle  lipgloss.Style
+	normalStyle    lipgloss.Style
+	highlightStyle lipgloss.Style
 )
 
-// Event structure for parsing JSONL
-type Event struct {
-	Timestamp   string                 `json:"ts"`                  // Actual field name is "ts"
-	Type        string                 `json:"type"`
-	Content     string                 `json:"content,omitempty"`   // Most events use "content"
-	Topic       string                 `json:"topic,omitempty"`     // Some events have topic
-	Thought     string                 `json:"thought,omitempty"`   // Legacy support
-	Observation string                 `json:"observation,omitempty"` // Legacy support
-	Question    string                 `json:"question,omitempty"`  // Legacy support
-	Context     string                 `json:"context,omitempty"`
-	Tags        []string               `json:"tags,omitempty"`
-	Metadata    map[string]interface{} `json:"metadata,omitempty"`
-	RawJSON     string                 // Store the original JSON line for pretty printing
+func init() {
+	useTheme(ui.New(ui.Dark))
+}
+
+// useTheme points the global styles at t
+func useTheme(t ui.Theme) {
+	theme = t
+	titleStyle = t.Title.Copy().Align(lipgloss.Center)
+	headerStyle = t.Header
+	labelStyle = t.Label
+	statusStyle = t.Status
+	pendingStyle = t.Pending
+	readyStyle = t.Ready
+	helpStyle = t.Help
+	separatorStyle = t.Separator
+	selectedStyle = t.Selected
+	normalStyle = t.Normal
+	highlightStyle = t.Highlight
 }
 
 // Dashboard data
@@ -90,26 +87,44 @@ type dashboardData struct {
 	totalEvents       int
 	pendingCount      int
 	activeProcesses   int
-	eventsUntilTheme  int
-	eventsUntilConn   int
-	eventsUntilPatt   int
+	triggerState      triggers.State
+	triggers          []triggers.Readiness
 	lastUpdate        string
-	latestEvent       *Event
+	latestEvent       *events.Event
 }
 
 // Model represents the TUI state
 type model struct {
 	config        *config.Config
+	home          *config.Config
+	follower      *events.Follower
+	searchIndex   *index.Index
+	indexPending  bool  // An index update is scheduled
+	indexErr      error // From opening the index or its last update
 	currentScreen screenType
 	dashboard     dashboardData
 	width         int
 	height        int
 	error         error
-	searchResults []string
+	searchResults []*events.Event
+	searchTotal   int
+	processes     []process.Process
+	processCursor int
+	processNote   string
+	triggerNote   string
+	capture       captureForm
+	search        searchView
+	review        reviewView
+	analytics     analyticsView
+	experiments   experimentsView
+	transcript    transcriptView
+	panes         panesView
+	kg            kgView
 	searchTerm    string
 	searchInput   string
 	loading       bool
 	inputMode     bool
+	prefs         prefs
 }
 
 // Messages
@@ -122,77 +137,194 @@ type errorMsg struct {
 }
 
 type searchResultsMsg struct {
-	results []string
+	results []*events.Event
+	total   int
 	term    string
 }
 
-type fileWatchMsg struct {
-	event fsnotify.Event
+type newEventMsg struct {
+	event    *events.Event
+	follower *events.Follower
 }
 
 type fileWatchErrorMsg struct {
+	err      error
+	follower *events.Follower
+}
+
+type openScreenMsg struct {
+	screen screenType
+}
+
+type refreshTickMsg struct{}
+
+// indexDueMsg runs the index update scheduled after new events
+type indexDueMsg struct{}
+
+type indexUpdatedMsg struct {
 	err error
 }
 
+// indexDelay batches index updates for events arriving in bursts
+const indexDelay = 2 * time.Second
+
 // Initialize the model
-func initialModel() model {
+func initialModel(reviewOptions review.Options) model {
+	// Preferences first, so the quit binding works on the error screen
+	p := loadPrefs(prefsPath())
+	useTheme(ui.New(p.theme))
+
 	cfg, err := config.LoadKSEnv()
 	if err != nil {
-		return model{error: err}
+		return model{error: err, review: reviewView{options: reviewOptions}, prefs: p}
+	}
+
+	// Follow the hot log from its current end; the dashboard load covers
+	// everything already written
+	follower, err := events.FollowFromEnd(cfg.HotLog)
+	if err != nil {
+		log.Printf("File watch error: %v", err)
+	}
+
+	// Persistent search index under KS_BACKGROUND_DIR; a corrupt one is
+	// rebuilt from scratch
+	searchIndex, indexErr := index.Open(searchIndexPath(cfg))
+
+	layout, err := loadPaneLayout(layoutPath())
+	if err != nil {
+		p.warnings = append(p.warnings, err.Error())
 	}
 
 	return model{
 		config:        cfg,
+		home:          cfg,
+		follower:      follower,
+		searchIndex:   searchIndex,
+		indexErr:      indexErr,
 		currentScreen: dashboardScreen,
 		dashboard:     dashboardData{},
+		search:        searchView{filters: p.searchFilters()},
+		review:        reviewView{options: reviewOptions},
+		analytics:     analyticsView{window: defaultAnalyticsWindow},
+		panes:         panesView{layout: layout, path: layoutPath()},
+		prefs:         p,
 	}
 }
 
 func (m model) Init() tea.Cmd {
-	return tea.Batch(
+	cmds := []tea.Cmd{
 		loadDashboardDataWithConfig(m.config),
-		watchFile(m.config.HotLog), // Start file watching
-	)
+		waitForEvent(m.follower), // Start file watching
+		refreshTick(m.prefs.refreshInterval),
+	}
+	if m.prefs.defaultScreen != dashboardScreen {
+		screen := m.prefs.defaultScreen
+		cmds = append(cmds, func() tea.Msg { return openScreenMsg{screen: screen} })
+	}
+	return tea.Batch(cmds...)
 }
 
-// Command to watch a file for changes using fsnotify
-// This follows Bubbletea's pattern: a command waits for ONE event and returns it as a message
-func watchFile(path string) tea.Cmd {
-	return func() tea.Msg {
-		if path == "" {
-			return fileWatchErrorMsg{err: fmt.Errorf("no file path provided")}
-		}
+// refreshTick schedules the next periodic refresh; 0 turns it off
+func refreshTick(interval time.Duration) tea.Cmd {
+	if interval <= 0 {
+		return nil
+	}
+	return tea.Tick(interval, func(time.Time) tea.Msg { return refreshTickMsg{} })
+}
 
-		watcher, err := fsnotify.NewWatcher()
-		if err != nil {
-			return fileWatchErrorMsg{err: err}
-		}
-		defer watcher.Close()
+// refresh reloads the dashboard and the data of the current screen
+func (m model) refresh() tea.Cmd {
+	switch m.currentScreen {
+	case processScreen:
+		return tea.Batch(loadDashboardDataWithConfig(m.config), loadProcesses(m.config))
+	case panesScreen:
+		return tea.Batch(loadDashboardDataWithConfig(m.config), loadProcesses(m.config), loadPaneQueue(m.config))
+	case kgScreen:
+		return tea.Batch(loadDashboardDataWithConfig(m.config), m.reloadKG())
+	}
+	return loadDashboardDataWithConfig(m.config)
+}
 
-		err = watcher.Add(path)
-		if err != nil {
-			return fileWatchErrorMsg{err: err}
-		}
+// openScreen switches screens and loads what the new screen shows
+func (m model) openScreen(screen screenType) (model, tea.Cmd) {
+	switch screen {
+	case analyticsScreen:
+		m.analytics.loading = true
+		m.currentScreen = screen
+		return m, loadAnalytics(m.config, m.analytics.days())
+	case processScreen:
+		m.currentScreen = screen
+		return m, loadProcesses(m.config)
+	case captureScreen:
+		m.currentScreen = screen
+		m.capture.editing = true
+		return m, loadTopics(m.config)
+	case reviewScreen:
+		m.currentScreen = screen
+		return m, loadReview(m.config)
+	case experimentsScreen:
+		return m.openExperiments()
+	case transcriptScreen:
+		return m.openTranscript(m.currentConversation())
+	case panesScreen:
+		m.currentScreen = screen
+		return m, m.loadPanes()
+	case kgScreen:
+		return m.openKG()
+	}
+	m.currentScreen = screen
+	return m, nil
+}
 
-		// Wait for ONE event and return it as a message
-		// Bubbletea's runtime automatically handles this in a goroutine
+// typing reports whether a text input has focus, so keys are characters
+// rather than bindings
+func (m model) typing() bool {
+	switch m.currentScreen {
+	case captureScreen:
+		return m.capture.editing
+	case searchScreen:
+		return m.inputMode || m.search.filterInput != noFilterInput
+	case reviewScreen:
+		return m.review.stage == reviewNotes
+	}
+	return false
+}
+
+// handleTypingKey passes a key unchanged to the screen being typed in
+func (m model) handleTypingKey(msg tea.KeyMsg) (model, tea.Cmd) {
+	var cmd tea.Cmd
+	switch m.currentScreen {
+	case captureScreen:
+		m, cmd = m.handleCaptureKey(msg)
+	case searchScreen:
+		m, cmd, _ = m.handleSearchKey(msg)
+	case reviewScreen:
+		m, cmd, _ = m.handleReviewKey(msg)
+	}
+	return m, cmd
+}
+
+// Command to wait for the next appended event from the follower
+// This follows Bubbletea's pattern: a command waits for ONE event and returns it as a message
+func waitForEvent(f *events.Follower) tea.Cmd {
+	if f == nil {
+		return nil
+	}
+	return func() tea.Msg {
 		select {
-		case event, ok := <-watcher.Events:
-			if !ok {
-				return fileWatchErrorMsg{err: fmt.Errorf("watcher events channel closed")}
-			}
-			return fileWatchMsg{event: event}
-		case err, ok := <-watcher.Errors:
+		case event, ok := <-f.Events:
 			if !ok {
-				return fileWatchErrorMsg{err: fmt.Errorf("watcher errors channel closed")}
+				return nil // Follower closed, stop waiting
 			}
-			return fileWatchErrorMsg{err: err}
+			return newEventMsg{event: event, follower: f}
+		case err := <-f.Errors:
+			return fileWatchErrorMsg{err: err, follower: f}
 		}
 	}
 }
 
 // Parse the latest event from the hot log
-func parseLatestEvent(cfg *config.Config) *Event {
+func parseLatestEvent(cfg *config.Config) *events.Event {
 	if cfg.HotLog == "" {
 		return nil
 	}
@@ -234,31 +366,13 @@ func parseLatestEvent(cfg *config.Config) *Event {
 		return nil
 	}
 
-	// Parse the JSON
-	var event Event
-	if err := json.Unmarshal([]byte(lastLine), &event); err != nil {
+	// Parse the JSON (keeps the raw line for pretty printing)
+	event, err := events.Parse([]byte(lastLine))
+	if err != nil {
 		return nil
 	}
 
-	// Store the raw JSON for pretty printing
-	event.RawJSON = lastLine
-
-	return &event
-}
-
-// Helper functions for min/max
-func min(a, b int) int {
-	if a < b {
-		return a
-	}
-	return b
-}
-
-func max(a, b int64) int64 {
-	if a > b {
-		return a
-	}
-	return b
+	return event
 }
 
 // getBashPath returns the configured bash path (required for consistent bash 5.x behavior)
@@ -277,22 +391,16 @@ func getCurrentDir() string {
 // Load dashboard data from system with config
 func loadDashboardDataWithConfig(cfg *config.Config) tea.Cmd {
 	return func() tea.Msg {
-		// Get event count - use bash command for both modes (consistent with status mode)
-		var totalEvents int
-		cmd := exec.Command("bash", "-c", "source ~/.ks-env 2>/dev/null || source .ks-env; source $KS_ROOT/lib/core.sh; source $KS_ROOT/lib/events.sh; ks_count_new_events")
-		if cfg.IsConversation {
-			// Set working directory for conversation context
-			cmd.Dir = cfg.ConversationDir
-		}
-		if output, err := cmd.Output(); err == nil {
-			totalEvents, _ = strconv.Atoi(strings.TrimSpace(string(output)))
+		// Event count and trigger readiness from .event_trigger_state
+		status, err := triggers.New(cfg).Status()
+		if err != nil {
+			return errorMsg{err}
 		}
 
 		// Get pending analyses count
 		var pendingCount int
-		pendingCmd := exec.Command("bash", "-c", "source ~/.ks-env 2>/dev/null || source .ks-env; source $KS_ROOT/lib/core.sh; source $KS_ROOT/tools/lib/queue.sh; ks_queue_list_pending | jq 'length'")
-		if output, err := pendingCmd.Output(); err == nil {
-			pendingCount, _ = strconv.Atoi(strings.TrimSpace(string(output)))
+		if pending, err := queue.New(cfg.AnalysisQueue).ListPending(); err == nil {
+			pendingCount = len(pending)
 		}
 
 		// Get latest event
@@ -300,12 +408,11 @@ func loadDashboardDataWithConfig(cfg *config.Config) tea.Cmd {
 
 		return dashboardMsg{
 			data: dashboardData{
-				totalEvents:     totalEvents,
+				totalEvents:     status.Count,
 				pendingCount:    pendingCount,
-				activeProcesses: 0, // TODO: implement
-				eventsUntilTheme: 10 - (totalEvents % 10),
-				eventsUntilConn:  20 - (totalEvents % 20),
-				eventsUntilPatt:  30 - (totalEvents % 30),
+				activeProcesses: countActiveProcesses(cfg),
+				triggerState:    status.State,
+				triggers:        status.Triggers,
 				lastUpdate:      time.Now().Format("15:04:05"),
 				latestEvent:     latestEvent,
 			},
@@ -343,104 +450,146 @@ func runExternalToolWithConfig(cfg *config.Config, command string) tea.Cmd {
 	})
 }
 
-// Search knowledge base with context awareness
-func searchKnowledgeWithConfig(cfg *config.Config, term string) tea.Cmd {
+// searchIndexPath keeps the index under KS_BACKGROUND_DIR, except in a
+// conversation: its hot log differs from the global one, and a shared
+// index would be rebuilt on every switch
+func searchIndexPath(cfg *config.Config) string {
+	if cfg.IsConversation {
+		return filepath.Join(cfg.KnowledgeDir, ".background", index.FileName)
+	}
+	return filepath.Join(cfg.BackgroundDir, index.FileName)
+}
+
+// newQueryEngine builds a query engine for the current context
+func newQueryEngine(cfg *config.Config, idx *index.Index) *query.Engine {
+	// In a conversation both paths are the conversation's own events
+	engine := query.New(cfg.HotLog, cfg.ArchiveDir)
+	engine.Index = idx
+	return engine
+}
+
+// Incrementally index newly appended events and save the index
+func updateSearchIndex(cfg *config.Config, idx *index.Index) tea.Cmd {
 	return func() tea.Msg {
-		cmd := exec.Command("bash", "-c", fmt.Sprintf("source ~/.ks-env 2>/dev/null || source .ks-env; $KS_ROOT/tools/capture/query '%s'", term))
-		
-		// If we're in a conversation directory, set working directory
-		if cfg.IsConversation {
-			cmd.Dir = cfg.ConversationDir
+		engine := newQueryEngine(cfg, idx)
+		if err := idx.Update(engine.Files(time.Time{})); err != nil {
+			return indexUpdatedMsg{err: err}
 		}
-		
-		output, err := cmd.Output()
-		if err != nil {
-			return errorMsg{fmt.Errorf("searching: %w", err)}
-		}
-		
-		lines := strings.Split(strings.TrimSpace(string(output)), "\n")
-		return searchResultsMsg{results: lines, term: term}
+		return indexUpdatedMsg{err: idx.Save()}
 	}
 }
 
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "ctrl+c", "q":
+		// Text input takes every key, whatever it is bound to
+		if m.typing() {
+			return m.handleTypingKey(msg)
+		}
+
+		action := m.prefs.keys.action(msg.String())
+		key := m.prefs.keys.canonical(msg)
+		if m.currentScreen == processScreen {
+			if next, cmd, handled := m.handleProcessKey(key.String()); handled {
+				return next, cmd
+			}
+		}
+		if m.currentScreen == reviewScreen {
+			if next, cmd, handled := m.handleReviewKey(key); handled {
+				return next, cmd
+			}
+		}
+		if m.currentScreen == searchScreen {
+			if next, cmd, handled := m.handleSearchKey(key); handled {
+				return next, cmd
+			}
+		}
+		if m.currentScreen == analyticsScreen {
+			if next, cmd, handled := m.handleAnalyticsKey(key.String()); handled {
+				return next, cmd
+			}
+		}
+		if m.currentScreen == experimentsScreen {
+			if next, cmd, handled := m.handleExperimentsKey(key.String()); handled {
+				return next, cmd
+			}
+		}
+		if m.currentScreen == transcriptScreen {
+			if next, cmd, handled := m.handleTranscriptKey(key.String()); handled {
+				return next, cmd
+			}
+		}
+		if m.currentScreen == panesScreen {
+			if next, cmd, handled := m.handlePanesKey(key.String()); handled {
+				return next, cmd
+			}
+		}
+		if m.currentScreen == kgScreen {
+			if next, cmd, handled := m.handleKGKey(key.String()); handled {
+				return next, cmd
+			}
+		}
+		if m.currentScreen == captureScreen && msg.Type == tea.KeyEnter {
+			m.capture.editing = true
+			return m, loadTopics(m.config)
+		}
+
+		switch action {
+		case actionQuit:
 			return m, tea.Quit
 
 		// Screen navigation
-		case "1", "d":
-			m.currentScreen = dashboardScreen
-			return m, nil
-		case "2", "s":
-			m.currentScreen = searchScreen
-			return m, nil
-		case "3", "a":
-			m.currentScreen = analyticsScreen
-			return m, nil
-		case "4", "p":
-			m.currentScreen = processScreen
-			return m, nil
-		case "5", "c":
-			m.currentScreen = captureScreen
-			return m, nil
+		case actionDashboard:
+			return m.openScreen(dashboardScreen)
+		case actionSearch:
+			return m.openScreen(searchScreen)
+		case actionAnalytics:
+			return m.openScreen(analyticsScreen)
+		case actionProcesses:
+			return m.openScreen(processScreen)
+		case actionCapture:
+			return m.openScreen(captureScreen)
+		case actionReview:
+			return m.openScreen(reviewScreen)
+		case actionExperiments:
+			return m.openScreen(experimentsScreen)
+		case actionTranscript:
+			return m.openScreen(transcriptScreen)
+		case actionPanes:
+			return m.openScreen(panesScreen)
+		case actionKG:
+			return m.openScreen(kgScreen)
 
 		// Dashboard actions
-		case "r":
-			if m.currentScreen == dashboardScreen && m.dashboard.pendingCount > 0 {
-				return m, runExternalToolWithConfig(m.config, "$KS_ROOT/tools/introspect/review-findings")
+		case actionOpenReview:
+			if m.currentScreen == dashboardScreen {
+				return m.openScreen(reviewScreen)
 			}
-		case "t":
+		case actionCheckTriggers:
 			if m.currentScreen == dashboardScreen {
-				return m, runExternalToolWithConfig(m.config, "$KS_ROOT/tools/plumbing/check-event-triggers --verbose")
+				return m, checkTriggers(m.config)
 			}
-		case "x":
+		case actionFx:
 			// Launch fx viewer for knowledge exploration
 			hotLogPath := m.config.HotLog
 			if hotLogPath == "" {
 				hotLogPath = "$KS_HOT_LOG"
 			}
 			return m, runExternalToolWithConfig(m.config, fmt.Sprintf("fx %s", hotLogPath))
-		case "k":
-			// Quick kg query
+		case actionKGStats:
 			if m.currentScreen == dashboardScreen {
-				return m, runExternalToolWithConfig(m.config, "$KS_ROOT/tools/kg/query --stats")
-			}
-		case "f":
-			return m, loadDashboardDataWithConfig(m.config)
-			
-		// Search actions
-		case "enter":
-			if m.currentScreen == searchScreen {
-				if m.inputMode {
-					m.inputMode = false
-					m.searchTerm = m.searchInput
-					return m, searchKnowledgeWithConfig(m.config, m.searchInput)
-				} else {
-					m.inputMode = true
-					m.searchInput = ""
-				}
-			}
-		case "esc":
-			if m.inputMode {
-				m.inputMode = false
-				m.searchInput = ""
-			}
-		case "backspace":
-			if m.inputMode && len(m.searchInput) > 0 {
-				m.searchInput = m.searchInput[:len(m.searchInput)-1]
-			}
-		default:
-			// Handle text input for search
-			if m.inputMode && m.currentScreen == searchScreen {
-				if len(msg.String()) == 1 {
-					m.searchInput += msg.String()
-				}
+				return m, loadKGStats(m.config)
 			}
+		case actionRefresh:
+			return m, m.refresh()
 		}
 
+	case openScreenMsg:
+		return m.openScreen(msg.screen)
+
+	case refreshTickMsg:
+		return m, tea.Batch(m.refresh(), refreshTick(m.prefs.refreshInterval))
+
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
@@ -451,35 +600,196 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case searchResultsMsg:
 		m.searchResults = msg.results
+		m.searchTotal = msg.total
 		m.searchTerm = msg.term
+		m.search.cursor = 0
+		m.search.detail = false
+
+	case processesMsg:
+		m.processes = msg.processes
+		if m.processCursor >= len(m.processes) && len(m.processes) > 0 {
+			m.processCursor = len(m.processes) - 1
+		}
+
+	case processActionMsg:
+		m.processNote = msg.note
+		return m, tea.Batch(loadProcesses(m.config), loadDashboardDataWithConfig(m.config))
+
+	case topicsMsg:
+		m.capture.topics = msg.topics
+
+	case captureSavedMsg:
+		f := &m.capture
+		f.note = msg.note
+		f.err = nil
+		f.content, f.tags, f.metadata = "", "", ""
+		f.focus = captureContent
+		m.triggerNote = msg.triggerNote
+		return m, tea.Batch(loadTopics(m.config), loadDashboardDataWithConfig(m.config))
+
+	case captureErrorMsg:
+		m.capture.err = msg.err
+
+	case reviewLoadedMsg:
+		r := &m.review
+		r.batches = msg.batches
+		r.loadErrs = msg.errs
+		if r.cursor >= len(r.batches) {
+			r.cursor = max(len(r.batches)-1, 0)
+		}
+
+	case reviewOpenedMsg:
+		r := &m.review
+		r.stage = reviewFinding
+		r.batch = msg.batch
+		r.findings = msg.findings
+		r.decisions = make([]string, len(msg.findings))
+		r.earlier = msg.earlier
+		r.index = 0
+		r.auto, r.hidden = msg.auto, msg.hidden
+
+	case reviewDoneMsg:
+		r := &m.review
+		r.stage = reviewPending
+		r.batch = nil
+		r.note = msg.note
+		return m, tea.Batch(loadReview(m.config), loadDashboardDataWithConfig(m.config))
+
+	case reviewErrorMsg:
+		r := &m.review
+		r.note = "Error: " + msg.err.Error()
+		// A decision that failed to record is asked again on resume
+		if r.batch != nil {
+			r.stage = reviewPending
+			r.batch = nil
+			r.note += "; review paused"
+			return m, loadReview(m.config)
+		}
+
+	case analyticsMsg:
+		a := &m.analytics
+		a.loading = false
+		a.stats, a.err = msg.stats, msg.err
+
+	case experimentsMsg:
+		x := &m.experiments
+		x.list, x.err = msg.list, msg.err
+		if x.cursor >= len(x.list) {
+			x.cursor = max(len(x.list)-1, 0)
+		}
+
+	case experimentsTickMsg:
+		// Keep refreshing while the screen is open
+		if m.currentScreen == experimentsScreen && msg.tick == m.experiments.tick {
+			return m, tea.Batch(loadExperiments(m.home), experimentsTick(msg.tick))
+		}
+
+	case transcriptMsg:
+		m.applyTranscript(msg)
+
+	case transcriptTickMsg:
+		// Keep polling while the screen is open
+		if m.currentScreen == transcriptScreen && msg.tick == m.transcript.tick {
+			return m, tea.Batch(readTranscript(m.transcript.dir, m.transcript.tail), transcriptTick(msg.tick))
+		}
+
+	case stopRequestedMsg:
+		switch {
+		case msg.err != nil:
+			m.transcript.note = "Error: " + msg.err.Error()
+		case msg.withdrawn:
+			m.transcript.note = "Stop signal withdrawn"
+		default:
+			m.transcript.note = "Stop signal sent; the orchestrator stops after the current turn"
+		}
+
+	case paneTailMsg:
+		if msg.err != nil {
+			m.panes.note = "Error: " + msg.err.Error()
+		} else {
+			m.panes.tail = msg.events
+		}
+
+	case paneQueueMsg:
+		if msg.err != nil {
+			m.panes.note = "Error: " + msg.err.Error()
+		} else {
+			m.panes.pending = msg.pending
+		}
+
+	case layoutSavedMsg:
+		if msg.err != nil {
+			m.panes.note = "Error: " + msg.err.Error()
+		}
+
+	case contextMsg:
+		if msg.err != nil {
+			m.experiments.note = "Error: " + msg.err.Error()
+			return m, nil
+		}
+		m.experiments.note = ""
+		return m.switchContext(msg.config)
+
+	case kgStatsMsg:
+		m.triggerNote = msg.note
+
+	case kgConceptsMsg, kgDetailMsg, kgHubsMsg:
+		m = m.handleKGMsg(msg)
+
+	case triggerCheckMsg:
+		m.triggerNote = msg.note
+		return m, loadDashboardDataWithConfig(m.config)
 
 	case errorMsg:
 		m.error = msg.err
 		m.loading = false
 
-	case fileWatchMsg:
-		// File was modified, reload dashboard and continue watching
-		if msg.event.Op&fsnotify.Write == fsnotify.Write {
-			return m, tea.Batch(
-				loadDashboardDataWithConfig(m.config),
-				watchFile(m.config.HotLog), // Continue watching
-			)
+	case newEventMsg:
+		// Drop events from a follower closed by a context switch
+		if msg.follower != m.follower {
+			return m, nil
+		}
+		// Update the dashboard in place instead of re-reading everything
+		d := &m.dashboard
+		d.totalEvents++
+		d.triggers = reevaluateTriggers(m.config, d.totalEvents, d.triggerState, d.triggers)
+		d.latestEvent = msg.event
+		m.panes.addTailEvent(msg.event)
+		d.lastUpdate = time.Now().Format("15:04:05")
+		cmds := []tea.Cmd{waitForEvent(m.follower)}
+		if !m.indexPending {
+			m.indexPending = true
+			cmds = append(cmds, tea.Tick(indexDelay, func(time.Time) tea.Msg { return indexDueMsg{} }))
 		}
-		// For other operations, just continue watching
-		return m, watchFile(m.config.HotLog)
+		if m.currentScreen == analyticsScreen {
+			cmds = append(cmds, loadAnalytics(m.config, m.analytics.days()))
+		}
+		return m, tea.Batch(cmds...)
+
+	case indexDueMsg:
+		m.indexPending = false
+		return m, updateSearchIndex(m.config, m.searchIndex)
+
+	case indexUpdatedMsg:
+		m.indexErr = msg.err
 
 	case fileWatchErrorMsg:
-		// Log error and continue watching after a brief delay
+		if msg.follower != m.follower {
+			return m, nil
+		}
+		// Log error and keep following; the follower recovers on its own
 		log.Printf("File watch error: %v", msg.err)
-		return m, tea.Sequence(
-			tea.Tick(time.Second*2, func(time.Time) tea.Msg { return nil }), // Wait 2 seconds
-			watchFile(m.config.HotLog), // Restart watching
-		)
+		return m, waitForEvent(m.follower)
 	}
 
 	return m, nil
 }
 
+// layoutWidth is the width the screens lay out to
+func (m model) layoutWidth() int {
+	return ui.Width(m.width)
+}
+
 func (m model) View() string {
 	if m.error != nil {
 		return fmt.Sprintf("Error: %v\n\nPress q to quit.", m.error)
@@ -489,10 +799,10 @@ func (m model) View() string {
 	var title string
 	var pathInfo string
 	if m.config.IsConversation {
-		title = titleStyle.Width(80).Render(fmt.Sprintf("KNOWLEDGE SYSTEM - %s", strings.ToUpper(m.config.ContextName)))
+		title = titleStyle.Width(m.layoutWidth()).Render(fmt.Sprintf("KNOWLEDGE SYSTEM - %s", strings.ToUpper(m.config.ContextName)))
 		pathInfo = statusStyle.Render(fmt.Sprintf("Knowledge: %s | Build: %s", m.config.KnowledgeDir, buildTime))
 	} else {
-		title = titleStyle.Width(80).Render("KNOWLEDGE SYSTEM DASHBOARD")
+		title = titleStyle.Width(m.layoutWidth()).Render("KNOWLEDGE SYSTEM DASHBOARD")
 		pathInfo = statusStyle.Render(fmt.Sprintf("Knowledge: %s | Hot Log: %s | Build: %s", m.config.KnowledgeDir, m.config.HotLog, buildTime))
 	}
 	
@@ -513,6 +823,16 @@ func (m model) View() string {
 		breadcrumb = "Processes"
 	case captureScreen:
 		breadcrumb = "Capture"
+	case reviewScreen:
+		breadcrumb = "Review"
+	case experimentsScreen:
+		breadcrumb = "Experiments"
+	case transcriptScreen:
+		breadcrumb = "Transcript"
+	case panesScreen:
+		breadcrumb = "Panes"
+	case kgScreen:
+		breadcrumb = "Knowledge Graph"
 	}
 	
 	contextInfo := ""
@@ -521,7 +841,7 @@ func (m model) View() string {
 	}
 	
 	nav := statusStyle.Render(fmt.Sprintf("Current: %s%s", breadcrumb, contextInfo))
-	separator := separatorStyle.Render(strings.Repeat("═", 80))
+	separator := theme.Rule("═", m.layoutWidth())
 
 	// Screen content
 	var content string
@@ -536,6 +856,16 @@ func (m model) View() string {
 		content = m.renderProcesses()
 	case captureScreen:
 		content = m.renderCapture()
+	case reviewScreen:
+		content = m.renderReview()
+	case experimentsScreen:
+		content = m.renderExperiments()
+	case transcriptScreen:
+		content = m.renderTranscript()
+	case panesScreen:
+		content = m.renderPanes()
+	case kgScreen:
+		content = m.renderKG()
 	}
 
 	// Help text
@@ -560,36 +890,20 @@ func (m model) renderDashboard() string {
 		d.lastUpdate)
 
 	// Analysis triggers
-	triggers := "ANALYSIS TRIGGERS:\n"
-	
-	themeStatus := func() string {
-		if d.eventsUntilTheme <= 0 {
-			return readyStyle.Render("Ready")
-		}
-		return fmt.Sprintf("%d to go", d.eventsUntilTheme)
-	}()
-	
-	connStatus := func() string {
-		if d.eventsUntilConn <= 0 {
-			return readyStyle.Render("Ready")
-		}
-		return fmt.Sprintf("%d to go", d.eventsUntilConn)
-	}()
-	
-	pattStatus := func() string {
-		if d.eventsUntilPatt <= 0 {
-			return readyStyle.Render("Ready")
-		}
-		return fmt.Sprintf("%d to go", d.eventsUntilPatt)
-	}()
-
-	triggers += fmt.Sprintf("  Theme: %s | Connections: %s | Patterns: %s",
-		themeStatus, connStatus, pattStatus)
+	triggerLine := "ANALYSIS TRIGGERS:\n"
+	var parts []string
+	for _, r := range d.triggers {
+		parts = append(parts, fmt.Sprintf("%s: %s", r.Analysis.Label, triggerStatus(r, "to go")))
+	}
+	triggerLine += "  " + strings.Join(parts, " | ")
+	if m.triggerNote != "" {
+		triggerLine += "\n  " + statusStyle.Render(m.triggerNote)
+	}
 
 	// Latest event section - enhanced display
 	latestEventSection := ""
 	if d.latestEvent != nil {
-		latestEventSection = separatorStyle.Render(strings.Repeat("─", 80)) + "\n"
+		latestEventSection = theme.Rule("─", m.layoutWidth()) + "\n"
 		latestEventSection += headerStyle.Render("LATEST EVENT") + "\n"
 		
 		// Format timestamp
@@ -603,24 +917,16 @@ func (m model) renderDashboard() string {
 			readyStyle.Render(d.latestEvent.Type),
 			normalStyle.Render(timestamp))
 		
-		// Get main content - prioritize new format, fallback to legacy
-		var content string
-		if d.latestEvent.Content != "" {
-			content = d.latestEvent.Content
-		} else if d.latestEvent.Thought != "" {
-			content = d.latestEvent.Thought
-		} else if d.latestEvent.Observation != "" {
-			content = d.latestEvent.Observation
-		} else if d.latestEvent.Question != "" {
-			content = d.latestEvent.Question
-		} else {
+		// Legacy thought/observation/question fields are folded into Content
+		content := d.latestEvent.Content
+		if content == "" {
 			content = "No content"
 		}
 		
 		// Content section - allow up to 3 lines
 		if content != "" {
-			// Wrap content to fit within reasonable line lengths (70 chars per line)
-			const maxLineLength = 70
+			// Wrap content to the screen, leaving room for the indent
+			maxLineLength := m.layoutWidth() - 10
 			const maxLines = 3
 			
 			var lines []string
@@ -707,144 +1013,113 @@ func (m model) renderDashboard() string {
 	// Pending reviews
 	pending := ""
 	if d.pendingCount > 0 {
-		pending = separatorStyle.Render(strings.Repeat("─", 80)) + "\n"
+		pending = theme.Rule("─", m.layoutWidth()) + "\n"
 		pending += "PENDING REVIEWS:\n"
 		pending += fmt.Sprintf("  %d analysis/analyses ready for review", d.pendingCount)
 	}
 
-	return fmt.Sprintf("%s\n%s\n%s\n%s\n%s", status, separatorStyle.Render(strings.Repeat("─", 80)), triggers, latestEventSection, pending)
+	return fmt.Sprintf("%s%s\n%s\n%s\n%s\n%s%s", status, m.renderConversationInfo(), theme.Rule("─", m.layoutWidth()), triggerLine, latestEventSection, pending, m.renderPrefsWarnings())
 }
 
-func (m model) renderSearch() string {
-	content := headerStyle.Render("SEARCH KNOWLEDGE BASE") + "\n\n"
-	
-	// Input section
-	if m.inputMode {
-		content += "Enter search term: " + selectedStyle.Render(m.searchInput+"_") + "\n"
-		content += helpStyle.Render("Press Enter to search, Esc to cancel") + "\n\n"
-	} else {
-		content += "Press Enter to start search\n\n"
+// renderConversationInfo summarizes logex-config.yaml in conversation context
+func (m model) renderConversationInfo() string {
+	if !m.config.IsConversation {
+		return ""
 	}
-	
-	// Results section
-	if len(m.searchResults) > 0 {
-		content += separatorStyle.Render(strings.Repeat("─", 60)) + "\n"
-		content += fmt.Sprintf("Results for '%s' (%d found):\n\n", m.searchTerm, len(m.searchResults))
-		
-		displayed := 0
-		for _, result := range m.searchResults {
-			if strings.TrimSpace(result) == "" {
-				continue
-			}
-			if displayed >= 10 { // Limit display
-				content += helpStyle.Render(fmt.Sprintf("... and %d more results", len(m.searchResults)-displayed)) + "\n"
-				break
-			}
-			content += normalStyle.Render("• " + result) + "\n"
-			displayed++
-		}
-	}
-	
-	return content
-}
-
-func (m model) renderAnalytics() string {
-	d := m.dashboard
-	content := headerStyle.Render("KNOWLEDGE SYSTEM ANALYTICS") + "\n\n"
-	
-	content += fmt.Sprintf("Total Events: %s\n", readyStyle.Render(strconv.Itoa(d.totalEvents)))
-	content += fmt.Sprintf("Pending Reviews: %s\n", pendingStyle.Render(strconv.Itoa(d.pendingCount)))
-	content += fmt.Sprintf("Active Processes: %d\n", d.activeProcesses)
-	content += "\n"
-	content += "Analysis Readiness:\n"
-	content += fmt.Sprintf("  Theme Extraction: %s\n", func() string {
-		if d.eventsUntilTheme <= 0 {
-			return readyStyle.Render("Ready")
-		}
-		return fmt.Sprintf("%d events needed", d.eventsUntilTheme)
-	}())
-	content += fmt.Sprintf("  Connection Finding: %s\n", func() string {
-		if d.eventsUntilConn <= 0 {
-			return readyStyle.Render("Ready")
-		}
-		return fmt.Sprintf("%d events needed", d.eventsUntilConn)
-	}())
-	
-	return content
-}
 
-func (m model) renderProcesses() string {
-	content := headerStyle.Render("BACKGROUND PROCESSES") + "\n\n"
-	
-	// Active processes
-	content += fmt.Sprintf("ACTIVE: %s\n", func() string {
-		if m.dashboard.activeProcesses > 0 {
-			return pendingStyle.Render(strconv.Itoa(m.dashboard.activeProcesses))
-		}
-		return "None"
-	}())
-	
-	// Pending reviews
-	if m.dashboard.pendingCount > 0 {
-		content += fmt.Sprintf("\nPENDING (%d):\n", m.dashboard.pendingCount)
-		content += readyStyle.Render("• Reviews available for processing") + "\n"
-	} else {
-		content += "\nPENDING: None\n"
+	info := ""
+	if conv := m.config.Conversation; conv != nil {
+		info += fmt.Sprintf("\nTopic: %s | Conversants: %s | Turns: %d max (%d per conversant)",
+			readyStyle.Render(conv.Conversation.Topic),
+			strings.Join(conv.ConversantNames(), ", "),
+			conv.TurnLimit(),
+			conv.Settings.MaxTurnsPerConversant)
 	}
-	
-	// Analysis triggers status
-	content += "\nTRIGGERS:\n"
-	d := m.dashboard
-	
-	themeReady := d.eventsUntilTheme <= 0
-	connReady := d.eventsUntilConn <= 0
-	pattReady := d.eventsUntilPatt <= 0
-	
-	if themeReady {
-		content += readyStyle.Render("• Theme extraction ready") + "\n"
-	} else {
-		content += fmt.Sprintf("• Theme extraction needs %d more events\n", d.eventsUntilTheme)
+	if m.config.ConversationErr != nil {
+		info += "\n" + pendingStyle.Render(fmt.Sprintf("logex-config.yaml: %v", m.config.ConversationErr))
 	}
-	
-	if connReady {
-		content += readyStyle.Render("• Connection finding ready") + "\n"
-	} else {
-		content += fmt.Sprintf("• Connection finding needs %d more events\n", d.eventsUntilConn)
-	}
-	
-	if pattReady {
-		content += readyStyle.Render("• Pattern analysis ready") + "\n"
-	} else {
-		content += fmt.Sprintf("• Pattern analysis needs %d more events\n", d.eventsUntilPatt)
-	}
-	
-	return content
+	return info
 }
 
-func (m model) renderCapture() string {
-	content := headerStyle.Render("CAPTURE EVENT") + "\n\n"
-	content += "Event capture form coming soon...\n"
-	content += "For now, use the capture tool directly."
-	
-	return content
+// renderPrefsWarnings lists problems found in ksd.toml at startup
+func (m model) renderPrefsWarnings() string {
+	if len(m.prefs.warnings) == 0 {
+		return ""
+	}
+	section := "\n" + theme.Rule("─", m.layoutWidth()) + "\n"
+	section += pendingStyle.Render("PREFERENCES: "+m.prefs.path) + "\n"
+	for _, w := range m.prefs.warnings {
+		section += "  " + pendingStyle.Render(w) + "\n"
+	}
+	return section
 }
 
 func (m model) renderHelp() string {
 	var help string
-	
+	k := m.prefs.keys
+	nav := "Navigation: [" + k.screensLabel() + "] Screens"
+	refresh := "[" + k.label(actionRefresh) + "] Refresh"
+	quit := "[" + k.label(actionQuit) + "] Quit"
+
 	switch m.currentScreen {
 	case dashboardScreen:
-		help = "Navigation: [1-5] Screens • Actions: [R] Review • [T] Triggers • [X] fx • [K] KG • [F] Refresh • [Q] Quit"
+		help = fmt.Sprintf("%s • Actions: [%s] Review • [%s] Triggers • [%s] fx • [%s] KG • %s • %s",
+			nav, k.label(actionOpenReview), k.label(actionCheckTriggers), k.label(actionFx), k.label(actionKGStats), refresh, quit)
 	case searchScreen:
 		if m.inputMode {
 			help = "Input: Type search term • [Enter] Search • [Esc] Cancel • [Backspace] Delete"
 		} else {
-			help = "Navigation: [1-5] Screens • Search: [Enter] Start • [Q] Quit"
+			switch {
+			case m.search.filterInput != noFilterInput:
+				help = "Filter: Type value • [Enter] Apply • [Esc] Cancel"
+			case m.search.detail:
+				help = "Detail: [↑/↓] Scroll • [←/→] Previous/next result • [Esc] Back to results • " + quit
+			default:
+				help = nav + " • Search: [/] New • [↑/↓] Select • [←/→] Page • [Enter] Detail • Filters: [Shift+T] Type • [Shift+O] Topic • [Shift+F] From • [Shift+U] Until • [Shift+X] Clear • " + quit
+			}
+		}
+	case captureScreen:
+		if m.capture.editing {
+			help = "Form: [Tab/Shift+Tab] Field • [←/→] Type • [↑/↓] Topic suggestion • [Tab] Complete • [Ctrl+S] Save • [Esc] Done"
+		} else {
+			help = nav + " • Capture: [Enter] Edit form • " + quit
+		}
+	case reviewScreen:
+		switch m.review.stage {
+		case reviewFinding:
+			help = "Review: [Y] Approve • [N] Reject • [Space] Skip • [Esc] Pause (keeps queue entry)"
+		case reviewReason:
+			help = "Reject: [←/→] or [1-4] Reason • [Enter] Next • [Esc] Back"
+		case reviewNotes:
+			help = "Reject: Type notes • [Enter] Reject • [Esc] Back"
+		default:
+			help = nav + " • [↑/↓] Select • [Enter] Review analysis • " + refresh + " • " + quit
+		}
+	case analyticsScreen:
+		help = nav + " • Window: [←/→] Shorter/longer • [" + k.label(actionRefresh) + "] Recalculate • " + quit
+	case experimentsScreen:
+		help = nav + " • [↑/↓] Select • [Enter] Open dashboard • [L] Transcript • [Esc] Main knowledge base • " + refresh + " • " + quit
+	case transcriptScreen:
+		if m.transcript.confirmStop {
+			help = "Stop: [Y] Send stop signal • Any other key cancels"
+		} else {
+			help = nav + " • [↑/↓] Select • [Home/End] First/follow • [Enter] Expand • [Shift+E] Expand all • [Shift+S] Stop/withdraw • " + quit
+		}
+	case panesScreen:
+		help = nav + " • [Tab] Focus • [↑/↓] Scroll • [ [/] ] Width • [{/}] Height • [O] Pane content • [=] Reset • " + refresh + " • " + quit
+	case kgScreen:
+		switch {
+		case len(m.kg.trail) > 0:
+			help = nav + " • [↑/↓] Select edge • [Enter] Walk to concept • [Esc] Back • [Home] Concept list • [Shift+E] Source events • " + refresh + " • " + quit
+		case m.kg.hubs.show:
+			help = nav + " • [↑/↓] Select • [Enter] Open concept • [Shift+T] Edge type • [Shift+H] Concept list • [Shift+E] Source events • " + refresh + " • " + quit
+		default:
+			help = nav + " • [↑/↓] Select • [Enter] Open concept • [Shift+H] Hubs • [Shift+E] Source events • " + refresh + " • " + quit
 		}
 	case processScreen:
-		help = "Navigation: [1-5] Screens • [F] Refresh • [Q] Quit"
+		help = nav + " • [↑/↓] Select • [Shift+K] Kill • [Shift+C] Clean stale • [Shift+D] Delete record • " + refresh + " • " + quit
 	default:
-		help = "Navigation: [1-5] Screens • [Q] Quit"
+		help = nav + " • " + quit
 	}
 	
 	return helpStyle.Render(help)
@@ -859,45 +1134,101 @@ func main() {
 		fmt.Println("")
 		fmt.Println("Options:")
 		fmt.Println("  --status, -s    Show current status (non-interactive)")
+		fmt.Println("  --format FMT    Status format: text, json or prometheus (default: text)")
 		fmt.Println("  --help, -h      Show this help message")
 		fmt.Println("")
+		fmt.Println("Review Options:")
+		fmt.Println("  --batch-size SIZE              Review N items at once (default: 5)")
+		fmt.Println("  --confidence-threshold VALUE   Filter by confidence level (default: 0.5)")
+		fmt.Println("  --auto-approve                 Auto-approve above threshold")
+		fmt.Println("")
 		fmt.Println("Interactive Mode Navigation:")
 		fmt.Println("  1/D - Dashboard    2/S - Search      3/A - Analytics")
-		fmt.Println("  4/P - Processes    5/C - Capture     Q - Quit")
+		fmt.Println("  4/P - Processes    5/C - Capture     6/V - Review")
+		fmt.Println("  7/E - Experiments  8/L - Transcript  9/W - Panes")
+		fmt.Println("  0/M - Knowledge graph")
+		fmt.Println("  ↑/↓ or K/J - Move   Q - Quit")
 		fmt.Println("")
 		fmt.Println("Dashboard Actions:")
 		fmt.Println("  R - Review findings     T - Check triggers")
-		fmt.Println("  X - fx JSON viewer      K - KG stats")
+		fmt.Println("  X - fx JSON viewer      G - KG stats")
 		fmt.Println("  F - Refresh")
+		fmt.Println("")
+		fmt.Println("Search:")
+		fmt.Println("  / - New search   ↑/↓ - Select   ←/→ - Page   Enter - Detail")
+		fmt.Println("  Shift+T - Type filter   Shift+O - Topic filter")
+		fmt.Println("  Shift+F/Shift+U - From/until date   Shift+X - Clear filters")
+		fmt.Println("")
+		fmt.Println("Analytics:")
+		fmt.Println("  ←/→ - Shorter/longer window (7, 14, 30, 90 days, all)   F - Recalculate")
+		fmt.Println("")
+		fmt.Println("Capture Form:")
+		fmt.Println("  Tab/Shift+Tab - Next/previous field   ←/→ - Event type")
+		fmt.Println("  ↑/↓ - Topic suggestion   Ctrl+S - Save   Esc - Leave form")
+		fmt.Println("")
+		fmt.Println("Review:")
+		fmt.Println("  Enter - Review analysis   Y - Approve   N - Reject   Space - Skip")
+		fmt.Println("  Esc - Pause review (the analysis stays queued)")
+		fmt.Println("")
+		fmt.Println("Experiments:")
+		fmt.Println("  ↑/↓ - Select   Enter - Open the conversation's dashboard")
+		fmt.Println("  L - Live transcript   Esc - Back to the main knowledge base")
+		fmt.Println("")
+		fmt.Println("Transcript:")
+		fmt.Println("  ↑/↓ - Select   Home/End - First entry/follow   Enter - Expand tools or failure")
+		fmt.Println("  Shift+E - Expand/collapse all   Shift+S - Send a manual stop (manual_stop: true), or withdraw one")
+		fmt.Println("")
+		fmt.Println("Panes:")
+		fmt.Println("  Tab/Shift+Tab - Focus   ↑/↓ - Scroll   [/] - Narrower/wider left column")
+		fmt.Println("  {/} - Shorter/taller top right pane   O - Cycle pane content   = - Reset")
+		fmt.Println("  Panes show events, processes (with the review queue), triggers or summary;")
+		fmt.Println("  the layout is saved to ksd-layout.toml next to ksd.toml")
+		fmt.Println("")
+		fmt.Println("Knowledge Graph:")
+		fmt.Println("  ↑/↓ - Select   Enter - Open concept or walk an edge   Esc - Back along the trail")
+		fmt.Println("  Home - Concept list   Shift+E - Search events mentioning the concept or its aliases")
+		fmt.Println("  Shift+H - Hubs: PageRank, betweenness, degree and community of every concept")
+		fmt.Println("  Shift+T - Analyse one edge type at a time (hubs)")
+		fmt.Println("")
+		fmt.Println("Process Actions:")
+		fmt.Println("  ↑/↓ - Select            Shift+K - Kill process")
+		fmt.Println("  Shift+C - Clean stale   Shift+D - Delete finished record")
+		fmt.Println("")
+		fmt.Println("Preferences ($XDG_CONFIG_HOME/ks/ksd.toml, default ~/.config/ks/ksd.toml):")
+		fmt.Println("  default_screen = \"dashboard\"     # search, analytics, processes, capture, review, experiments, transcript, panes, kg")
+		fmt.Println("  refresh_interval = \"30s\"         # \"0\" turns periodic refresh off")
+		fmt.Println("  theme = \"auto\"                   # auto (from the terminal background), dark, light, high-contrast")
+		fmt.Println("  [search]                          # type, topic, since (\"7d\" or YYYY-MM-DD)")
+		fmt.Println("  [keys]                            # e.g. kg_stats = [\"g\"], up = [\"up\", \"k\"]")
+		fmt.Println("  Actions: quit, dashboard, search, analytics, processes, capture, review, experiments,")
+		fmt.Println("  transcript, panes, kg, refresh, open_review, check_triggers, fx, kg_stats, up, down")
+		fmt.Println("  Conflicting or unknown bindings are listed on the dashboard")
+		fmt.Println("  Set NO_COLOR to turn colour off")
 		return
 	}
 
-	// Check for status flag
-	if len(os.Args) > 1 && (os.Args[1] == "--status" || os.Args[1] == "-s") {
-		// Simple built-in status mode
-		_, err := config.LoadKSEnv()
-		if err != nil {
-			log.Fatal(err)
-		}
-		
-		// Get basic stats
-		cmd := exec.Command("bash", "-c", "source .ks-env; source $KS_ROOT/lib/core.sh; source $KS_ROOT/lib/events.sh; ks_count_new_events")
-		output, err := cmd.Output()
-		if err != nil {
-			log.Fatal(err)
+	// Non-interactive status report
+	for _, arg := range os.Args[1:] {
+		if arg == "--status" || arg == "-s" {
+			os.Exit(runStatus(os.Args[1:]))
 		}
-		
-		totalEvents, _ := strconv.Atoi(strings.TrimSpace(string(output)))
-		
-		fmt.Println("Knowledge System Status")
-		fmt.Println("────────────────────────")
-		fmt.Printf("Captured Events: %d\n", totalEvents)
-		fmt.Println("Interactive TUI: ./ksd (no arguments)")
-		return
 	}
 
-	p := tea.NewProgram(initialModel(), tea.WithAltScreen())
-	if _, err := p.Run(); err != nil {
+	reviewOptions, err := parseReviewOptions(os.Args[1:])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ksd: %v\n", err)
+		os.Exit(2)
+	}
+
+	p := tea.NewProgram(initialModel(reviewOptions), tea.WithAltScreen())
+	final, err := p.Run()
+	if err != nil {
 		log.Fatal(err)
 	}
+	// Keep what searches indexed since the last save
+	if m, ok := final.(model); ok && m.searchIndex != nil {
+		if err := m.searchIndex.Save(); err != nil {
+			fmt.Fprintf(os.Stderr, "ksd: saving search index: %v\n", err)
+		}
+	}
 }
\ No newline at end of file
@@ -0,0 +1,413 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/durapensa/ks/pkg/config"
+	"github.com/durapensa/ks/pkg/events"
+	"github.com/durapensa/ks/pkg/fsutil"
+	"github.com/durapensa/ks/pkg/process"
+	"github.com/durapensa/ks/pkg/queue"
+)
+
+// Pane contents
+const (
+	paneEvents    = "events"
+	paneProcesses = "processes"
+	paneTriggers  = "triggers"
+	paneSummary   = "summary"
+)
+
+// paneKinds is the order [O] cycles through
+var paneKinds = []string{paneEvents, paneProcesses, paneTriggers, paneSummary}
+
+// Pane positions, in focus order
+const (
+	leftPane = iota
+	topRightPane
+	bottomRightPane
+	paneCount
+)
+
+// Split limits and the step [ ] { } move them by, in percent
+const (
+	minSplit  = 20
+	maxSplit  = 80
+	splitStep = 5
+)
+
+// paneTailMax is how many recent events the event pane keeps
+const paneTailMax = 200
+
+// paneLayout is what ksd-layout.toml stores
+type paneLayout struct {
+	Panes      []string `toml:"panes"`       // Left, top right, bottom right
+	Split      int      `toml:"split"`       // Width of the left column, percent
+	RightSplit int      `toml:"right_split"` // Height of the top right pane, percent
+	Focus      int      `toml:"focus"`
+}
+
+func defaultPaneLayout() paneLayout {
+	return paneLayout{
+		Panes:      []string{paneEvents, paneProcesses, paneTriggers},
+		Split:      50,
+		RightSplit: 50,
+	}
+}
+
+// panesView is the state of the Panes screen
+type panesView struct {
+	layout paneLayout
+	path   string
+	// offsets scroll each pane; the event tail scrolls back from the newest
+	offsets [paneCount]int
+	tail    []*events.Event
+	pending []queue.Pending
+	note    string
+}
+
+type paneTailMsg struct {
+	events []*events.Event
+	err    error
+}
+
+type paneQueueMsg struct {
+	pending []queue.Pending
+	err     error
+}
+
+type layoutSavedMsg struct {
+	err error
+}
+
+// layoutPath keeps the layout next to ksd.toml
+func layoutPath() string {
+	prefs := prefsPath()
+	if prefs == "" {
+		return ""
+	}
+	return filepath.Join(filepath.Dir(prefs), "ksd-layout.toml")
+}
+
+// loadPaneLayout reads the saved layout. A missing file gives the default;
+// invalid values are replaced by theirs and reported.
+func loadPaneLayout(path string) (paneLayout, error) {
+	layout := defaultPaneLayout()
+	if path == "" {
+		return layout, nil
+	}
+	var saved paneLayout
+	_, err := toml.DecodeFile(path, &saved)
+	if errors.Is(err, os.ErrNotExist) {
+		return layout, nil
+	}
+	if err != nil {
+		return layout, fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	var problems []string
+	if len(saved.Panes) == paneCount {
+		for _, kind := range saved.Panes {
+			if !contains(paneKinds, kind) {
+				problems = append(problems, fmt.Sprintf("unknown pane %q", kind))
+			}
+		}
+		if len(problems) == 0 {
+			layout.Panes = saved.Panes
+		}
+	} else if saved.Panes != nil {
+		problems = append(problems, fmt.Sprintf("want %d panes, got %d", paneCount, len(saved.Panes)))
+	}
+	if saved.Split != 0 {
+		layout.Split = min(max(saved.Split, minSplit), maxSplit)
+	}
+	if saved.RightSplit != 0 {
+		layout.RightSplit = min(max(saved.RightSplit, minSplit), maxSplit)
+	}
+	layout.Focus = min(max(saved.Focus, 0), paneCount-1)
+
+	if len(problems) > 0 {
+		return layout, fmt.Errorf("%s: %s", path, strings.Join(problems, ", "))
+	}
+	return layout, nil
+}
+
+// saveLayout writes the layout in the background
+func saveLayout(path string, layout paneLayout) tea.Cmd {
+	if path == "" {
+		return nil
+	}
+	return func() tea.Msg {
+		var buf bytes.Buffer
+		buf.WriteString("# Written by ksd; panes are events, processes, triggers or summary\n")
+		if err := toml.NewEncoder(&buf).Encode(layout); err != nil {
+			return layoutSavedMsg{fmt.Errorf("encoding layout: %w", err)}
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return layoutSavedMsg{fmt.Errorf("saving layout: %w", err)}
+		}
+		if err := fsutil.WriteFileAtomic(path, buf.Bytes(), 0644); err != nil {
+			return layoutSavedMsg{fmt.Errorf("saving layout: %w", err)}
+		}
+		return layoutSavedMsg{}
+	}
+}
+
+// loadPaneTail reads the most recent events of the hot log, skipping
+// lines that don't parse
+func loadPaneTail(cfg *config.Config) tea.Cmd {
+	return func() tea.Msg {
+		reader, err := events.NewReader(cfg.HotLog)
+		if errors.Is(err, os.ErrNotExist) {
+			return paneTailMsg{}
+		}
+		if err != nil {
+			return paneTailMsg{err: err}
+		}
+		defer reader.Close()
+
+		var tail []*events.Event
+		for {
+			event, err := reader.Next()
+			var parseErr *events.ParseError
+			if errors.As(err, &parseErr) {
+				continue
+			}
+			if err != nil {
+				return paneTailMsg{err: err}
+			}
+			if event == nil {
+				break
+			}
+			tail = append(tail, event)
+			if len(tail) > 2*paneTailMax {
+				tail = tail[len(tail)-paneTailMax:]
+			}
+		}
+		return paneTailMsg{events: tail[max(len(tail)-paneTailMax, 0):]}
+	}
+}
+
+// loadPaneQueue lists analyses waiting for review
+func loadPaneQueue(cfg *config.Config) tea.Cmd {
+	return func() tea.Msg {
+		pending, err := queue.New(cfg.AnalysisQueue).ListPending()
+		return paneQueueMsg{pending: pending, err: err}
+	}
+}
+
+// loadPanes loads everything the panes show
+func (m model) loadPanes() tea.Cmd {
+	return tea.Batch(loadPaneTail(m.config), loadProcesses(m.config), loadPaneQueue(m.config))
+}
+
+// addTailEvent appends an event from the follower
+func (p *panesView) addTailEvent(event *events.Event) {
+	p.tail = append(p.tail, event)
+	if len(p.tail) > paneTailMax {
+		p.tail = p.tail[len(p.tail)-paneTailMax:]
+	}
+}
+
+// handlePanesKey handles keys specific to the Panes screen
+func (m model) handlePanesKey(key string) (model, tea.Cmd, bool) {
+	p := &m.panes
+	l := &p.layout
+	switch key {
+	case "tab":
+		l.Focus = (l.Focus + 1) % paneCount
+	case "shift+tab":
+		l.Focus = (l.Focus + paneCount - 1) % paneCount
+	case "up", "down":
+		delta := 1
+		if (key == "up") != (l.Panes[l.Focus] == paneEvents) {
+			delta = -1
+		}
+		p.offsets[l.Focus] = min(max(p.offsets[l.Focus]+delta, 0), m.paneScrollLimit(l.Focus))
+		return m, nil, true
+	case "[":
+		l.Split = max(l.Split-splitStep, minSplit)
+	case "]":
+		l.Split = min(l.Split+splitStep, maxSplit)
+	case "{":
+		l.RightSplit = max(l.RightSplit-splitStep, minSplit)
+	case "}":
+		l.RightSplit = min(l.RightSplit+splitStep, maxSplit)
+	case "o":
+		// Show the next kind of content in the focused pane, copying the
+		// slice so the saved layout isn't changed under the writer
+		l.Panes = append([]string(nil), l.Panes...)
+		for i, kind := range paneKinds {
+			if kind == l.Panes[l.Focus] {
+				l.Panes[l.Focus] = paneKinds[(i+1)%len(paneKinds)]
+				break
+			}
+		}
+		p.offsets[l.Focus] = 0
+	case "=":
+		*l = defaultPaneLayout()
+		p.offsets = [paneCount]int{}
+	default:
+		return m, nil, false
+	}
+	return m, saveLayout(p.path, *l), true
+}
+
+// paneTitles name each kind of pane
+var paneTitles = map[string]string{
+	paneEvents:    "LIVE EVENTS",
+	paneProcesses: "PROCESSES & QUEUE",
+	paneTriggers:  "TRIGGERS",
+	paneSummary:   "SUMMARY",
+}
+
+// paneAreaHeight is what's left for the panes below the header and above
+// the help line
+func (m model) paneAreaHeight() int {
+	if m.height == 0 {
+		return 20
+	}
+	return max(m.height-11, 8)
+}
+
+// paneSizes are the content width and height of each pane
+func (m model) paneSizes() [paneCount][2]int {
+	l := m.panes.layout
+	width := m.layoutWidth()
+	height := m.paneAreaHeight()
+
+	// Every pane has a one column/row border on each side
+	leftWidth := width*l.Split/100 - 2
+	rightWidth := width - leftWidth - 4
+	topHeight := height*l.RightSplit/100 - 2
+	return [paneCount][2]int{
+		leftPane:        {leftWidth, height - 2},
+		topRightPane:    {rightWidth, topHeight},
+		bottomRightPane: {rightWidth, height - topHeight - 4},
+	}
+}
+
+// paneScrollLimit is the furthest a pane can scroll
+func (m model) paneScrollLimit(pos int) int {
+	size := m.paneSizes()[pos]
+	lines := m.paneLines(m.panes.layout.Panes[pos], size[0])
+	return max(len(lines)-(size[1]-1), 0)
+}
+
+func (m model) renderPanes() string {
+	sizes := m.paneSizes()
+	left := m.renderPane(leftPane, sizes[leftPane][0], sizes[leftPane][1])
+	right := lipgloss.JoinVertical(lipgloss.Left,
+		m.renderPane(topRightPane, sizes[topRightPane][0], sizes[topRightPane][1]),
+		m.renderPane(bottomRightPane, sizes[bottomRightPane][0], sizes[bottomRightPane][1]))
+	content := lipgloss.JoinHorizontal(lipgloss.Top, left, right)
+	if m.panes.note != "" {
+		content += "\n" + pendingStyle.Render(m.panes.note)
+	}
+	return content
+}
+
+// renderPane draws one framed pane of width x height content cells
+func (m model) renderPane(pos, width, height int) string {
+	kind := m.panes.layout.Panes[pos]
+	focused := pos == m.panes.layout.Focus
+	lines := m.paneLines(kind, width)
+
+	// The title stays put; the body scrolls
+	body := max(height-1, 0)
+	limit := max(len(lines)-body, 0)
+	offset := min(m.panes.offsets[pos], limit)
+	if kind == paneEvents {
+		offset = limit - offset
+	}
+	lines = lines[offset:min(offset+body, len(lines))]
+
+	title := labelStyle.Render(paneTitles[kind])
+	frame := theme.Pane
+	if focused {
+		title = theme.Title.Copy().UnsetMarginBottom().Render("▸ " + paneTitles[kind])
+		frame = theme.FocusedPane
+	}
+	text := lipgloss.NewStyle().MaxWidth(width).Render(title + "\n" + strings.Join(lines, "\n"))
+	return frame.Copy().Width(width).Height(height).MaxHeight(height + 2).Render(text)
+}
+
+// paneLines renders the content of a pane, one string per line
+func (m model) paneLines(kind string, width int) []string {
+	var lines []string
+	switch kind {
+	case paneEvents:
+		if len(m.panes.tail) == 0 {
+			return []string{statusStyle.Render("No events yet")}
+		}
+		for _, event := range m.panes.tail {
+			clock := event.Timestamp
+			if t, err := event.Time(); err == nil {
+				clock = t.Local().Format("15:04:05")
+			}
+			head := fmt.Sprintf("%s %-10s ", clock, event.Type)
+			first, _, _ := strings.Cut(event.Content, "\n")
+			lines = append(lines, statusStyle.Render(head)+normalStyle.Render(truncate(first, max(width-len(head), 1))))
+		}
+
+	case paneProcesses:
+		now := time.Now()
+		running := 0
+		for i := range m.processes {
+			proc := &m.processes[i]
+			if proc.Status != process.StatusRunning {
+				continue
+			}
+			running++
+			state := readyStyle.Render("running")
+			if proc.Stale(now) {
+				state = pendingStyle.Render("stale")
+			}
+			lines = append(lines, fmt.Sprintf("%s %s %s", truncate(proc.Task, 24), state, statusStyle.Render(formatDuration(proc.Duration(now)))))
+		}
+		if running == 0 {
+			lines = append(lines, statusStyle.Render("No running processes"))
+		}
+		lines = append(lines, "", labelStyle.Render("Review queue"))
+		if len(m.panes.pending) == 0 {
+			lines = append(lines, statusStyle.Render("Nothing to review"))
+		}
+		for _, pending := range m.panes.pending {
+			lines = append(lines, pendingStyle.Render(pending.Type)+" "+statusStyle.Render(pending.Value.CompletedAt))
+		}
+
+	case paneTriggers:
+		d := m.dashboard
+		if len(d.triggers) == 0 {
+			return []string{statusStyle.Render("Loading...")}
+		}
+		lines = append(lines, fmt.Sprintf("%d events since the last analyses", d.totalEvents))
+		for _, r := range d.triggers {
+			lines = append(lines, fmt.Sprintf("%-14s %s", r.Analysis.Label, triggerStatus(r, "to go")))
+		}
+		if m.triggerNote != "" {
+			lines = append(lines, "", statusStyle.Render(m.triggerNote))
+		}
+
+	case paneSummary:
+		d := m.dashboard
+		lines = append(lines,
+			fmt.Sprintf("Events:    %s", readyStyle.Render(fmt.Sprint(d.totalEvents))),
+			fmt.Sprintf("Reviews:   %d", d.pendingCount),
+			fmt.Sprintf("Active:    %d", d.activeProcesses),
+			fmt.Sprintf("Updated:   %s", d.lastUpdate))
+		if m.config.IsConversation {
+			lines = append(lines, fmt.Sprintf("Context:   %s", m.config.ContextName))
+		}
+	}
+	return lines
+}
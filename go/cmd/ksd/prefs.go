@@ -0,0 +1,149 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/durapensa/ks/pkg/events"
+	"github.com/durapensa/ks/pkg/ui"
+)
+
+// defaultRefreshInterval is how often the dashboard reloads on its own
+const defaultRefreshInterval = 30 * time.Second
+
+// prefsFile is the ksd.toml layout
+type prefsFile struct {
+	DefaultScreen   string              `toml:"default_screen"`
+	RefreshInterval string              `toml:"refresh_interval"`
+	Theme           string              `toml:"theme"`
+	Search          searchPrefs         `toml:"search"`
+	Keys            map[string][]string `toml:"keys"`
+}
+
+// searchPrefs are the filters a new search starts with
+type searchPrefs struct {
+	Type  string `toml:"type"`
+	Topic string `toml:"topic"`
+	Since string `toml:"since"`
+}
+
+// prefs are the resolved user preferences
+type prefs struct {
+	path            string
+	defaultScreen   screenType
+	refreshInterval time.Duration
+	theme           string
+	search          searchPrefs
+	keys            keyMap
+	// warnings are problems found in the file, shown on the dashboard
+	warnings []string
+}
+
+// prefsPath is $XDG_CONFIG_HOME/ks/ksd.toml, defaulting to ~/.config
+func prefsPath() string {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "ks", "ksd.toml")
+}
+
+// screenNames maps default_screen values to screens
+var screenNames = map[string]screenType{
+	"dashboard":   dashboardScreen,
+	"search":      searchScreen,
+	"analytics":   analyticsScreen,
+	"processes":   processScreen,
+	"capture":     captureScreen,
+	"review":      reviewScreen,
+	"experiments": experimentsScreen,
+	"transcript":  transcriptScreen,
+	"panes":       panesScreen,
+	"kg":          kgScreen,
+}
+
+// loadPrefs reads the preferences file. A missing file gives the defaults;
+// invalid values are reported and replaced by their defaults.
+func loadPrefs(path string) prefs {
+	p := prefs{
+		path:            path,
+		defaultScreen:   dashboardScreen,
+		refreshInterval: defaultRefreshInterval,
+		theme:           ui.Auto,
+	}
+
+	var file prefsFile
+	if path != "" {
+		meta, err := toml.DecodeFile(path, &file)
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+		case err != nil:
+			p.warnings = append(p.warnings, fmt.Sprintf("%s: %v", path, err))
+			file = prefsFile{}
+		default:
+			for _, key := range meta.Undecoded() {
+				p.warnings = append(p.warnings, fmt.Sprintf("unknown setting %q", key.String()))
+			}
+		}
+	}
+
+	if file.DefaultScreen != "" {
+		if screen, ok := screenNames[strings.ToLower(file.DefaultScreen)]; ok {
+			p.defaultScreen = screen
+		} else {
+			p.warnings = append(p.warnings, fmt.Sprintf("unknown default_screen %q", file.DefaultScreen))
+		}
+	}
+
+	if file.RefreshInterval != "" {
+		interval, err := time.ParseDuration(file.RefreshInterval)
+		if err != nil || interval < 0 {
+			p.warnings = append(p.warnings, fmt.Sprintf("invalid refresh_interval %q", file.RefreshInterval))
+		} else {
+			p.refreshInterval = interval
+		}
+	}
+
+	if file.Theme != "" {
+		if contains(ui.Names, file.Theme) {
+			p.theme = file.Theme
+		} else {
+			p.warnings = append(p.warnings, fmt.Sprintf("unknown theme %q (want %s)", file.Theme, strings.Join(ui.Names, ", ")))
+		}
+	}
+
+	p.search = file.Search
+	if t := p.search.Type; t != "" && !contains(events.ValidTypes, t) {
+		p.warnings = append(p.warnings, fmt.Sprintf("unknown search type %q", t))
+		p.search.Type = ""
+	}
+	if p.search.Since != "" {
+		if _, err := parseDateFilter(p.search.Since); err != nil {
+			p.warnings = append(p.warnings, fmt.Sprintf("invalid search since %q: %v", p.search.Since, err))
+			p.search.Since = ""
+		}
+	}
+
+	var keyWarnings []string
+	p.keys, keyWarnings = newKeyMap(file.Keys)
+	p.warnings = append(p.warnings, keyWarnings...)
+	return p
+}
+
+// searchFilters are the filters a new search view starts with
+func (p prefs) searchFilters() searchFilters {
+	filters := searchFilters{eventType: p.search.Type, topic: p.search.Topic}
+	if p.search.Since != "" {
+		filters.from, _ = parseDateFilter(p.search.Since)
+	}
+	return filters
+}
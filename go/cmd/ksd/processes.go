@@ -0,0 +1,212 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/durapensa/ks/pkg/config"
+	"github.com/durapensa/ks/pkg/process"
+)
+
+// Process screen messages
+type processesMsg struct {
+	processes []process.Process
+}
+
+type processActionMsg struct {
+	note string
+}
+
+// Load every registry entry for the Processes screen
+func loadProcesses(cfg *config.Config) tea.Cmd {
+	return func() tea.Msg {
+		processes, err := process.NewRegistry(cfg.ProcessRegistry).All()
+		if err != nil {
+			return errorMsg{fmt.Errorf("reading process registry: %w", err)}
+		}
+		return processesMsg{processes: processes}
+	}
+}
+
+// countActiveProcesses counts running entries that are not stale
+func countActiveProcesses(cfg *config.Config) int {
+	active, err := process.NewRegistry(cfg.ProcessRegistry).Active()
+	if err != nil {
+		return 0
+	}
+
+	now := time.Now()
+	count := 0
+	for i := range active {
+		if !active[i].Stale(now) {
+			count++
+		}
+	}
+	return count
+}
+
+// Kill the selected process and record it as failed
+func killProcess(cfg *config.Config, p process.Process) tea.Cmd {
+	return func() tea.Msg {
+		err := process.NewRegistry(cfg.ProcessRegistry).Kill(&p)
+		if errors.Is(err, process.ErrStale) {
+			return processActionMsg{note: fmt.Sprintf("Not killing %s (PID %d): %v; clean up stale entries instead", p.Task, p.PID, process.ErrStale)}
+		}
+		if err != nil {
+			return errorMsg{err}
+		}
+		return processActionMsg{note: fmt.Sprintf("Killed %s (PID %d)", p.Task, p.PID)}
+	}
+}
+
+// Move stale active entries to failed/, like ks_cleanup_stale_processes
+func cleanupStaleProcesses(cfg *config.Config) tea.Cmd {
+	return func() tea.Msg {
+		cleaned, err := process.NewRegistry(cfg.ProcessRegistry).CleanupStale()
+		if err != nil {
+			return errorMsg{fmt.Errorf("cleaning up processes: %w", err)}
+		}
+		return processActionMsg{note: fmt.Sprintf("Cleaned up %d stale process(es)", cleaned)}
+	}
+}
+
+// Delete a finished process record
+func removeProcess(cfg *config.Config, p process.Process) tea.Cmd {
+	return func() tea.Msg {
+		if err := process.NewRegistry(cfg.ProcessRegistry).Remove(&p); err != nil {
+			return errorMsg{err}
+		}
+		return processActionMsg{note: fmt.Sprintf("Removed record for %s", p.Task)}
+	}
+}
+
+// handleProcessKey handles keys specific to the Processes screen
+func (m model) handleProcessKey(key string) (model, tea.Cmd, bool) {
+	switch key {
+	case "up":
+		if m.processCursor > 0 {
+			m.processCursor--
+		}
+		return m, nil, true
+	case "down":
+		if m.processCursor < len(m.processes)-1 {
+			m.processCursor++
+		}
+		return m, nil, true
+	case "K":
+		if p, ok := m.selectedProcess(); ok && p.Status == process.StatusRunning {
+			return m, killProcess(m.config, p), true
+		}
+		return m, nil, true
+	case "C":
+		return m, cleanupStaleProcesses(m.config), true
+	case "D":
+		if p, ok := m.selectedProcess(); ok && p.Status != process.StatusRunning {
+			return m, removeProcess(m.config, p), true
+		}
+		return m, nil, true
+	}
+	return m, nil, false
+}
+
+func (m model) selectedProcess() (process.Process, bool) {
+	if m.processCursor < 0 || m.processCursor >= len(m.processes) {
+		return process.Process{}, false
+	}
+	return m.processes[m.processCursor], true
+}
+
+func formatDuration(d time.Duration) string {
+	return d.Round(time.Second).String()
+}
+
+func (m model) renderProcesses() string {
+	content := headerStyle.Render("BACKGROUND PROCESSES") + "\n\n"
+
+	now := time.Now()
+	var running, succeeded, failed int
+	for i := range m.processes {
+		switch m.processes[i].Status {
+		case process.StatusRunning:
+			running++
+		case process.StatusFailed:
+			failed++
+		default:
+			succeeded++
+		}
+	}
+
+	// Active processes
+	content += fmt.Sprintf("ACTIVE: %s | Completed: %d | Failed: %d\n\n", func() string {
+		if running > 0 {
+			return pendingStyle.Render(strconv.Itoa(running))
+		}
+		return "None"
+	}(), succeeded, failed)
+
+	if len(m.processes) == 0 {
+		content += statusStyle.Render("No background processes registered") + "\n"
+	}
+	for i := range m.processes {
+		p := &m.processes[i]
+
+		var status string
+		switch {
+		case p.Status == process.StatusRunning && p.Stale(now):
+			status = pendingStyle.Render("stale")
+		case p.Status == process.StatusRunning:
+			status = readyStyle.Render("running")
+		case p.Status == process.StatusFailed:
+			status = pendingStyle.Render("failed")
+		default:
+			status = statusStyle.Render(p.Status)
+		}
+
+		line := fmt.Sprintf("%-24s PID %-7d %-8s %s", p.Task, p.PID, formatDuration(p.Duration(now)), p.StartTime)
+		if i == m.processCursor {
+			content += selectedStyle.Render("▸ "+line) + " " + status + "\n"
+		} else {
+			content += normalStyle.Render("  "+line) + " " + status + "\n"
+		}
+		if i == m.processCursor {
+			if p.Description != "" {
+				content += statusStyle.Render("    "+p.Description) + "\n"
+			}
+			if p.OutputFile != "" {
+				content += statusStyle.Render("    Output: "+p.OutputFile) + "\n"
+			}
+			content += statusStyle.Render("    Record: "+filepath.Base(p.File)) + "\n"
+		}
+	}
+
+	if m.processNote != "" {
+		content += "\n" + readyStyle.Render(m.processNote) + "\n"
+	}
+
+	// Pending reviews
+	if m.dashboard.pendingCount > 0 {
+		content += fmt.Sprintf("\nPENDING (%d):\n", m.dashboard.pendingCount)
+		content += readyStyle.Render("• Reviews available for processing") + "\n"
+	} else {
+		content += "\nPENDING: None\n"
+	}
+
+	// Analysis triggers status
+	content += "\nTRIGGERS:\n"
+	for _, r := range m.dashboard.triggers {
+		switch {
+		case r.Pending:
+			content += pendingStyle.Render(fmt.Sprintf("• %s analysis awaiting review", r.Analysis.Label)) + "\n"
+		case r.Ready:
+			content += readyStyle.Render(fmt.Sprintf("• %s analysis ready", r.Analysis.Label)) + "\n"
+		default:
+			content += fmt.Sprintf("• %s analysis needs %d more events\n", r.Analysis.Label, r.Remaining)
+		}
+	}
+
+	return content
+}
@@ -0,0 +1,470 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/durapensa/ks/pkg/config"
+	"github.com/durapensa/ks/pkg/queue"
+	"github.com/durapensa/ks/pkg/review"
+)
+
+// Review screen stages
+type reviewStage int
+
+const (
+	reviewPending reviewStage = iota // Choosing a pending analysis
+	reviewFinding                    // Deciding on findings
+	reviewReason                     // Picking a rejection reason
+	reviewNotes                      // Typing improvement notes
+)
+
+// reviewView is the state of the Review screen
+type reviewView struct {
+	options  review.Options
+	stage    reviewStage
+	batches  []*review.Batch
+	loadErrs []string
+	cursor   int
+
+	// The analysis under review
+	batch     *review.Batch
+	findings  []review.Finding // Those needing a decision
+	decisions []string
+	earlier   map[string]int // Decisions made before the review was paused
+	index     int
+	auto      int
+	hidden    int
+
+	reason int
+	notes  string
+	note   string
+}
+
+// Review screen messages
+type reviewLoadedMsg struct {
+	batches []*review.Batch
+	errs    []string
+}
+
+type reviewOpenedMsg struct {
+	batch    *review.Batch
+	findings []review.Finding
+	earlier  map[string]int
+	auto     int
+	hidden   int
+}
+
+type reviewDoneMsg struct {
+	note string
+}
+
+type reviewErrorMsg struct {
+	err error
+}
+
+// Load every pending analysis and its findings file
+func loadReview(cfg *config.Config) tea.Cmd {
+	return func() tea.Msg {
+		pending, err := queue.New(cfg.AnalysisQueue).ListPending()
+		if err != nil {
+			return reviewErrorMsg{fmt.Errorf("reading analysis queue: %w", err)}
+		}
+
+		msg := reviewLoadedMsg{}
+		for _, p := range pending {
+			batch, err := review.Load(p)
+			if err != nil {
+				msg.errs = append(msg.errs, fmt.Sprintf("%s: %v", p.Type, err))
+				continue
+			}
+			msg.batches = append(msg.batches, batch)
+		}
+		return msg
+	}
+}
+
+// Start or resume reviewing a batch: auto-approve and hide findings by
+// confidence, skip findings decided before a pause, and settle the analysis
+// at once if nothing is left to decide
+func openBatch(cfg *config.Config, opts review.Options, batch *review.Batch) tea.Cmd {
+	return func() tea.Msg {
+		reviewer := review.NewReviewer(cfg)
+
+		if len(batch.Findings) == 0 {
+			if _, err := reviewer.Finish(batch); err != nil {
+				return reviewErrorMsg{err}
+			}
+			return reviewDoneMsg{note: fmt.Sprintf("%s found no significant patterns or themes; cleared from queue", batch.Type)}
+		}
+
+		decided, err := batch.Decisions()
+		if err != nil {
+			return reviewErrorMsg{err}
+		}
+		manual, auto, hidden := opts.Partition(batch.Findings)
+		for _, f := range auto {
+			if _, ok := decided[f.Index]; ok {
+				continue
+			}
+			if err := reviewer.Decide(batch, review.Decision{Index: f.Index, Decision: review.Approved}); err != nil {
+				return reviewErrorMsg{err}
+			}
+		}
+		earlier := map[string]int{}
+		var undecided []review.Finding
+		for _, f := range manual {
+			if d, ok := decided[f.Index]; ok {
+				earlier[d.Decision]++
+			} else {
+				undecided = append(undecided, f)
+			}
+		}
+
+		if len(undecided) == 0 {
+			outcome, err := reviewer.Finish(batch)
+			if err != nil {
+				return reviewErrorMsg{err}
+			}
+			return reviewDoneMsg{note: reviewSummary(batch.Type, outcome, len(auto))}
+		}
+		return reviewOpenedMsg{batch: batch, findings: undecided, earlier: earlier, auto: len(auto), hidden: len(hidden)}
+	}
+}
+
+// Record one decision beside the batch; approvals and rejections are
+// written when the review finishes. After the last finding the analysis is
+// finished too, but only once its decision is safely recorded.
+func recordDecision(cfg *config.Config, batch *review.Batch, f review.Finding, decision, reason, notes string, last bool, auto int) tea.Cmd {
+	return func() tea.Msg {
+		d := review.Decision{Index: f.Index, Decision: decision}
+		if decision == review.Rejected {
+			d.Reason, d.Notes = reason, notes
+		}
+		reviewer := review.NewReviewer(cfg)
+		if err := reviewer.Decide(batch, d); err != nil {
+			return reviewErrorMsg{err}
+		}
+		if !last {
+			return nil
+		}
+		outcome, err := reviewer.Finish(batch)
+		if err != nil {
+			return reviewErrorMsg{err}
+		}
+		return reviewDoneMsg{note: reviewSummary(batch.Type, outcome, auto)}
+	}
+}
+
+// decide records the current finding's decision and moves on, finishing
+// the analysis after its last finding
+func (m model) decide(decision string) (model, tea.Cmd) {
+	r := &m.review
+	f := r.findings[r.index]
+	r.decisions[r.index] = decision
+	last := r.index == len(r.findings)-1
+
+	cmd := recordDecision(m.config, r.batch, f, decision, review.RejectionReasons[r.reason], strings.TrimSpace(r.notes), last, r.auto)
+	r.stage = reviewFinding
+	r.reason, r.notes = 0, ""
+	if !last {
+		r.index++
+	}
+	return m, cmd
+}
+
+// reviewSummary reports a finished review from what Finish settled.
+// Findings left without a decision, such as those below the confidence
+// threshold, are counted rather than silently dropped.
+func reviewSummary(analysis string, outcome *review.Outcome, auto int) string {
+	summary := fmt.Sprintf("%s reviewed: %d approved, %d rejected, %d skipped", analysis,
+		outcome.Approved, outcome.Rejected, outcome.Skipped)
+	if auto > 0 {
+		summary += fmt.Sprintf(" (%d auto-approved)", auto)
+	}
+	if outcome.Undecided > 0 {
+		summary += fmt.Sprintf("; %d without a decision, not recorded", outcome.Undecided)
+	}
+	return summary
+}
+
+// handleReviewKey handles keys specific to the Review screen
+func (m model) handleReviewKey(msg tea.KeyMsg) (model, tea.Cmd, bool) {
+	r := &m.review
+
+	switch r.stage {
+	case reviewPending:
+		switch msg.String() {
+		case "up":
+			if r.cursor > 0 {
+				r.cursor--
+			}
+		case "down":
+			if r.cursor < len(r.batches)-1 {
+				r.cursor++
+			}
+		case "enter":
+			if r.cursor < len(r.batches) {
+				r.note = ""
+				return m, openBatch(m.config, r.options, r.batches[r.cursor]), true
+			}
+		case "f":
+			return m, loadReview(m.config), true
+		default:
+			return m, nil, false
+		}
+		return m, nil, true
+
+	case reviewFinding:
+		switch msg.String() {
+		case "y":
+			next, cmd := m.decide(review.Approved)
+			return next, cmd, true
+		case "n":
+			r.stage = reviewReason
+		case " ":
+			next, cmd := m.decide(review.Skipped)
+			return next, cmd, true
+		case "esc":
+			r.stage = reviewPending
+			r.note = "Review paused; " + r.batch.Type + " stays in the queue"
+			return m, loadReview(m.config), true
+		case "ctrl+c":
+			return m, tea.Quit, true
+		}
+		// Keep every other key from reaching the screen shortcuts mid-review
+		return m, nil, true
+
+	case reviewReason:
+		switch msg.String() {
+		case "left", "up":
+			r.reason = (r.reason + len(review.RejectionReasons) - 1) % len(review.RejectionReasons)
+		case "right", "down", "tab":
+			r.reason = (r.reason + 1) % len(review.RejectionReasons)
+		case "1", "2", "3", "4":
+			r.reason, _ = strconv.Atoi(msg.String())
+			r.reason--
+			r.stage = reviewNotes
+		case "enter":
+			r.stage = reviewNotes
+		case "esc":
+			r.stage = reviewFinding
+		case "ctrl+c":
+			return m, tea.Quit, true
+		}
+		return m, nil, true
+
+	case reviewNotes:
+		switch msg.Type {
+		case tea.KeyEnter:
+			next, cmd := m.decide(review.Rejected)
+			return next, cmd, true
+		case tea.KeyEsc:
+			r.stage = reviewReason
+		case tea.KeyCtrlC:
+			return m, tea.Quit, true
+		case tea.KeyBackspace:
+			if runes := []rune(r.notes); len(runes) > 0 {
+				r.notes = string(runes[:len(runes)-1])
+			}
+		case tea.KeySpace:
+			r.notes += " "
+		case tea.KeyRunes:
+			r.notes += string(msg.Runes)
+		}
+		return m, nil, true
+	}
+	return m, nil, false
+}
+
+func (m model) renderReview() string {
+	r := m.review
+	content := headerStyle.Render("FINDINGS REVIEW") + "\n\n"
+
+	auto := "off"
+	if r.options.AutoApprove {
+		auto = "on"
+	}
+	content += statusStyle.Render(fmt.Sprintf("Batch size: %d | Confidence threshold: %.2f | Auto-approve: %s",
+		r.options.BatchSize, r.options.ConfidenceThreshold, auto)) + "\n\n"
+
+	if r.stage != reviewPending && r.batch != nil {
+		return content + m.renderReviewFinding()
+	}
+
+	if len(r.batches) == 0 && len(r.loadErrs) == 0 {
+		content += "No analyses pending review\n"
+	}
+	for i, b := range r.batches {
+		line := fmt.Sprintf("%-22s completed %s  %s", b.Type, b.CompletedAt, b.Summary())
+		if i == r.cursor {
+			content += selectedStyle.Render("▸ "+line) + "\n"
+		} else {
+			content += normalStyle.Render("  "+line) + "\n"
+		}
+	}
+	for _, e := range r.loadErrs {
+		content += pendingStyle.Render("• "+e) + "\n"
+	}
+	if r.note != "" {
+		content += "\n" + readyStyle.Render(r.note) + "\n"
+	}
+	return content
+}
+
+func (m model) renderReviewFinding() string {
+	r := m.review
+	size := max(r.options.BatchSize, 1)
+	batchStart := (r.index / size) * size
+	batchEnd := min(batchStart+size, len(r.findings))
+
+	content := fmt.Sprintf("Reviewing %s findings — batch %d/%d\n", r.batch.Type,
+		r.index/size+1, (len(r.findings)+size-1)/size)
+	if r.auto > 0 || r.hidden > 0 {
+		content += statusStyle.Render(fmt.Sprintf("%d auto-approved, %d below threshold", r.auto, r.hidden)) + "\n"
+	}
+	if earlier := r.earlier[review.Approved] + r.earlier[review.Rejected] + r.earlier[review.Skipped]; earlier > 0 {
+		content += statusStyle.Render(fmt.Sprintf("Resumed: %d decided before the pause", earlier)) + "\n"
+	}
+	content += "\n"
+
+	// The batch at a glance
+	for i := batchStart; i < batchEnd; i++ {
+		marker := "·"
+		style := normalStyle
+		switch r.decisions[i] {
+		case review.Approved:
+			marker, style = "✓", readyStyle
+		case review.Rejected:
+			marker, style = "✗", pendingStyle
+		case review.Skipped:
+			marker, style = "→", statusStyle
+		}
+		line := fmt.Sprintf("%s %d. %s", marker, i+1, truncate(strings.Join(strings.Fields(r.findings[i].Content()), " "), m.layoutWidth()-10))
+		if i == r.index {
+			content += selectedStyle.Render(line) + "\n"
+		} else {
+			content += style.Render(line) + "\n"
+		}
+	}
+
+	// The current finding in full, as review-findings displays it
+	f := r.findings[r.index]
+	wrap := lipgloss.NewStyle().Width(m.layoutWidth() - 8)
+	indent := func(text string) string {
+		return "  " + strings.ReplaceAll(wrap.Render(text), "\n", "\n  ") + "\n"
+	}
+	bullets := func(items []string) string {
+		var out string
+		for _, item := range items {
+			out += indent("• " + item)
+		}
+		return out
+	}
+
+	content += "\n" + labelStyle.Render(fmt.Sprintf("Finding %d/%d", r.index+1, len(r.findings))) + "\n"
+	content += separatorStyle.Render(strings.Repeat("━", 56)) + "\n"
+	if f.Pattern() != "" {
+		content += labelStyle.Render("Pattern: ") + f.Pattern() + "\n"
+		if f.Description() != "" {
+			content += labelStyle.Render("Description: ") + f.Description() + "\n"
+		}
+		if f.Occurrences() != "" {
+			content += labelStyle.Render("Occurrences: ") + f.Occurrences() + "\n"
+		}
+		if f.Significance() != "" {
+			content += labelStyle.Render("Significance: ") + f.Significance() + "\n"
+		}
+	} else {
+		if f.Name() != "" {
+			content += labelStyle.Render("Name: ") + f.Name() + "\n"
+		}
+		content += labelStyle.Render("Type: ") + f.Type() + "\n"
+		if f.Description() != "" {
+			content += labelStyle.Render("Description:") + "\n" + indent(f.Description())
+		} else if f.Name() == "" {
+			content += labelStyle.Render("Content:") + "\n" + indent(f.Content())
+		}
+		if f.Relationship() != "" {
+			content += labelStyle.Render("Relationship:") + "\n" + indent(f.Relationship())
+		}
+	}
+	if confidence := f.ConfidenceLabel(); confidence != "" {
+		content += labelStyle.Render("Confidence: ") + confidence + "\n"
+	}
+	if examples := f.Examples(); len(examples) > 0 {
+		content += labelStyle.Render("Examples:") + "\n" + bullets(examples)
+	}
+	if quotes := f.Quotes(); len(quotes) > 0 {
+		content += labelStyle.Render("Supporting Evidence:") + "\n" + bullets(quotes)
+	}
+	if linked := f.Events(); len(linked) > 0 {
+		content += labelStyle.Render("Events:") + "\n" + bullets(linked)
+	}
+	content += separatorStyle.Render(strings.Repeat("━", 56)) + "\n"
+
+	switch r.stage {
+	case reviewReason:
+		var reasons []string
+		for i, reason := range review.RejectionReasons {
+			if i == r.reason {
+				reasons = append(reasons, selectedStyle.Render(fmt.Sprintf("[%d] %s", i+1, reason)))
+			} else {
+				reasons = append(reasons, fmt.Sprintf("[%d] %s", i+1, reason))
+			}
+		}
+		content += "\nRejection reason: " + strings.Join(reasons, "  ") + "\n"
+	case reviewNotes:
+		content += "\nRejection reason: " + pendingStyle.Render(review.RejectionReasons[r.reason]) + "\n"
+		content += "Improvement notes (optional): " + selectedStyle.Render(r.notes+"_") + "\n"
+	}
+	return content
+}
+
+// parseReviewOptions reads the INTROSPECT options ksd accepts
+func parseReviewOptions(args []string) (review.Options, error) {
+	opts := review.DefaultOptions()
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		name, value, hasValue := strings.Cut(arg, "=")
+		needValue := func() (string, error) {
+			if hasValue {
+				return value, nil
+			}
+			if i+1 >= len(args) {
+				return "", fmt.Errorf("%s requires a value", name)
+			}
+			i++
+			return args[i], nil
+		}
+
+		switch name {
+		case "--batch-size":
+			v, err := needValue()
+			if err != nil {
+				return opts, err
+			}
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 1 {
+				return opts, fmt.Errorf("invalid --batch-size %q", v)
+			}
+			opts.BatchSize = n
+		case "--confidence-threshold":
+			v, err := needValue()
+			if err != nil {
+				return opts, err
+			}
+			t, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return opts, fmt.Errorf("invalid --confidence-threshold %q", v)
+			}
+			opts.ConfidenceThreshold = t
+		case "--auto-approve":
+			opts.AutoApprove = true
+		}
+	}
+	return opts, nil
+}
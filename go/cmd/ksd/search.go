@@ -0,0 +1,539 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/durapensa/ks/pkg/config"
+	"github.com/durapensa/ks/pkg/events"
+	"github.com/durapensa/ks/pkg/index"
+	"github.com/durapensa/ks/pkg/query"
+)
+
+// Search filter inputs
+type searchFilterField int
+
+const (
+	noFilterInput searchFilterField = iota
+	topicFilterInput
+	fromFilterInput
+	untilFilterInput
+)
+
+// searchFilters are refined in place and re-applied to the current term
+type searchFilters struct {
+	eventType string
+	topic     string
+	from      time.Time
+	until     time.Time // Exclusive
+}
+
+func (f searchFilters) active() bool {
+	return f.eventType != "" || f.topic != "" || !f.from.IsZero() || !f.until.IsZero()
+}
+
+// searchView is the cursor, detail pane and filter state of the Search screen
+type searchView struct {
+	cursor       int
+	detail       bool
+	detailOffset int
+	filters      searchFilters
+	filterInput  searchFilterField
+	filterBuffer string
+	filterErr    error
+	// scan searches the logs without the index, whose quoted phrases must
+	// all match, for terms that are alternatives (a concept's names)
+	scan bool
+}
+
+func searchKnowledgeWithConfig(cfg *config.Config, idx *index.Index, term string, filters searchFilters) tea.Cmd {
+	return func() tea.Msg {
+		engine := newQueryEngine(cfg, idx)
+
+		result, err := engine.Run(query.Options{
+			Search: term,
+			Since:  filters.from,
+			Until:  filters.until,
+			Type:   filters.eventType,
+			Topic:  filters.topic,
+			Limit:  -1, // Paged in the view
+		})
+		if err != nil {
+			return errorMsg{fmt.Errorf("searching: %w", err)}
+		}
+
+		return searchResultsMsg{results: result.Events, total: result.Total, term: term}
+	}
+}
+
+// parseDateFilter accepts YYYY-MM-DD or a relative "Nd" (N days ago)
+func parseDateFilter(input string) (time.Time, error) {
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return time.Time{}, nil
+	}
+	if days, ok := strings.CutSuffix(input, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n >= 0 {
+			return time.Now().UTC().AddDate(0, 0, -n).Truncate(24 * time.Hour), nil
+		}
+	}
+	t, err := time.Parse("2006-01-02", input)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q (use YYYY-MM-DD or Nd)", input)
+	}
+	return t, nil
+}
+
+// searchPageSize fits the result list to the terminal height
+func (m model) searchPageSize() int {
+	if m.height == 0 {
+		return 10
+	}
+	size := (m.height - 16) / 2
+	if size < 3 {
+		return 3
+	}
+	return size
+}
+
+// runSearch repeats the current search with the current filters
+func (m model) runSearch() tea.Cmd {
+	idx := m.searchIndex
+	if m.search.scan {
+		idx = nil
+	}
+	return searchKnowledgeWithConfig(m.config, idx, m.searchTerm, m.search.filters)
+}
+
+// handleSearchKey handles keys specific to the Search screen
+func (m model) handleSearchKey(msg tea.KeyMsg) (model, tea.Cmd, bool) {
+	s := &m.search
+
+	// Filter value input takes every key
+	if s.filterInput != noFilterInput {
+		switch msg.Type {
+		case tea.KeyEnter:
+			if err := m.applyFilterInput(); err != nil {
+				s.filterErr = err
+				return m, nil, true
+			}
+			s.filterInput, s.filterBuffer, s.filterErr = noFilterInput, "", nil
+			return m, m.runSearch(), true
+		case tea.KeyEsc:
+			s.filterInput, s.filterBuffer, s.filterErr = noFilterInput, "", nil
+		case tea.KeyCtrlC:
+			return m, tea.Quit, true
+		case tea.KeyBackspace:
+			if runes := []rune(s.filterBuffer); len(runes) > 0 {
+				s.filterBuffer = string(runes[:len(runes)-1])
+			}
+		case tea.KeySpace:
+			s.filterBuffer += " "
+		case tea.KeyRunes:
+			s.filterBuffer += string(msg.Runes)
+		}
+		return m, nil, true
+	}
+	// So does the search term, whatever the keys are bound to
+	if m.inputMode {
+		switch msg.Type {
+		case tea.KeyEnter:
+			m.inputMode = false
+			m.searchTerm = m.searchInput
+			m.search.scan = false
+			return m, m.runSearch(), true
+		case tea.KeyEsc:
+			m.inputMode = false
+			m.searchInput = ""
+		case tea.KeyCtrlC:
+			return m, tea.Quit, true
+		case tea.KeyBackspace:
+			if runes := []rune(m.searchInput); len(runes) > 0 {
+				m.searchInput = string(runes[:len(runes)-1])
+			}
+		case tea.KeySpace:
+			m.searchInput += " "
+		case tea.KeyRunes:
+			m.searchInput += string(msg.Runes)
+		}
+		return m, nil, true
+	}
+
+	if s.detail {
+		switch msg.String() {
+		case "esc", "enter", "backspace":
+			s.detail = false
+		case "up":
+			if s.detailOffset > 0 {
+				s.detailOffset--
+			}
+		case "down":
+			if s.detailOffset < len(m.searchDetailLines())-m.searchDetailHeight() {
+				s.detailOffset++
+			}
+		case "left":
+			if s.cursor > 0 {
+				s.cursor--
+				s.detailOffset = 0
+			}
+		case "right":
+			if s.cursor < len(m.searchResults)-1 {
+				s.cursor++
+				s.detailOffset = 0
+			}
+		default:
+			return m, nil, false
+		}
+		return m, nil, true
+	}
+
+	page := m.searchPageSize()
+	last := len(m.searchResults) - 1
+	switch msg.String() {
+	case "/":
+		m.inputMode = true
+		m.searchInput = ""
+	case "enter":
+		if len(m.searchResults) == 0 {
+			m.inputMode = true
+			m.searchInput = ""
+			return m, nil, true
+		}
+		s.detail = true
+		s.detailOffset = 0
+	case "up":
+		if s.cursor > 0 {
+			s.cursor--
+		}
+	case "down":
+		if s.cursor < last {
+			s.cursor++
+		}
+	case "pgup", "left":
+		s.cursor = max(s.cursor-page, 0)
+	case "pgdown", "right":
+		s.cursor = max(min(s.cursor+page, last), 0)
+	case "home":
+		s.cursor = 0
+	case "end":
+		s.cursor = max(last, 0)
+	case "T":
+		// Cycle the type filter through all types and back to none
+		next := ""
+		if s.filters.eventType == "" {
+			next = events.ValidTypes[0]
+		} else {
+			for i, t := range events.ValidTypes {
+				if t == s.filters.eventType && i+1 < len(events.ValidTypes) {
+					next = events.ValidTypes[i+1]
+				}
+			}
+		}
+		s.filters.eventType = next
+		return m, m.runSearch(), true
+	case "O":
+		s.filterInput, s.filterBuffer = topicFilterInput, s.filters.topic
+	case "F":
+		s.filterInput, s.filterBuffer = fromFilterInput, formatDateFilter(s.filters.from)
+	case "U":
+		s.filterInput, s.filterBuffer = untilFilterInput, formatUntilFilter(s.filters.until)
+	case "X":
+		s.filters = searchFilters{}
+		return m, m.runSearch(), true
+	default:
+		return m, nil, false
+	}
+	return m, nil, true
+}
+
+// applyFilterInput stores the typed filter value
+func (m *model) applyFilterInput() error {
+	s := &m.search
+	switch s.filterInput {
+	case topicFilterInput:
+		s.filters.topic = strings.TrimSpace(s.filterBuffer)
+	case fromFilterInput:
+		t, err := parseDateFilter(s.filterBuffer)
+		if err != nil {
+			return err
+		}
+		s.filters.from = t
+	case untilFilterInput:
+		t, err := parseDateFilter(s.filterBuffer)
+		if err != nil {
+			return err
+		}
+		if !t.IsZero() {
+			t = t.AddDate(0, 0, 1) // Include the whole day
+		}
+		s.filters.until = t
+	}
+	return nil
+}
+
+func formatDateFilter(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format("2006-01-02")
+}
+
+// formatUntilFilter shows the last included day of an exclusive bound
+func formatUntilFilter(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return formatDateFilter(t.AddDate(0, 0, -1))
+}
+
+// highlighter marks the parts of a text that the search term matched:
+// regex (or literal) matches, and for ranked searches words sharing a stem
+// with a query word as the index matches them
+type highlighter struct {
+	pattern *regexp.Regexp
+	stems   map[string]bool
+}
+
+func newHighlighter(term string, ranked bool) *highlighter {
+	h := &highlighter{stems: map[string]bool{}}
+	if strings.TrimSpace(term) == "" {
+		return h
+	}
+	pattern, err := regexp.Compile("(?i)" + term)
+	if err != nil {
+		pattern = regexp.MustCompile("(?i)" + regexp.QuoteMeta(term))
+	}
+	h.pattern = pattern
+	if !ranked {
+		return h
+	}
+	for _, token := range index.Tokenize(term) {
+		h.stems[token.Term] = true
+	}
+	return h
+}
+
+// Render styles the matched spans of text with highlightStyle
+func (h *highlighter) Render(text string, base lipgloss.Style) string {
+	var spans [][2]int
+	if h.pattern != nil {
+		for _, loc := range h.pattern.FindAllStringIndex(text, -1) {
+			if loc[1] > loc[0] {
+				spans = append(spans, [2]int{loc[0], loc[1]})
+			}
+		}
+	}
+	if len(h.stems) > 0 {
+		start := -1
+		for i, r := range text + " " {
+			isWord := unicode.IsLetter(r) || unicode.IsDigit(r)
+			if isWord && start < 0 {
+				start = i
+			}
+			if !isWord && start >= 0 {
+				if h.stems[index.Stem(strings.ToLower(text[start:i]))] {
+					spans = append(spans, [2]int{start, i})
+				}
+				start = -1
+			}
+		}
+	}
+	if len(spans) == 0 {
+		return base.Render(text)
+	}
+
+	sort.Slice(spans, func(i, j int) bool { return spans[i][0] < spans[j][0] })
+	var out strings.Builder
+	pos := 0
+	for _, span := range spans {
+		if span[1] <= pos {
+			continue
+		}
+		if span[0] > pos {
+			out.WriteString(base.Render(text[pos:span[0]]))
+		} else {
+			span[0] = pos
+		}
+		out.WriteString(highlightStyle.Render(text[span[0]:span[1]]))
+		pos = span[1]
+	}
+	if pos < len(text) {
+		out.WriteString(base.Render(text[pos:]))
+	}
+	return out.String()
+}
+
+// truncate shortens s to n runes, marking the cut with an ellipsis
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	if n <= 1 {
+		return string(runes[:n])
+	}
+	return string(runes[:n-1]) + "…"
+}
+
+func (m model) renderSearch() string {
+	if m.search.detail && m.search.cursor < len(m.searchResults) {
+		return m.renderSearchDetail()
+	}
+
+	s := m.search
+	content := headerStyle.Render("SEARCH KNOWLEDGE BASE") + "\n\n"
+	if m.indexErr != nil {
+		content += pendingStyle.Render("Search index: "+m.indexErr.Error()) + "\n\n"
+	}
+
+	// Input section
+	switch {
+	case m.inputMode:
+		content += "Enter search term: " + selectedStyle.Render(m.searchInput+"_") + "\n"
+		content += helpStyle.Render("Press Enter to search, Esc to cancel") + "\n\n"
+	case s.filterInput != noFilterInput:
+		label := map[searchFilterField]string{
+			topicFilterInput: "Topic",
+			fromFilterInput:  "From (YYYY-MM-DD or Nd)",
+			untilFilterInput: "Until (YYYY-MM-DD or Nd)",
+		}[s.filterInput]
+		content += label + ": " + selectedStyle.Render(s.filterBuffer+"_") + "\n"
+		if s.filterErr != nil {
+			content += pendingStyle.Render(s.filterErr.Error()) + "\n"
+		}
+		content += "\n"
+	case m.searchTerm == "" && len(m.searchResults) == 0:
+		content += "Press Enter or / to start search\n\n"
+	}
+
+	// Filters
+	filter := func(name, value string) string {
+		if value == "" {
+			return name + ": " + statusStyle.Render("any")
+		}
+		return name + ": " + readyStyle.Render(value)
+	}
+	content += strings.Join([]string{
+		filter("Type", s.filters.eventType),
+		filter("Topic", s.filters.topic),
+		filter("From", formatDateFilter(s.filters.from)),
+		filter("Until", formatUntilFilter(s.filters.until)),
+	}, " | ") + "\n"
+
+	// Results section
+	if len(m.searchResults) == 0 {
+		if m.searchTerm != "" || s.filters.active() {
+			content += fmt.Sprintf("\nNo results for '%s'\n", m.searchTerm)
+		}
+		return content
+	}
+
+	page := m.searchPageSize()
+	pageStart := (s.cursor / page) * page
+	pageEnd := min(pageStart+page, len(m.searchResults))
+
+	content += theme.Rule("─", m.layoutWidth()) + "\n"
+	content += fmt.Sprintf("Results for '%s' (%d found) — page %d/%d:\n\n",
+		m.searchTerm, m.searchTotal, pageStart/page+1, (len(m.searchResults)+page-1)/page)
+
+	h := newHighlighter(m.searchTerm, m.searchIndex != nil && !m.search.scan && query.RankedSearch(m.searchTerm))
+	for i := pageStart; i < pageEnd; i++ {
+		event := m.searchResults[i]
+
+		style, marker := normalStyle, "  "
+		if i == s.cursor {
+			style, marker = selectedStyle, "▸ "
+		}
+
+		header := fmt.Sprintf("%s %-10s %s", event.FormatTime(), event.Type, event.Topic)
+		firstLine, _, _ := strings.Cut(event.Content, "\n")
+		content += style.Render(marker+truncate(header, m.layoutWidth()-4)) + "\n"
+		content += style.Render("    ") + h.Render(truncate(firstLine, m.layoutWidth()-6), style) + "\n"
+	}
+
+	return content
+}
+
+// searchDetailHeight is how many detail lines fit on screen
+func (m model) searchDetailHeight() int {
+	if m.height == 0 {
+		return 30
+	}
+	return max(m.height-14, 5)
+}
+
+// searchDetailLines renders every line of the selected result's detail pane
+func (m model) searchDetailLines() []string {
+	event := m.searchResults[m.search.cursor]
+	h := newHighlighter(m.searchTerm, m.searchIndex != nil && !m.search.scan && query.RankedSearch(m.searchTerm))
+
+	var lines []string
+	field := func(name, value string) {
+		lines = append(lines, labelStyle.Render(name+": ")+value)
+	}
+	field("Time", event.FormatTime())
+	field("Type", event.Type)
+	field("Topic", event.Topic)
+	if len(event.Tags) > 0 {
+		field("Tags", strings.Join(event.Tags, ", "))
+	}
+	if event.Context != "" {
+		field("Context", event.Context)
+	}
+
+	lines = append(lines, "", labelStyle.Render("Content:"))
+	wrapped := lipgloss.NewStyle().Width(m.layoutWidth() - 2).Render(event.Content)
+	for _, line := range strings.Split(wrapped, "\n") {
+		lines = append(lines, h.Render(strings.TrimRight(line, " "), normalStyle))
+	}
+
+	if len(event.Metadata) > 0 {
+		lines = append(lines, "", labelStyle.Render("Metadata:"))
+		keys := make([]string, 0, len(event.Metadata))
+		for key := range event.Metadata {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			value, _ := json.Marshal(event.Metadata[key])
+			lines = append(lines, fmt.Sprintf("  %s: %s", key, value))
+		}
+	}
+
+	lines = append(lines, "", labelStyle.Render("Raw JSON:"))
+	var raw bytes.Buffer
+	if err := json.Indent(&raw, []byte(event.Raw), "", "  "); err != nil {
+		raw.Reset()
+		raw.WriteString(event.Raw)
+	}
+	for _, line := range strings.Split(raw.String(), "\n") {
+		lines = append(lines, statusStyle.Render(line))
+	}
+	return lines
+}
+
+func (m model) renderSearchDetail() string {
+	s := m.search
+	lines := m.searchDetailLines()
+
+	// Scroll the pane within the terminal height
+	visible := m.searchDetailHeight()
+	offset := min(s.detailOffset, max(len(lines)-visible, 0))
+	end := min(offset+visible, len(lines))
+
+	content := headerStyle.Render(fmt.Sprintf("RESULT %d OF %d", s.cursor+1, len(m.searchResults))) + "\n\n"
+	content += strings.Join(lines[offset:end], "\n") + "\n"
+	if end < len(lines) {
+		content += statusStyle.Render(fmt.Sprintf("… %d more lines", len(lines)-end)) + "\n"
+	}
+	return content
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/durapensa/ks/pkg/config"
+	"github.com/durapensa/ks/pkg/status"
+)
+
+// parseStatusFormat reads --format (or --format=) from the --status args
+func parseStatusFormat(args []string) (string, error) {
+	format := "text"
+	for i := 0; i < len(args); i++ {
+		name, value, hasValue := strings.Cut(args[i], "=")
+		if name != "--format" && name != "-f" {
+			continue
+		}
+		if !hasValue {
+			if i+1 >= len(args) {
+				return "", fmt.Errorf("%s requires a value", name)
+			}
+			i++
+			value = args[i]
+		}
+		format = value
+	}
+	for _, known := range status.Formats {
+		if format == known {
+			return format, nil
+		}
+	}
+	return "", fmt.Errorf("unknown --format %q (want %s)", format, strings.Join(status.Formats, ", "))
+}
+
+// runStatus prints a status report and returns the exit code
+func runStatus(args []string) int {
+	format, err := parseStatusFormat(args)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ksd: %v\n", err)
+		return 2
+	}
+	cfg, err := config.LoadKSEnv()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ksd: %v\n", err)
+		return 1
+	}
+	if err := status.Collect(cfg, time.Now()).Write(os.Stdout, format); err != nil {
+		fmt.Fprintf(os.Stderr, "ksd: %v\n", err)
+		return 1
+	}
+	return 0
+}
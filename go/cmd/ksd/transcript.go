@@ -0,0 +1,406 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/durapensa/ks/pkg/config"
+	"github.com/durapensa/ks/pkg/logex"
+)
+
+// transcriptRefresh is how often the orchestration log is polled while the
+// Transcript screen is open
+const transcriptRefresh = time.Second
+
+// transcriptView is the state of the Transcript screen
+type transcriptView struct {
+	dir    string
+	name   string
+	config *config.ConversationConfig
+	tail   *logex.Tail
+
+	transcript  *logex.Transcript
+	expanded    map[*logex.Entry]bool
+	cursor      int
+	follow      bool
+	confirmStop bool
+	skipped     int
+	note        string
+	err         error
+	// tick tags poll timers so only the latest loop keeps running
+	tick int
+}
+
+// Transcript screen messages
+type transcriptMsg struct {
+	tail    *logex.Tail
+	events  []*logex.Event
+	reset   bool
+	skipped int
+	err     error
+	stopErr error // From dropping a stop signal left by an earlier run
+}
+
+type transcriptTickMsg struct {
+	tick int
+}
+
+type stopRequestedMsg struct {
+	withdrawn bool
+	err       error
+}
+
+// Read what the orchestrator and conversants appended since the last poll.
+// A new run in dir drops the stop signal left by the one before.
+func readTranscript(dir string, tail *logex.Tail) tea.Cmd {
+	return func() tea.Msg {
+		events, reset, skipped, err := tail.Read()
+		var stopErr error
+		for _, event := range events {
+			if event.Type == logex.ConversationStarted {
+				started, _ := event.Time()
+				stopErr = logex.ClearStaleStop(dir, started)
+			}
+		}
+		return transcriptMsg{tail: tail, events: events, reset: reset, skipped: skipped, err: err, stopErr: stopErr}
+	}
+}
+
+func transcriptTick(tick int) tea.Cmd {
+	return tea.Tick(transcriptRefresh, func(time.Time) tea.Msg {
+		return transcriptTickMsg{tick: tick}
+	})
+}
+
+// Write supervise/stop_signal so the orchestrator ends after this turn
+func requestStop(dir string) tea.Cmd {
+	return func() tea.Msg {
+		return stopRequestedMsg{err: logex.RequestStop(dir)}
+	}
+}
+
+// Remove supervise/stop_signal so the orchestrator carries on
+func withdrawStop(dir string) tea.Cmd {
+	return func() tea.Msg {
+		return stopRequestedMsg{withdrawn: true, err: logex.ClearStop(dir)}
+	}
+}
+
+// openTranscript shows the transcript of the conversation in dir, following
+// it from the start of its orchestration log
+func (m model) openTranscript(dir string, conv *config.ConversationConfig) (model, tea.Cmd) {
+	m.currentScreen = transcriptScreen
+	t := &m.transcript
+	if dir == "" {
+		return m, nil
+	}
+	if t.dir != dir {
+		layout := logex.NewLayout(m.home)
+		*t = transcriptView{
+			dir:        dir,
+			name:       filepath.Base(dir),
+			config:     conv,
+			tail:       logex.NewTail(filepath.Join(dir, layout.OrchestrationLog)),
+			transcript: &logex.Transcript{},
+			expanded:   map[*logex.Entry]bool{},
+			follow:     true,
+			tick:       t.tick,
+		}
+	}
+	t.tick++
+	return m, tea.Batch(readTranscript(t.dir, t.tail), transcriptTick(t.tick))
+}
+
+// currentConversation picks the conversation to transcribe: the current
+// context, else the one selected on the Experiments screen
+func (m model) currentConversation() (string, *config.ConversationConfig) {
+	if m.config.IsConversation {
+		return m.config.ConversationDir, m.config.Conversation
+	}
+	if x := m.experiments; x.cursor < len(x.list) {
+		return x.list[x.cursor].Dir, x.list[x.cursor].Config
+	}
+	return m.transcript.dir, m.transcript.config
+}
+
+// applyTranscript adds newly read events
+func (m *model) applyTranscript(msg transcriptMsg) {
+	t := &m.transcript
+	if msg.tail != t.tail {
+		return
+	}
+	t.err = msg.err
+	if msg.stopErr != nil {
+		t.note = "Error: " + msg.stopErr.Error()
+	}
+	if msg.reset {
+		t.transcript = &logex.Transcript{}
+		t.expanded = map[*logex.Entry]bool{}
+		t.cursor, t.skipped = 0, 0
+	}
+	t.skipped += msg.skipped
+	for _, event := range msg.events {
+		t.transcript.Add(event)
+	}
+	if t.follow {
+		t.cursor = max(len(t.transcript.Entries)-1, 0)
+	}
+}
+
+// handleTranscriptKey handles keys specific to the Transcript screen
+func (m model) handleTranscriptKey(key string) (model, tea.Cmd, bool) {
+	t := &m.transcript
+	if t.confirmStop {
+		t.confirmStop = false
+		if key == "y" {
+			return m, requestStop(t.dir), true
+		}
+		t.note = "Stop cancelled"
+		return m, nil, true
+	}
+	if t.transcript == nil {
+		return m, nil, false
+	}
+
+	last := len(t.transcript.Entries) - 1
+	switch key {
+	case "up":
+		if t.cursor > 0 {
+			t.cursor--
+		}
+		t.follow = false
+		return m, nil, true
+	case "down":
+		if t.cursor < last {
+			t.cursor++
+		}
+		t.follow = t.cursor >= last
+		return m, nil, true
+	case "home":
+		t.cursor, t.follow = 0, false
+		return m, nil, true
+	case "end":
+		t.cursor, t.follow = max(last, 0), true
+		return m, nil, true
+	case "enter", " ":
+		// Expand or collapse tool activity and failures
+		if t.cursor <= last {
+			entry := t.transcript.Entries[t.cursor]
+			if entry.Kind == logex.EntryTools || entry.Kind == logex.EntryFailure {
+				t.expanded[entry] = !t.expanded[entry]
+			}
+		}
+		return m, nil, true
+	case "E":
+		expand := true
+		for _, entry := range t.transcript.Entries {
+			if t.expanded[entry] {
+				expand = false
+				break
+			}
+		}
+		for _, entry := range t.transcript.Entries {
+			if entry.Kind == logex.EntryTools || entry.Kind == logex.EntryFailure {
+				t.expanded[entry] = expand
+			}
+		}
+		return m, nil, true
+	case "S":
+		switch {
+		case logex.StopRequested(t.dir):
+			return m, withdrawStop(t.dir), true
+		case t.config == nil || !t.config.ExitConditions.ManualStop:
+			t.note = "manual_stop is not enabled in " + logex.NewLayout(m.home).ConfigFile
+		case t.transcript.Ended:
+			t.note = "The conversation has already ended"
+		default:
+			t.confirmStop = true
+		}
+		return m, nil, true
+	}
+	return m, nil, false
+}
+
+// conversantStyle colours a conversant by its place in the turn order
+func (t *transcriptView) conversantStyle(name string) lipgloss.Style {
+	index := 0
+	if t.config != nil {
+		for i, n := range t.config.ConversantNames() {
+			if n == name {
+				index = i
+			}
+		}
+	} else {
+		for _, r := range name {
+			index += int(r)
+		}
+	}
+	return theme.SeriesStyle(index)
+}
+
+// transcriptHeight is how many transcript lines fit on screen
+func (m model) transcriptHeight() int {
+	if m.height == 0 {
+		return 25
+	}
+	return max(m.height-16, 5)
+}
+
+func (m model) renderTranscript() string {
+	t := &m.transcript
+	content := headerStyle.Render("TRANSCRIPT") + "\n"
+	if t.dir == "" {
+		return content + statusStyle.Render("No conversation selected. Pick one on the Experiments screen [7] and press [L].") + "\n"
+	}
+
+	// Header: conversation, turn counter and state
+	limit := "?"
+	if t.config != nil && t.config.TurnLimit() > 0 {
+		limit = fmt.Sprint(t.config.TurnLimit())
+	}
+	progress := t.transcript
+	state := "waiting"
+	switch {
+	case progress.Ended:
+		state = "ended"
+	case progress.Started:
+		state = "running"
+	}
+	header := labelStyle.Render(t.name) + fmt.Sprintf("  Turn %s/%s  ", readyStyle.Render(fmt.Sprint(progress.Turns)), limit)
+	header += statusStyle.Render(state)
+	if progress.Failures > 0 {
+		header += "  " + pendingStyle.Render(fmt.Sprintf("%d failures", progress.Failures))
+	}
+	if logex.StopRequested(t.dir) && !progress.Ended {
+		header += "  " + pendingStyle.Render("stop requested")
+	}
+	if t.config != nil {
+		var names []string
+		for _, name := range t.config.ConversantNames() {
+			names = append(names, t.conversantStyle(name).Render(name))
+		}
+		header += "\n" + statusStyle.Render("Topic: "+t.config.Conversation.Topic+" • ") + strings.Join(names, " ")
+	}
+	content += header + "\n\n"
+
+	if t.err != nil {
+		content += pendingStyle.Render("Error: "+t.err.Error()) + "\n"
+	}
+	if len(progress.Entries) == 0 {
+		content += statusStyle.Render("Waiting for "+t.tail.Path) + "\n"
+	}
+
+	// Render every entry, then show the window around the cursor
+	var lines []string
+	cursorStart, cursorEnd := 0, 0
+	for i, entry := range progress.Entries {
+		if i == t.cursor {
+			cursorStart = len(lines)
+		}
+		lines = append(lines, t.renderEntry(entry, i == t.cursor, m.layoutWidth())...)
+		if i == t.cursor {
+			cursorEnd = len(lines)
+		}
+	}
+	height := m.transcriptHeight()
+	start := 0
+	if len(lines) > height {
+		start = min(max(cursorEnd-height, 0), cursorStart)
+		if t.follow {
+			start = len(lines) - height
+		}
+	}
+	end := min(start+height, len(lines))
+	content += strings.Join(lines[start:end], "\n")
+	if len(lines) > 0 {
+		content += "\n"
+	}
+	if end < len(lines) {
+		content += statusStyle.Render(fmt.Sprintf("  ↓ %d more lines", len(lines)-end)) + "\n"
+	}
+
+	if t.skipped > 0 {
+		content += statusStyle.Render(fmt.Sprintf("%d unparseable log lines skipped", t.skipped)) + "\n"
+	}
+	if t.confirmStop {
+		content += "\n" + pendingStyle.Render("Send a manual stop to "+t.name+"? The orchestrator ends after the current turn. [y/N]") + "\n"
+	} else if t.note != "" {
+		content += "\n" + statusStyle.Render(t.note) + "\n"
+	}
+	return content
+}
+
+// renderEntry renders one transcript block as lines
+func (t *transcriptView) renderEntry(entry *logex.Entry, selected bool, width int) []string {
+	marker := "  "
+	if selected {
+		marker = selectedStyle.Render("▸") + " "
+	}
+	clock := ""
+	if !entry.Time.IsZero() {
+		clock = statusStyle.Render(entry.Time.Local().Format("15:04:05"))
+	}
+	expanded := t.expanded[entry]
+
+	switch entry.Kind {
+	case logex.EntryMessage:
+		who := t.conversantStyle(entry.Who).Render(entry.Who)
+		if entry.Fallback {
+			who += " " + pendingStyle.Render("(fallback)")
+		}
+		lines := []string{marker + who + " " + clock}
+		body := lipgloss.NewStyle().Width(width - 6).Render(entry.Text)
+		for _, line := range strings.Split(body, "\n") {
+			lines = append(lines, "    "+strings.TrimRight(line, " "))
+		}
+		return append(lines, "")
+
+	case logex.EntryTools:
+		arrow := "▸"
+		if expanded {
+			arrow = "▾"
+		}
+		summary := fmt.Sprintf("%s %s tool activity (%d events", arrow, entry.Who, len(entry.Tools))
+		if failed := entry.Failed(); failed > 0 {
+			summary += ", " + pendingStyle.Render(fmt.Sprintf("%d failed", failed))
+		}
+		lines := []string{marker + statusStyle.Render(summary+")")}
+		if expanded {
+			for _, tool := range entry.Tools {
+				icon := readyStyle.Render("✓")
+				if tool.Type == logex.ToolFailed {
+					icon = pendingStyle.Render("✗")
+				}
+				text := truncate(strings.ReplaceAll(tool.Content, "\n", " "), width-10)
+				lines = append(lines, "      "+icon+" "+statusStyle.Render(tool.Type+": ")+text)
+			}
+		}
+		return lines
+
+	case logex.EntryFailure:
+		arrow := "▸"
+		if expanded {
+			arrow = "▾"
+		}
+		first, _, _ := strings.Cut(entry.Text, "\n")
+		lines := []string{marker + pendingStyle.Render(arrow+" ✗ "+entry.Who+" "+truncate(first, width-20)) + " " + clock}
+		if expanded {
+			body := lipgloss.NewStyle().Width(width - 8).Render(entry.Text)
+			for _, line := range strings.Split(body, "\n") {
+				lines = append(lines, "      "+pendingStyle.Render(strings.TrimRight(line, " ")))
+			}
+		}
+		return lines
+	}
+
+	// System markers
+	text := entry.Text
+	if entry.Who != "" {
+		text = fmt.Sprintf("Turn %d completed (%s)", entry.Turn, entry.Who)
+	}
+	return []string{marker + separatorStyle.Render("── ") + statusStyle.Render(text) + " " + clock}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/durapensa/ks/pkg/config"
+	"github.com/durapensa/ks/pkg/triggers"
+)
+
+type triggerCheckMsg struct {
+	note string
+}
+
+// Run the event-count triggers and spawn any analyses that are due
+func checkTriggers(cfg *config.Config) tea.Cmd {
+	return func() tea.Msg {
+		runs, err := triggers.New(cfg).Check()
+		if err != nil && len(runs) == 0 {
+			return errorMsg{fmt.Errorf("checking triggers: %w", err)}
+		}
+		if len(runs) == 0 {
+			return triggerCheckMsg{note: "No analyses due"}
+		}
+
+		var names []string
+		for _, run := range runs {
+			names = append(names, run.Analysis.Label)
+		}
+		return triggerCheckMsg{note: "Spawned " + strings.Join(names, ", ") + " analysis"}
+	}
+}
+
+// reevaluateTriggers recomputes readiness for a new event count, keeping
+// the pending-review flags from the last full load
+func reevaluateTriggers(cfg *config.Config, count int, state triggers.State, previous []triggers.Readiness) []triggers.Readiness {
+	updated := triggers.New(cfg).Evaluate(count, state)
+	for i := range updated {
+		for _, p := range previous {
+			if p.Analysis.Name == updated[i].Analysis.Name {
+				updated[i].Pending = p.Pending
+			}
+		}
+	}
+	return updated
+}
+
+// triggerStatus renders one analysis' readiness, e.g. "Ready" or "4 to go"
+func triggerStatus(r triggers.Readiness, needed string) string {
+	switch {
+	case r.Pending:
+		return pendingStyle.Render("Review pending")
+	case r.Ready:
+		return readyStyle.Render("Ready")
+	}
+	return fmt.Sprintf("%d %s", r.Remaining, needed)
+}
@@ -0,0 +1,262 @@
+package analytics
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/durapensa/ks/pkg/config"
+	"github.com/durapensa/ks/pkg/events"
+	"github.com/durapensa/ks/pkg/query"
+)
+
+// claudeSource is the metadata.source of events captured with the
+// [Claude] prefix
+const claudeSource = "claude-conversation"
+
+// Count is a named tally
+type Count struct {
+	Name  string
+	Count int
+}
+
+// Pair is how often two tags appear on the same event
+type Pair struct {
+	A, B  string
+	Count int
+}
+
+// Day is the number of events captured on one local calendar day
+type Day struct {
+	Date   time.Time
+	Human  int
+	Claude int
+}
+
+// Total is the day's combined count
+func (d Day) Total() int {
+	return d.Human + d.Claude
+}
+
+// Stats summarises the events in a time window
+type Stats struct {
+	// Days is the window length; 0 means every event
+	Days  int
+	Since time.Time
+
+	Total  int
+	Human  int
+	Claude int
+
+	PerDay  []Day
+	PerHour [24]int // Local hour of day
+
+	Types    []Count
+	Topics   []Count
+	Tags     []Count
+	TagPairs []Pair
+
+	// Skipped counts unreadable lines and events without a valid timestamp
+	Skipped int
+}
+
+// Analyzer computes Stats from the hot log, its archives and the derived
+// [Claude] stream
+type Analyzer struct {
+	HotLog     string
+	ArchiveDir string
+	StreamLog  string
+}
+
+// New creates an analyzer for the knowledge base in cfg
+func New(cfg *config.Config) *Analyzer {
+	return &Analyzer{
+		HotLog:     cfg.HotLog,
+		ArchiveDir: cfg.ArchiveDir,
+		StreamLog:  filepath.Join(cfg.DerivedDir, "stream.jsonl"),
+	}
+}
+
+// Compute reads every event of the last days days (all events when days is
+// 0) and tallies them
+func (a *Analyzer) Compute(days int, now time.Time) (*Stats, error) {
+	now = now.Local()
+	stats := &Stats{Days: days}
+	if days > 0 {
+		today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+		stats.Since = today.AddDate(0, 0, -(days - 1))
+	}
+
+	t := newTally()
+	for _, file := range query.New(a.HotLog, a.ArchiveDir).Files(stats.Since) {
+		if err := t.readFile(file, stats, false); err != nil {
+			return nil, err
+		}
+	}
+	if a.StreamLog != "" {
+		if _, err := os.Stat(a.StreamLog); err == nil {
+			if err := t.readFile(a.StreamLog, stats, true); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	t.finish(stats, now)
+	return stats, nil
+}
+
+// tally accumulates counts before they are sorted into Stats
+type tally struct {
+	perDay map[time.Time]*Day
+	types  map[string]int
+	topics map[string]int
+	tags   map[string]int
+	pairs  map[[2]string]int
+}
+
+func newTally() *tally {
+	return &tally{
+		perDay: map[time.Time]*Day{},
+		types:  map[string]int{},
+		topics: map[string]int{},
+		tags:   map[string]int{},
+		pairs:  map[[2]string]int{},
+	}
+}
+
+func (t *tally) readFile(path string, stats *Stats, claude bool) error {
+	reader, err := events.NewReader(path)
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", path, err)
+	}
+	defer reader.Close()
+
+	for {
+		event, err := reader.Next()
+		var parseErr *events.ParseError
+		if errors.As(err, &parseErr) {
+			stats.Skipped++
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("reading %s: %w", path, err)
+		}
+		if event == nil {
+			return nil
+		}
+
+		ts, err := event.Time()
+		if err != nil {
+			stats.Skipped++
+			continue
+		}
+		ts = ts.Local()
+		if !stats.Since.IsZero() && ts.Before(stats.Since) {
+			continue
+		}
+		t.add(event, ts, stats, claude || event.Metadata["source"] == claudeSource)
+	}
+}
+
+func (t *tally) add(event *events.Event, ts time.Time, stats *Stats, claude bool) {
+	stats.Total++
+	date := time.Date(ts.Year(), ts.Month(), ts.Day(), 0, 0, 0, 0, time.Local)
+	day, ok := t.perDay[date]
+	if !ok {
+		day = &Day{Date: date}
+		t.perDay[date] = day
+	}
+	if claude {
+		stats.Claude++
+		day.Claude++
+	} else {
+		stats.Human++
+		day.Human++
+	}
+	stats.PerHour[ts.Hour()]++
+
+	if event.Type != "" {
+		t.types[event.Type]++
+	}
+	if event.Topic != "" {
+		t.topics[event.Topic]++
+	}
+
+	// Count each distinct tag once per event, then every unordered pair
+	seen := map[string]bool{}
+	var tags []string
+	for _, tag := range event.Tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		tags = append(tags, tag)
+		t.tags[tag]++
+	}
+	sort.Strings(tags)
+	for i := range tags {
+		for j := i + 1; j < len(tags); j++ {
+			t.pairs[[2]string{tags[i], tags[j]}]++
+		}
+	}
+}
+
+// finish fills the sorted tables and a gap-free day series ending today
+func (t *tally) finish(stats *Stats, now time.Time) {
+	stats.Types = sortCounts(t.types)
+	stats.Topics = sortCounts(t.topics)
+	stats.Tags = sortCounts(t.tags)
+
+	for pair, count := range t.pairs {
+		stats.TagPairs = append(stats.TagPairs, Pair{A: pair[0], B: pair[1], Count: count})
+	}
+	sort.Slice(stats.TagPairs, func(i, j int) bool {
+		a, b := stats.TagPairs[i], stats.TagPairs[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		if a.A != b.A {
+			return a.A < b.A
+		}
+		return a.B < b.B
+	})
+
+	start := stats.Since
+	if start.IsZero() {
+		for date := range t.perDay {
+			if start.IsZero() || date.Before(start) {
+				start = date
+			}
+		}
+	}
+	if start.IsZero() {
+		return
+	}
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	for date := start; !date.After(today); date = date.AddDate(0, 0, 1) {
+		if day, ok := t.perDay[date]; ok {
+			stats.PerDay = append(stats.PerDay, *day)
+		} else {
+			stats.PerDay = append(stats.PerDay, Day{Date: date})
+		}
+	}
+}
+
+func sortCounts(counts map[string]int) []Count {
+	sorted := make([]Count, 0, len(counts))
+	for name, count := range counts {
+		sorted = append(sorted, Count{Name: name, Count: count})
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Count != sorted[j].Count {
+			return sorted[i].Count > sorted[j].Count
+		}
+		return sorted[i].Name < sorted[j].Name
+	})
+	return sorted
+}
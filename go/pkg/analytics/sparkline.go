@@ -0,0 +1,42 @@
+package analytics
+
+import "strings"
+
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// Sparkline renders values as a row of block characters scaled to the
+// largest value. Series wider than width are resampled by summing adjacent
+// buckets; width <= 0 keeps one character per value.
+func Sparkline(values []int, width int) string {
+	if width > 0 && len(values) > width {
+		values = resample(values, width)
+	}
+
+	peak := 0
+	for _, v := range values {
+		peak = max(peak, v)
+	}
+
+	var b strings.Builder
+	for _, v := range values {
+		switch {
+		case v <= 0:
+			b.WriteRune(' ')
+		case peak == 0:
+			b.WriteRune(sparkBlocks[0])
+		default:
+			level := (v*len(sparkBlocks) - 1) / peak
+			b.WriteRune(sparkBlocks[min(level, len(sparkBlocks)-1)])
+		}
+	}
+	return b.String()
+}
+
+// resample sums values into width buckets of near-equal size
+func resample(values []int, width int) []int {
+	buckets := make([]int, width)
+	for i, v := range values {
+		buckets[i*width/len(values)] += v
+	}
+	return buckets
+}
@@ -0,0 +1,270 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Turn-taking strategies understood by tools/logex/orchestrate
+var TurnStrategies = []string{"round_robin"}
+
+// ConversantTypes lists the supported conversant backends
+var ConversantTypes = []string{"claude"}
+
+// ConversationConfig is a typed logex-config.yaml
+type ConversationConfig struct {
+	Path string `yaml:"-"`
+
+	Conversation   ConversationInfo       `yaml:"conversation"`
+	Settings       ConversationSettings   `yaml:"settings"`
+	Conversants    Conversants            `yaml:"conversants"`
+	Dialogue       Dialogue               `yaml:"dialogue"`
+	ExitConditions ExitConditions         `yaml:"exit_conditions"`
+	Experimental   map[string]interface{} `yaml:"experimental,omitempty"`
+
+	// lines maps dotted field paths to their line in the source file
+	lines map[string]int
+}
+
+// ConversationInfo describes the experiment
+type ConversationInfo struct {
+	Name        string `yaml:"name"`
+	Topic       string `yaml:"topic"`
+	Description string `yaml:"description"`
+}
+
+// ConversationSettings holds pacing limits
+type ConversationSettings struct {
+	MaxTurnsPerConversant int `yaml:"max_turns_per_conversant"`
+	TurnDelaySeconds      int `yaml:"turn_delay_seconds"`
+	RateLimitDelay        int `yaml:"rate_limit_delay"`
+}
+
+// Conversant is one participant and its persona
+type Conversant struct {
+	Name    string `yaml:"-"`
+	Type    string `yaml:"type"`
+	Persona string `yaml:"persona"`
+}
+
+// Conversants keeps conversants in file order, which decides turn order
+type Conversants []Conversant
+
+// UnmarshalYAML decodes the conversants mapping preserving key order
+func (c *Conversants) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.MappingNode {
+		return fmt.Errorf("line %d: conversants must be a mapping", node.Line)
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		var conversant Conversant
+		if err := node.Content[i+1].Decode(&conversant); err != nil {
+			return err
+		}
+		conversant.Name = node.Content[i].Value
+		*c = append(*c, conversant)
+	}
+	return nil
+}
+
+// Dialogue controls how the conversation starts and proceeds
+type Dialogue struct {
+	Starter       string     `yaml:"starter"`
+	InitialPrompt string     `yaml:"initial_prompt"`
+	TurnTaking    TurnTaking `yaml:"turn_taking"`
+}
+
+// TurnTaking selects the turn strategy
+type TurnTaking struct {
+	Strategy string `yaml:"strategy"`
+}
+
+// ExitConditions decide when the orchestrator stops
+type ExitConditions struct {
+	MaxTotalTurns int      `yaml:"max_total_turns"`
+	Keywords      []string `yaml:"keywords"`
+	ManualStop    bool     `yaml:"manual_stop"`
+}
+
+// ValidationError is a problem at a specific line of logex-config.yaml
+type ValidationError struct {
+	Line    int
+	Field   string
+	Message string
+}
+
+func (e ValidationError) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("line %d: %s: %s", e.Line, e.Field, e.Message)
+	}
+	return fmt.Sprintf("%s: %s", e.Field, e.Message)
+}
+
+// ValidationErrors collects every problem found in one file
+type ValidationErrors []ValidationError
+
+func (e ValidationErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+// LoadConversationConfig parses and validates a logex-config.yaml. The
+// config is returned alongside ValidationErrors so callers can still show
+// what parsed.
+func LoadConversationConfig(path string) (*ConversationConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+
+	cfg := &ConversationConfig{
+		Path:  path,
+		lines: map[string]int{},
+	}
+	if len(root.Content) == 0 {
+		return cfg, ValidationErrors{{Field: "conversation", Message: "file is empty"}}
+	}
+	if err := root.Decode(cfg); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	recordLines(root.Content[0], "", cfg.lines)
+
+	if errs := cfg.Validate(); len(errs) > 0 {
+		return cfg, errs
+	}
+	return cfg, nil
+}
+
+// recordLines walks mapping nodes, noting the line of every key
+func recordLines(node *yaml.Node, prefix string, lines map[string]int) {
+	if node.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key := node.Content[i].Value
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		lines[key] = node.Content[i].Line
+		recordLines(node.Content[i+1], key, lines)
+	}
+}
+
+// line returns the line of field, or of its closest present ancestor
+func (c *ConversationConfig) line(field string) int {
+	for field != "" {
+		if line, ok := c.lines[field]; ok {
+			return line
+		}
+		dot := strings.LastIndex(field, ".")
+		if dot < 0 {
+			break
+		}
+		field = field[:dot]
+	}
+	return 0
+}
+
+// Validate checks the config the way orchestrate relies on it
+func (c *ConversationConfig) Validate() ValidationErrors {
+	var errs ValidationErrors
+	fail := func(field, format string, args ...interface{}) {
+		errs = append(errs, ValidationError{
+			Line:    c.line(field),
+			Field:   field,
+			Message: fmt.Sprintf(format, args...),
+		})
+	}
+
+	if c.Conversation.Name == "" {
+		fail("conversation.name", "is required")
+	}
+	if c.Conversation.Topic == "" {
+		fail("conversation.topic", "is required")
+	}
+
+	if c.Settings.MaxTurnsPerConversant < 0 {
+		fail("settings.max_turns_per_conversant", "must not be negative")
+	}
+	if c.Settings.TurnDelaySeconds < 0 {
+		fail("settings.turn_delay_seconds", "must not be negative")
+	}
+	if c.Settings.RateLimitDelay < 0 {
+		fail("settings.rate_limit_delay", "must not be negative")
+	}
+
+	if len(c.Conversants) < 2 {
+		fail("conversants", "at least two conversants are required")
+	}
+	for _, conversant := range c.Conversants {
+		field := "conversants." + conversant.Name
+		if !contains(ConversantTypes, conversant.Type) {
+			fail(field+".type", "unsupported type %q (supported: %s)", conversant.Type, strings.Join(ConversantTypes, ", "))
+		}
+		if strings.TrimSpace(conversant.Persona) == "" {
+			fail(field+".persona", "is required")
+		}
+	}
+
+	if c.Dialogue.Starter == "" {
+		fail("dialogue.starter", "is required")
+	} else if c.Conversant(c.Dialogue.Starter) == nil {
+		fail("dialogue.starter", "%q is not a conversant", c.Dialogue.Starter)
+	}
+	if c.Dialogue.TurnTaking.Strategy != "" && !contains(TurnStrategies, c.Dialogue.TurnTaking.Strategy) {
+		fail("dialogue.turn_taking.strategy", "unknown strategy %q (supported: %s)", c.Dialogue.TurnTaking.Strategy, strings.Join(TurnStrategies, ", "))
+	}
+
+	if c.ExitConditions.MaxTotalTurns < 0 {
+		fail("exit_conditions.max_total_turns", "must not be negative")
+	}
+
+	return errs
+}
+
+// Conversant looks up a conversant by name
+func (c *ConversationConfig) Conversant(name string) *Conversant {
+	for i := range c.Conversants {
+		if c.Conversants[i].Name == name {
+			return &c.Conversants[i]
+		}
+	}
+	return nil
+}
+
+// ConversantNames lists conversant names in turn order
+func (c *ConversationConfig) ConversantNames() []string {
+	names := make([]string, len(c.Conversants))
+	for i, conversant := range c.Conversants {
+		names[i] = conversant.Name
+	}
+	return names
+}
+
+// TurnLimit is the effective cap on total turns: max_total_turns when set,
+// otherwise max_turns_per_conversant for each conversant
+func (c *ConversationConfig) TurnLimit() int {
+	if c.ExitConditions.MaxTotalTurns > 0 {
+		return c.ExitConditions.MaxTotalTurns
+	}
+	return c.Settings.MaxTurnsPerConversant * len(c.Conversants)
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
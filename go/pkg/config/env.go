@@ -5,128 +5,299 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 )
 
 // Config holds the knowledge system configuration
 type Config struct {
-	KSRoot         string
-	KnowledgeDir   string
-	EventsDir      string
-	HotLog         string
-	Model          string
-	IsConversation bool
+	KSRoot          string
+	KnowledgeDir    string
+	EventsDir       string
+	HotLog          string
+	Model           string
+	IsConversation  bool
 	ConversationDir string
-	ContextName    string
+	ContextName     string
+
+	ToolsDir        string
+	DerivedDir      string
+	ArchiveDir      string
+	BackgroundDir   string
+	ProcessRegistry string
+	AnalysisQueue   string
+	ExperimentsDir  string
+
+	// Conversation layout, relative to a conversation directory
+	ConversationConfig           string
+	ConversationKnowledgeDir     string
+	ConversationEventsDir        string
+	ConversationHotLog           string
+	ConversationOrchestrationLog string
+
+	// Conversation is the parsed logex-config.yaml in a conversation
+	// directory; ConversationErr holds its parse or validation errors
+	Conversation    *ConversationConfig
+	ConversationErr error
+
+	// Env holds every KS_* variable as resolved from .ks-env
+	Env map[string]string
 }
 
+var (
+	exportLine    = regexp.MustCompile(`^export\s+([A-Za-z_][A-Za-z0-9_]*)=(.*)$`)
+	functionStart = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*\s*\(\)\s*\{`)
+)
+
 // LoadKSEnv reads the .ks-env file and returns configuration
 func LoadKSEnv() (*Config, error) {
-	// Find project root by looking for .ks-env
-	root, err := findProjectRoot()
+	currentDir, err := os.Getwd()
 	if err != nil {
-		return nil, fmt.Errorf("finding project root: %w", err)
+		return nil, fmt.Errorf("getting current directory: %w", err)
 	}
+	return LoadKSEnvAt(currentDir)
+}
 
-	// Check if we're in a conversation directory
-	currentDir, err := os.Getwd()
+// LoadKSEnvAt resolves configuration as if the tools were run from dir
+func LoadKSEnvAt(dir string) (*Config, error) {
+	// Find project root by looking for .ks-env
+	root, err := findProjectRoot(dir)
 	if err != nil {
-		return nil, fmt.Errorf("getting current directory: %w", err)
+		return nil, fmt.Errorf("finding project root: %w", err)
 	}
 
 	config := &Config{
 		KSRoot: root,
 	}
 
+	// Start from the process environment, as bash would
+	vars := map[string]string{}
+	for _, kv := range os.Environ() {
+		if key, value, ok := strings.Cut(kv, "="); ok {
+			vars[key] = value
+		}
+	}
+	if vars["KS_ROOT"] == "" {
+		vars["KS_ROOT"] = root
+	}
+
 	// Detect conversation context
-	localKnowledgeDir := filepath.Join(currentDir, "knowledge")
-	conversationConfig := filepath.Join(currentDir, "logex-config.yaml")
-	
+	localKnowledgeDir := filepath.Join(dir, "knowledge")
+	conversationConfig := filepath.Join(dir, "logex-config.yaml")
+
 	// A conversation directory has ./knowledge/ AND logex-config.yaml
 	// This distinguishes experiment directories from the main ks directory
 	if stat, err := os.Stat(localKnowledgeDir); err == nil && stat.IsDir() {
 		if _, err := os.Stat(conversationConfig); err == nil {
 			// We're in a conversation/experiment directory
 			config.IsConversation = true
-			config.ConversationDir = currentDir
-			config.ContextName = filepath.Base(currentDir)
-			config.KnowledgeDir = localKnowledgeDir
-			config.EventsDir = filepath.Join(localKnowledgeDir, "events")
-			config.HotLog = filepath.Join(config.EventsDir, "hot.jsonl")
+			config.ConversationDir = dir
+			config.ContextName = filepath.Base(dir)
+			config.Conversation, config.ConversationErr = LoadConversationConfig(conversationConfig)
 		}
 	}
 
 	// Load environment file
 	envFile := filepath.Join(root, ".ks-env")
-	file, err := os.Open(envFile)
+	if err := parseEnvFile(envFile, vars); err != nil {
+		return nil, err
+	}
+
+	// logex/claude-instance overrides only these after sourcing .ks-env;
+	// the process registry, analysis queue and background state stay shared
+	if config.IsConversation {
+		vars["KS_HOT_LOG"] = filepath.Join(localKnowledgeDir, "events", "hot.jsonl")
+		vars["KS_DERIVED_DIR"] = filepath.Join(localKnowledgeDir, "derived")
+		vars["KS_TOOLS_DIR"] = filepath.Join(dir, "tools")
+	}
+
+	config.Env = map[string]string{}
+	for key, value := range vars {
+		if strings.HasPrefix(key, "KS_") {
+			config.Env[key] = value
+		}
+	}
+
+	config.KSRoot = vars["KS_ROOT"]
+	config.KnowledgeDir = vars["KS_KNOWLEDGE_DIR"]
+	config.EventsDir = vars["KS_EVENTS_DIR"]
+	if config.IsConversation {
+		// The conversation's own knowledge, as opposed to Env, which is
+		// what the tools see
+		config.KnowledgeDir = localKnowledgeDir
+		config.EventsDir = filepath.Join(localKnowledgeDir, "events")
+	}
+	config.HotLog = vars["KS_HOT_LOG"]
+	config.Model = vars["KS_MODEL"]
+	config.ToolsDir = vars["KS_TOOLS_DIR"]
+	config.DerivedDir = vars["KS_DERIVED_DIR"]
+	config.ArchiveDir = vars["KS_ARCHIVE_DIR"]
+	if config.IsConversation {
+		// Pair the conversation's hot log with its own archive, not the
+		// global one the tools are still given
+		config.ArchiveDir = filepath.Join(config.EventsDir, "archive")
+	}
+	config.BackgroundDir = vars["KS_BACKGROUND_DIR"]
+	config.ProcessRegistry = vars["KS_PROCESS_REGISTRY"]
+	config.AnalysisQueue = vars["KS_ANALYSIS_QUEUE"]
+	config.ExperimentsDir = vars["KS_EXPERIMENTS_DIR"]
+	config.ConversationConfig = vars["KS_CONVERSATION_CONFIG"]
+	config.ConversationKnowledgeDir = vars["KS_CONVERSATION_KNOWLEDGE_DIR"]
+	config.ConversationEventsDir = vars["KS_CONVERSATION_EVENTS_DIR"]
+	config.ConversationHotLog = vars["KS_CONVERSATION_HOT_LOG"]
+	config.ConversationOrchestrationLog = vars["KS_CONVERSATION_ORCHESTRATION_LOG"]
+
+	return config, nil
+}
+
+// parseEnvFile evaluates the top-level export statements of a .ks-env file
+// into vars. Function bodies (the PATH setup) are skipped.
+func parseEnvFile(path string, vars map[string]string) error {
+	file, err := os.Open(path)
 	if err != nil {
-		return nil, fmt.Errorf("opening .ks-env: %w", err)
+		return fmt.Errorf("opening .ks-env: %w", err)
 	}
 	defer file.Close()
 
-	// Parse shell environment variables
+	inFunction := false
 	scanner := bufio.NewScanner(file)
 	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		
+		raw := scanner.Text()
+		line := strings.TrimSpace(raw)
+
 		// Skip comments and empty lines
 		if line == "" || strings.HasPrefix(line, "#") {
 			continue
 		}
 
+		if inFunction {
+			if strings.HasPrefix(raw, "}") {
+				inFunction = false
+			}
+			continue
+		}
+		if functionStart.MatchString(line) {
+			inFunction = true
+			continue
+		}
+
 		// Parse export statements
-		if strings.HasPrefix(line, "export ") {
-			line = strings.TrimPrefix(line, "export ")
-			parts := strings.SplitN(line, "=", 2)
-			if len(parts) == 2 {
-				key := parts[0]
-				value := strings.Trim(parts[1], `"'`)
-				
-				// Expand variables like $KS_ROOT
-				value = os.Expand(value, func(v string) string {
-					switch v {
-					case "KS_ROOT":
-						return config.KSRoot
-					default:
-						return os.Getenv(v)
-					}
-				})
-
-				switch key {
-				case "KS_KNOWLEDGE_DIR":
-					if !config.IsConversation {
-						config.KnowledgeDir = value
-					}
-				case "KS_EVENTS_DIR":
-					if !config.IsConversation {
-						config.EventsDir = value
-					}
-				case "KS_HOT_LOG":
-					if !config.IsConversation {
-						config.HotLog = value
-					}
-				case "KS_MODEL":
-					config.Model = value
-				}
+		match := exportLine.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		vars[match[1]] = evalValue(match[2], vars)
+	}
+
+	return scanner.Err()
+}
+
+// evalValue handles the quoting forms .ks-env uses: 'literal', "expanded"
+// and bare words
+func evalValue(value string, vars map[string]string) string {
+	if len(value) >= 2 && value[0] == '\'' && value[len(value)-1] == '\'' {
+		return value[1 : len(value)-1]
+	}
+	if len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"' {
+		value = value[1 : len(value)-1]
+	}
+	return Expand(value, func(name string) (string, bool) {
+		v, ok := vars[name]
+		return v, ok
+	})
+}
+
+// Expand performs the subset of bash parameter expansion used by .ks-env:
+// $VAR, ${VAR}, ${VAR:-default} and ${VAR-default}. Defaults are only
+// expanded when used, and may nest further references. Command
+// substitutions $(...) expand to nothing.
+func Expand(s string, lookup func(string) (string, bool)) string {
+	var out strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '$' || i+1 >= len(s) {
+			out.WriteByte(s[i])
+			continue
+		}
+
+		switch next := s[i+1]; {
+		case next == '{':
+			end := matchingClose(s, i+1, '{', '}')
+			if end < 0 {
+				out.WriteString(s[i:])
+				return out.String()
+			}
+			out.WriteString(expandBraced(s[i+2:end], lookup))
+			i = end
+		case next == '(':
+			end := matchingClose(s, i+1, '(', ')')
+			if end < 0 {
+				return out.String()
 			}
+			i = end
+		case isNameStart(next):
+			j := i + 1
+			for j < len(s) && isNameChar(s[j]) {
+				j++
+			}
+			value, _ := lookup(s[i+1 : j])
+			out.WriteString(value)
+			i = j - 1
+		default:
+			out.WriteByte(s[i])
 		}
 	}
+	return out.String()
+}
 
-	// Apply environment overrides
-	if val := os.Getenv("KS_MODEL"); val != "" {
-		config.Model = val
+// expandBraced evaluates the inside of ${...}
+func expandBraced(expr string, lookup func(string) (string, bool)) string {
+	j := 0
+	for j < len(expr) && isNameChar(expr[j]) {
+		j++
 	}
+	name, rest := expr[:j], expr[j:]
+	value, set := lookup(name)
 
-	return config, scanner.Err()
+	switch {
+	case strings.HasPrefix(rest, ":-"):
+		if value == "" {
+			return Expand(rest[2:], lookup)
+		}
+	case strings.HasPrefix(rest, "-"):
+		if !set {
+			return Expand(rest[1:], lookup)
+		}
+	}
+	return value
 }
 
-func findProjectRoot() (string, error) {
-	// Start from current directory and walk up
-	dir, err := os.Getwd()
-	if err != nil {
-		return "", err
+// matchingClose finds the index of the bracket closing the one at start
+func matchingClose(s string, start int, open, close byte) int {
+	depth := 0
+	for i := start; i < len(s); i++ {
+		switch s[i] {
+		case open:
+			depth++
+		case close:
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
 	}
+	return -1
+}
+
+func isNameStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isNameChar(c byte) bool {
+	return isNameStart(c) || (c >= '0' && c <= '9')
+}
 
+func findProjectRoot(dir string) (string, error) {
+	// Start from dir and walk up
 	for {
 		if _, err := os.Stat(filepath.Join(dir, ".ks-env")); err == nil {
 			return dir, nil
@@ -140,4 +311,4 @@ func findProjectRoot() (string, error) {
 	}
 
 	return "", fmt.Errorf(".ks-env not found")
-}
\ No newline at end of file
+}
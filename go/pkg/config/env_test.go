@@ -0,0 +1,70 @@
+package config
+
+import "testing"
+
+var testVars = map[string]string{
+	"ROOT":  "/ks",
+	"EMPTY": "",
+	"A_B":   "ab",
+}
+
+func testLookup(name string) (string, bool) {
+	value, ok := testVars[name]
+	return value, ok
+}
+
+// Expand follows bash for the parameter forms .ks-env uses
+func TestExpand(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"plain", "plain"},
+		{"$ROOT/knowledge", "/ks/knowledge"},
+		{"${ROOT}/knowledge", "/ks/knowledge"},
+		{"$A_B", "ab"},
+		{"${ROOT}_B", "/ks_B"},
+		{"$ROOT_B", ""},
+		{"$UNSET/x", "/x"},
+		// :- replaces unset and empty, - only unset
+		{"${UNSET:-/tmp}", "/tmp"},
+		{"${EMPTY:-/tmp}", "/tmp"},
+		{"${ROOT:-/tmp}", "/ks"},
+		{"${UNSET-/tmp}", "/tmp"},
+		{"${EMPTY-/tmp}", ""},
+		// Defaults expand, and nest
+		{"${UNSET:-$ROOT/events}", "/ks/events"},
+		{"${UNSET:-${EMPTY:-${ROOT}}/hot.jsonl}", "/ks/hot.jsonl"},
+		// Command substitutions are dropped
+		{"$(pwd)/knowledge", "/knowledge"},
+		{"$(dirname $(pwd))", ""},
+		// A lone or unfinished $ stays literal
+		{"cost: $", "cost: $"},
+		{"a $ b", "a $ b"},
+		{"${ROOT", "${ROOT"},
+	}
+	for _, tt := range tests {
+		if got := Expand(tt.in, testLookup); got != tt.want {
+			t.Errorf("Expand(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+// evalValue treats quotes as the shell does for an export's value
+func TestEvalValue(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{`'$ROOT/knowledge'`, "$ROOT/knowledge"},
+		{`"$ROOT/knowledge"`, "/ks/knowledge"},
+		{`"${UNSET:-$ROOT}"`, "/ks"},
+		{`$ROOT/knowledge`, "/ks/knowledge"},
+		{`""`, ""},
+		{`''`, ""},
+		{`sonnet`, "sonnet"},
+	}
+	for _, tt := range tests {
+		if got := evalValue(tt.in, testVars); got != tt.want {
+			t.Errorf("evalValue(%s) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
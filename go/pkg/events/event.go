@@ -0,0 +1,158 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Schema versions for events found on disk
+const (
+	// SchemaLegacy covers early events written with "timestamp", string tags
+	// and thought/observation/question fields instead of content
+	SchemaLegacy = 0
+	// SchemaCurrent is the {ts, type, topic, content, metadata} shape written
+	// by tools/capture/events
+	SchemaCurrent = 1
+)
+
+// Event represents a knowledge system event
+type Event struct {
+	Timestamp string
+	Type      string
+	Topic     string
+	Content   string
+	Context   string
+	Tags      []string
+	Metadata  map[string]interface{}
+
+	// Schema records which on-disk shape the event was decoded from
+	Schema int
+	// Raw holds the original JSON line when the event was read from a file
+	Raw string
+}
+
+// rawEvent mirrors every field name that has appeared in hot.jsonl
+type rawEvent struct {
+	Ts          string                 `json:"ts"`
+	Timestamp   string                 `json:"timestamp"`
+	Type        string                 `json:"type"`
+	Topic       string                 `json:"topic"`
+	Content     string                 `json:"content"`
+	Thought     string                 `json:"thought"`
+	Observation string                 `json:"observation"`
+	Question    string                 `json:"question"`
+	Context     string                 `json:"context"`
+	Tags        json.RawMessage        `json:"tags"`
+	Metadata    map[string]interface{} `json:"metadata"`
+}
+
+// UnmarshalJSON decodes any historical event shape into the canonical Event
+func (e *Event) UnmarshalJSON(data []byte) error {
+	var raw rawEvent
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	tags, err := parseTags(raw.Tags)
+	if err != nil {
+		return fmt.Errorf("parsing tags: %w", err)
+	}
+
+	*e = Event{
+		Timestamp: raw.Ts,
+		Type:      raw.Type,
+		Topic:     raw.Topic,
+		Content:   raw.Content,
+		Context:   raw.Context,
+		Tags:      tags,
+		Metadata:  raw.Metadata,
+		Schema:    SchemaCurrent,
+	}
+
+	if e.Timestamp == "" {
+		e.Timestamp = raw.Timestamp
+		e.Schema = SchemaLegacy
+	}
+
+	// Legacy events stored their text under a type-specific key
+	if e.Content == "" {
+		for _, legacy := range []string{raw.Thought, raw.Observation, raw.Question} {
+			if legacy != "" {
+				e.Content = legacy
+				e.Schema = SchemaLegacy
+				break
+			}
+		}
+	}
+
+	return nil
+}
+
+// MarshalJSON always writes the current schema
+func (e Event) MarshalJSON() ([]byte, error) {
+	metadata := e.Metadata
+	if metadata == nil {
+		metadata = map[string]interface{}{}
+	}
+
+	return json.Marshal(struct {
+		Ts       string                 `json:"ts"`
+		Type     string                 `json:"type"`
+		Topic    string                 `json:"topic"`
+		Content  string                 `json:"content"`
+		Context  string                 `json:"context,omitempty"`
+		Tags     []string               `json:"tags,omitempty"`
+		Metadata map[string]interface{} `json:"metadata"`
+	}{e.Timestamp, e.Type, e.Topic, e.Content, e.Context, e.Tags, metadata})
+}
+
+// parseTags accepts either a JSON array or a comma-separated string
+func parseTags(data json.RawMessage) ([]string, error) {
+	if len(data) == 0 || string(data) == "null" {
+		return nil, nil
+	}
+
+	var list []string
+	if err := json.Unmarshal(data, &list); err == nil {
+		return list, nil
+	}
+
+	var joined string
+	if err := json.Unmarshal(data, &joined); err != nil {
+		return nil, err
+	}
+
+	var tags []string
+	for _, tag := range strings.Split(joined, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags, nil
+}
+
+// Parse decodes a single JSONL line into an Event
+func Parse(line []byte) (*Event, error) {
+	var event Event
+	if err := json.Unmarshal(line, &event); err != nil {
+		return nil, err
+	}
+	event.Raw = string(line)
+	return &event, nil
+}
+
+// Time parses the event timestamp
+func (e *Event) Time() (time.Time, error) {
+	return time.Parse(time.RFC3339, e.Timestamp)
+}
+
+// FormatTime formats the event timestamp for display
+func (e *Event) FormatTime() string {
+	t, err := e.Time()
+	if err != nil {
+		return e.Timestamp
+	}
+	return t.Format("2006-01-02 15:04")
+}
@@ -0,0 +1,78 @@
+package events
+
+import (
+	"reflect"
+	"testing"
+)
+
+// Every event shape found in hot.jsonl decodes to the same Event
+func TestEventUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want Event
+	}{
+		{
+			name: "current",
+			line: `{"ts":"2025-06-17T10:00:00Z","type":"thought","topic":"memory","content":"Spaced recall works","metadata":{"source":"cli"}}`,
+			want: Event{Timestamp: "2025-06-17T10:00:00Z", Type: "thought", Topic: "memory", Content: "Spaced recall works",
+				Metadata: map[string]interface{}{"source": "cli"}, Schema: SchemaCurrent},
+		},
+		{
+			name: "current with tag list and context",
+			line: `{"ts":"2025-06-17T10:00:00Z","type":"insight","topic":"t","content":"c","context":"reading","tags":["a","b"],"metadata":{}}`,
+			want: Event{Timestamp: "2025-06-17T10:00:00Z", Type: "insight", Topic: "t", Content: "c", Context: "reading",
+				Tags: []string{"a", "b"}, Metadata: map[string]interface{}{}, Schema: SchemaCurrent},
+		},
+		{
+			name: "ts wins over timestamp",
+			line: `{"ts":"2025-06-17T10:00:00Z","timestamp":"2025-01-01T00:00:00Z","type":"thought","content":"c"}`,
+			want: Event{Timestamp: "2025-06-17T10:00:00Z", Type: "thought", Content: "c", Schema: SchemaCurrent},
+		},
+		{
+			name: "legacy timestamp",
+			line: `{"timestamp":"2025-01-01T00:00:00Z","type":"thought","topic":"t","content":"c"}`,
+			want: Event{Timestamp: "2025-01-01T00:00:00Z", Type: "thought", Topic: "t", Content: "c", Schema: SchemaLegacy},
+		},
+		{
+			name: "legacy thought with tag string",
+			line: `{"timestamp":"2025-01-01T00:00:00Z","type":"thought","thought":"An idea","tags":"a, b,,c "}`,
+			want: Event{Timestamp: "2025-01-01T00:00:00Z", Type: "thought", Content: "An idea",
+				Tags: []string{"a", "b", "c"}, Schema: SchemaLegacy},
+		},
+		{
+			name: "legacy observation under ts",
+			line: `{"ts":"2025-01-01T00:00:00Z","type":"observation","observation":"Seen"}`,
+			want: Event{Timestamp: "2025-01-01T00:00:00Z", Type: "observation", Content: "Seen", Schema: SchemaLegacy},
+		},
+		{
+			name: "legacy question",
+			line: `{"timestamp":"2025-01-01T00:00:00Z","type":"question","question":"Why?"}`,
+			want: Event{Timestamp: "2025-01-01T00:00:00Z", Type: "question", Content: "Why?", Schema: SchemaLegacy},
+		},
+		{
+			name: "content wins over legacy fields",
+			line: `{"ts":"2025-01-01T00:00:00Z","type":"thought","content":"new","thought":"old"}`,
+			want: Event{Timestamp: "2025-01-01T00:00:00Z", Type: "thought", Content: "new", Schema: SchemaCurrent},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse([]byte(tt.line))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got.Raw = ""
+			if !reflect.DeepEqual(*got, tt.want) {
+				t.Errorf("Parse(%s)\n got %+v\nwant %+v", tt.line, *got, tt.want)
+			}
+		})
+	}
+}
+
+// Tags that are neither a list nor a string are an error, not dropped
+func TestEventUnmarshalJSONBadTags(t *testing.T) {
+	if _, err := Parse([]byte(`{"ts":"2025-01-01T00:00:00Z","type":"thought","content":"c","tags":3}`)); err == nil {
+		t.Error("numeric tags: want an error")
+	}
+}
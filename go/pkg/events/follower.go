@@ -0,0 +1,305 @@
+package events
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/durapensa/ks/pkg/fsutil"
+	"github.com/fsnotify/fsnotify"
+)
+
+// pollInterval is the fallback re-check period for filesystems where
+// fsnotify misses events (network mounts, some container setups)
+const pollInterval = 2 * time.Second
+
+// Checkpoint records how far a Follower has read into a log file
+type Checkpoint struct {
+	Path   string `json:"path"`
+	Inode  uint64 `json:"inode"`
+	Offset int64  `json:"offset"`
+}
+
+// LoadCheckpoint reads a checkpoint saved by SaveCheckpoint.
+// A missing file yields a zero checkpoint so following starts from the top.
+func LoadCheckpoint(filename string) (Checkpoint, error) {
+	var cp Checkpoint
+	data, err := os.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return cp, nil
+	}
+	if err != nil {
+		return cp, fmt.Errorf("reading checkpoint: %w", err)
+	}
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return cp, fmt.Errorf("parsing checkpoint: %w", err)
+	}
+	return cp, nil
+}
+
+// SaveCheckpoint writes the checkpoint atomically
+func SaveCheckpoint(filename string, cp Checkpoint) error {
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return err
+	}
+	return fsutil.WriteFileAtomic(filename, data, 0644)
+}
+
+// Follower emits events as they are appended to a JSONL log, like tail -F.
+// It survives truncation and the rename rotate-logs performs when it moves
+// hot.jsonl into the archive.
+type Follower struct {
+	// Events delivers each newly appended event
+	Events <-chan *Event
+	// Errors delivers parse and I/O errors; following continues after them
+	Errors <-chan error
+
+	path    string
+	events  chan *Event
+	errors  chan error
+	done    chan struct{}
+	closed  sync.Once
+	watcher *fsnotify.Watcher
+
+	file    *os.File
+	inode   uint64
+	offset  int64
+	partial []byte
+
+	mu         sync.Mutex
+	checkpoint Checkpoint
+}
+
+// Follow starts following path from the given checkpoint. If the file the
+// checkpoint was taken on has since been rotated into archiveDir, the rest
+// of that archive is read first; a checkpoint for any other file is ignored
+// and reading starts at the beginning of the current file.
+func Follow(path, archiveDir string, from Checkpoint) (*Follower, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("creating watcher: %w", err)
+	}
+	// Watch the directory so renames and re-creation of the file are seen
+	if err := watcher.Add(filepath.Dir(path)); err != nil {
+		watcher.Close()
+		return nil, fmt.Errorf("watching %s: %w", filepath.Dir(path), err)
+	}
+
+	f := &Follower{
+		path:    path,
+		events:  make(chan *Event, 64),
+		errors:  make(chan error, 8),
+		done:    make(chan struct{}),
+		watcher: watcher,
+	}
+	f.Events = f.events
+	f.Errors = f.errors
+
+	if from.Path == path {
+		f.inode = from.Inode
+		f.offset = from.Offset
+		f.file = openRotated(path, archiveDir, from.Inode)
+	}
+	f.publishCheckpoint()
+
+	go f.run()
+	return f, nil
+}
+
+// FollowFromEnd starts following path, skipping everything already written
+func FollowFromEnd(path string) (*Follower, error) {
+	cp := Checkpoint{Path: path}
+	if stat, err := os.Stat(path); err == nil {
+		cp.Inode = fsutil.Inode(stat)
+		cp.Offset = stat.Size()
+	}
+	return Follow(path, "", cp)
+}
+
+// openRotated opens the archive rotate-logs renamed the file with inode to,
+// or returns nil if path still is that file or no archive matches
+func openRotated(path, archiveDir string, inode uint64) *os.File {
+	if archiveDir == "" || inode == 0 {
+		return nil
+	}
+	if stat, err := os.Stat(path); err == nil && fsutil.Inode(stat) == inode {
+		return nil
+	}
+	archives, _ := filepath.Glob(filepath.Join(archiveDir, "*.jsonl"))
+	for _, archive := range archives {
+		stat, err := os.Stat(archive)
+		if err != nil || fsutil.Inode(stat) != inode {
+			continue
+		}
+		file, err := os.Open(archive)
+		if err != nil {
+			return nil
+		}
+		return file
+	}
+	return nil
+}
+
+// Checkpoint returns the position after the last delivered complete line
+func (f *Follower) Checkpoint() Checkpoint {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.checkpoint
+}
+
+// Close stops following and closes the Events channel. Closing again does
+// nothing.
+func (f *Follower) Close() error {
+	var err error
+	f.closed.Do(func() {
+		close(f.done)
+		err = f.watcher.Close()
+	})
+	return err
+}
+
+func (f *Follower) publishCheckpoint() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.checkpoint = Checkpoint{Path: f.path, Inode: f.inode, Offset: f.offset - int64(len(f.partial))}
+}
+
+func (f *Follower) run() {
+	defer close(f.events)
+	defer func() {
+		if f.file != nil {
+			f.file.Close()
+		}
+	}()
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	f.check()
+	for {
+		select {
+		case <-f.done:
+			return
+		case ev, ok := <-f.watcher.Events:
+			if !ok {
+				return
+			}
+			if filepath.Clean(ev.Name) == filepath.Clean(f.path) {
+				f.check()
+			}
+		case err, ok := <-f.watcher.Errors:
+			if !ok {
+				return
+			}
+			f.sendError(err)
+		case <-ticker.C:
+			f.check()
+		}
+	}
+}
+
+// check reconciles the open handle with the file currently at path and
+// reads anything new
+func (f *Follower) check() {
+	stat, err := os.Stat(f.path)
+	if err != nil {
+		// Rotated away and not yet re-created: finish the old handle
+		if f.file != nil {
+			f.drain()
+			f.closeFile()
+		}
+		return
+	}
+
+	inode := fsutil.Inode(stat)
+	if f.file != nil && inode != f.inode {
+		// Rotation: read whatever was appended before the rename, then switch
+		f.drain()
+		f.closeFile()
+		f.inode = inode
+		f.offset = 0
+	}
+
+	if f.file == nil {
+		file, err := os.Open(f.path)
+		if err != nil {
+			f.sendError(fmt.Errorf("opening %s: %w", f.path, err))
+			return
+		}
+		if inode != f.inode {
+			f.offset = 0
+		}
+		f.file = file
+		f.inode = inode
+	}
+
+	if stat.Size() < f.offset {
+		// Truncated in place
+		f.offset = 0
+		f.partial = nil
+	}
+
+	f.drain()
+}
+
+// drain reads from offset to EOF, delivering complete lines
+func (f *Follower) drain() {
+	if _, err := f.file.Seek(f.offset, io.SeekStart); err != nil {
+		f.sendError(fmt.Errorf("seeking: %w", err))
+		return
+	}
+
+	reader := bufio.NewReader(f.file)
+	for {
+		chunk, err := reader.ReadBytes('\n')
+		f.offset += int64(len(chunk))
+		if len(chunk) > 0 {
+			f.partial = append(f.partial, chunk...)
+		}
+		if err != nil {
+			if err != io.EOF {
+				f.sendError(fmt.Errorf("reading: %w", err))
+			}
+			break
+		}
+
+		line := bytes.TrimSpace(f.partial)
+		f.partial = nil
+		if len(line) == 0 {
+			continue
+		}
+		event, perr := Parse(line)
+		if perr != nil {
+			f.sendError(fmt.Errorf("parsing JSON: %w", perr))
+			continue
+		}
+		select {
+		case f.events <- event:
+		case <-f.done:
+			return
+		}
+	}
+
+	f.publishCheckpoint()
+}
+
+func (f *Follower) closeFile() {
+	f.file.Close()
+	f.file = nil
+	f.partial = nil
+}
+
+func (f *Follower) sendError(err error) {
+	select {
+	case f.errors <- err:
+	default:
+		// Nobody is reading errors; drop rather than block following
+	}
+}
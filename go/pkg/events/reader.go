@@ -2,24 +2,38 @@ package events
 
 import (
 	"bufio"
-	"encoding/json"
+	"bytes"
+	"errors"
 	"fmt"
+	"io"
 	"os"
-	"time"
+	"sort"
 )
 
-// Event represents a knowledge system event
-type Event struct {
-	Timestamp string `json:"timestamp"`
-	Type      string `json:"type"`
-	Content   string `json:"content"`
-	Tags      string `json:"tags,omitempty"`
+// maxLineSize bounds a single JSONL line; long-form content can exceed
+// bufio.Scanner's 64KB default
+const maxLineSize = 1024 * 1024
+
+// ParseError reports a line that is not a valid event. Reading can
+// continue past it with another call to Next.
+type ParseError struct {
+	Line int
+	Err  error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("parsing JSON on line %d: %v", e.Line, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
 }
 
 // Reader reads events from JSONL files
 type Reader struct {
 	file    *os.File
 	scanner *bufio.Scanner
+	line    int
 }
 
 // NewReader creates a new event reader for the given file
@@ -29,9 +43,12 @@ func NewReader(filename string) (*Reader, error) {
 		return nil, fmt.Errorf("opening file: %w", err)
 	}
 
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+
 	return &Reader{
 		file:    file,
-		scanner: bufio.NewScanner(file),
+		scanner: scanner,
 	}, nil
 }
 
@@ -42,19 +59,24 @@ func (r *Reader) Close() error {
 
 // Next reads the next event from the file
 func (r *Reader) Next() (*Event, error) {
-	if !r.scanner.Scan() {
-		if err := r.scanner.Err(); err != nil {
-			return nil, fmt.Errorf("scanning: %w", err)
+	var line []byte
+	for len(line) == 0 {
+		if !r.scanner.Scan() {
+			if err := r.scanner.Err(); err != nil {
+				return nil, fmt.Errorf("scanning: %w", err)
+			}
+			return nil, nil // EOF
 		}
-		return nil, nil // EOF
+		r.line++
+		line = bytes.TrimSpace(r.scanner.Bytes())
 	}
 
-	var event Event
-	if err := json.Unmarshal(r.scanner.Bytes(), &event); err != nil {
-		return nil, fmt.Errorf("parsing JSON: %w", err)
+	event, err := Parse(line)
+	if err != nil {
+		return nil, &ParseError{Line: r.line, Err: err}
 	}
 
-	return &event, nil
+	return event, nil
 }
 
 // ReadAll reads all events from a file
@@ -80,12 +102,71 @@ func ReadAll(filename string) ([]*Event, error) {
 	return events, nil
 }
 
-// FormatTime formats the event timestamp for display
-func (e *Event) FormatTime() string {
-	// Try parsing the timestamp
-	t, err := time.Parse("2006-01-02T15:04:05Z07:00", e.Timestamp)
+// CountLines counts newline-terminated lines the way `wc -l` does, which is
+// how ks_count_new_events counts events. A missing file has zero events.
+func CountLines(filename string) (int, error) {
+	file, err := os.Open(filename)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
 	if err != nil {
-		return e.Timestamp
+		return 0, fmt.Errorf("opening file: %w", err)
+	}
+	defer file.Close()
+
+	count := 0
+	buf := make([]byte, 64*1024)
+	for {
+		n, err := file.Read(buf)
+		count += bytes.Count(buf[:n], []byte{'\n'})
+		if err == io.EOF {
+			return count, nil
+		}
+		if err != nil {
+			return count, fmt.Errorf("reading: %w", err)
+		}
 	}
-	return t.Format("2006-01-02 15:04")
-}
\ No newline at end of file
+}
+
+// Topics lists the topics used in a file, most frequent first. Unparseable
+// lines are skipped and a missing file has no topics.
+func Topics(filename string) ([]string, error) {
+	reader, err := NewReader(filename)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	counts := map[string]int{}
+	for {
+		event, err := reader.Next()
+		var parseErr *ParseError
+		if errors.As(err, &parseErr) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if event == nil {
+			break
+		}
+		if event.Topic != "" {
+			counts[event.Topic]++
+		}
+	}
+
+	topics := make([]string, 0, len(counts))
+	for topic := range counts {
+		topics = append(topics, topic)
+	}
+	sort.Slice(topics, func(i, j int) bool {
+		if counts[topics[i]] != counts[topics[j]] {
+			return counts[topics[i]] > counts[topics[j]]
+		}
+		return topics[i] < topics[j]
+	})
+	return topics, nil
+}
@@ -0,0 +1,110 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/durapensa/ks/pkg/fsutil"
+)
+
+// ValidTypes matches the event types accepted by ks_validate_event_type
+var ValidTypes = []string{"thought", "connection", "question", "insight", "process"}
+
+// claudePrefix marks content that belongs in the derived stream
+const claudePrefix = "[Claude]"
+
+// ValidateType reports whether eventType is one of ValidTypes
+func ValidateType(eventType string) error {
+	for _, valid := range ValidTypes {
+		if eventType == valid {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid event type '%s' (valid types: %s)", eventType, strings.Join(ValidTypes, ", "))
+}
+
+// Writer appends events to the hot log the same way tools/capture/events does
+type Writer struct {
+	hotLog     string
+	derivedDir string
+}
+
+// NewWriter creates a writer for the given hot log and derived directory
+func NewWriter(hotLog, derivedDir string) *Writer {
+	return &Writer{
+		hotLog:     hotLog,
+		derivedDir: derivedDir,
+	}
+}
+
+// Append builds an event from its parts and writes it
+func (w *Writer) Append(eventType, topic, content string) (*Event, string, error) {
+	event := &Event{
+		Type:    eventType,
+		Topic:   topic,
+		Content: content,
+	}
+	path, err := w.Write(event)
+	if err != nil {
+		return nil, "", err
+	}
+	return event, path, nil
+}
+
+// Write validates the event and appends it as one JSONL line under flock.
+// Content starting with "[Claude]" is routed to derived/stream.jsonl with
+// the prefix stripped. Returns the path of the log that was written.
+func (w *Writer) Write(event *Event) (string, error) {
+	if err := ValidateType(event.Type); err != nil {
+		return "", err
+	}
+
+	if event.Timestamp == "" {
+		event.Timestamp = time.Now().UTC().Format("2006-01-02T15:04:05Z")
+	}
+	event.Schema = SchemaCurrent
+
+	target := w.hotLog
+	if strings.HasPrefix(event.Content, claudePrefix) {
+		target = filepath.Join(w.derivedDir, "stream.jsonl")
+		event.Content = strings.TrimPrefix(event.Content, claudePrefix+" ")
+		if event.Metadata == nil {
+			event.Metadata = map[string]interface{}{}
+		}
+		event.Metadata["source"] = "claude-conversation"
+	}
+
+	line, err := json.Marshal(event)
+	if err != nil {
+		return "", fmt.Errorf("encoding event: %w", err)
+	}
+	line = append(line, '\n')
+
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return "", fmt.Errorf("creating log directory: %w", err)
+	}
+
+	unlock, err := fsutil.Lock(target)
+	if err != nil {
+		return "", err
+	}
+	defer unlock()
+
+	file, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return "", fmt.Errorf("opening log: %w", err)
+	}
+	defer file.Close()
+
+	// A single write keeps the line whole even for readers that don't lock
+	if _, err := file.Write(line); err != nil {
+		return "", fmt.Errorf("writing event: %w", err)
+	}
+
+	event.Raw = strings.TrimSuffix(string(line), "\n")
+	return target, nil
+}
@@ -0,0 +1,16 @@
+package fsutil
+
+import (
+	"os"
+	"syscall"
+)
+
+// Inode returns the inode number behind a FileInfo, or 0 if unavailable.
+// Comparing inodes is how rotation (rename + re-create) is told apart from
+// appends to the same file.
+func Inode(stat os.FileInfo) uint64 {
+	if sys, ok := stat.Sys().(*syscall.Stat_t); ok {
+		return uint64(sys.Ino)
+	}
+	return 0
+}
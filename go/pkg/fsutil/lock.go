@@ -0,0 +1,35 @@
+package fsutil
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// LockPath is the lock file guarding path, the one ks_lock_file in
+// lib/core.sh names for the bash tools. It is never removed: flock locks
+// belong to the inode, so a recreated file would not exclude a writer still
+// holding the old one.
+func LockPath(path string) string {
+	return path + ".lock"
+}
+
+// Lock takes an exclusive flock on LockPath(path), creating it if needed.
+// The bash tools take the same lock with `flock`, so Go and bash writers
+// exclude each other. The returned function releases the lock.
+func Lock(path string) (func() error, error) {
+	file, err := os.OpenFile(LockPath(path), os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("opening lock file: %w", err)
+	}
+
+	if err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("acquiring lock: %w", err)
+	}
+
+	return func() error {
+		defer file.Close()
+		return syscall.Flock(int(file.Fd()), syscall.LOCK_UN)
+	}, nil
+}
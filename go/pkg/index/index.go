@@ -0,0 +1,430 @@
+package index
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/durapensa/ks/pkg/events"
+	"github.com/durapensa/ks/pkg/fsutil"
+)
+
+// FileName is the index file kept under KS_BACKGROUND_DIR
+const FileName = "search-index.gob"
+
+// BM25 parameters
+const (
+	k1 = 1.2
+	b  = 0.75
+)
+
+// fieldGap separates event fields so phrases can't match across them
+const fieldGap = 100
+
+// Doc locates one indexed event
+type Doc struct {
+	File   string
+	Offset int64
+	Length int // Token count, for BM25 length normalization
+}
+
+// Posting lists where a term occurs in one document
+type Posting struct {
+	Doc       int
+	Positions []int
+}
+
+// FileState records how much of a log has been indexed
+type FileState struct {
+	Inode  uint64
+	Offset int64
+}
+
+// Hit is a ranked search result
+type Hit struct {
+	Doc   Doc
+	Score float64
+}
+
+// Index is an on-disk inverted index over event logs. Updates happen in
+// memory; Save writes them out.
+type Index struct {
+	path  string
+	mu    sync.Mutex
+	dirty bool // Updated since the last save
+
+	Docs        []Doc
+	Postings    map[string][]Posting
+	Files       map[string]FileState
+	TotalLength int
+}
+
+// Open loads the index at path. A missing index starts empty. An
+// unreadable one is reported, but the index returned with the error is
+// still usable: it starts empty too and is rebuilt by the next Update.
+func Open(path string) (*Index, error) {
+	idx := &Index{path: path}
+	idx.reset()
+
+	if err := idx.load(); err != nil && !os.IsNotExist(err) {
+		idx.reset()
+		return idx, fmt.Errorf("reading search index %s: %w", path, err)
+	}
+	return idx, nil
+}
+
+func (idx *Index) reset() {
+	idx.Docs = nil
+	idx.Postings = map[string][]Posting{}
+	idx.Files = map[string]FileState{}
+	idx.TotalLength = 0
+}
+
+func (idx *Index) load() error {
+	file, err := os.Open(idx.path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	idx.reset()
+	return gob.NewDecoder(bufio.NewReader(file)).Decode(idx)
+}
+
+func (idx *Index) save() error {
+	if err := os.MkdirAll(filepath.Dir(idx.path), 0755); err != nil {
+		return fmt.Errorf("creating index directory: %w", err)
+	}
+
+	tmp := idx.path + ".tmp"
+	file, err := os.Create(tmp)
+	if err != nil {
+		return fmt.Errorf("creating index: %w", err)
+	}
+
+	writer := bufio.NewWriter(file)
+	if err := gob.NewEncoder(writer).Encode(idx); err != nil {
+		file.Close()
+		os.Remove(tmp)
+		return fmt.Errorf("encoding index: %w", err)
+	}
+	if err := writer.Flush(); err != nil {
+		file.Close()
+		os.Remove(tmp)
+		return fmt.Errorf("writing index: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, idx.path)
+}
+
+// Update brings the index in memory in line with files, reading only what
+// was appended since the last update. If a known file was rotated away,
+// replaced or truncated the whole index is rebuilt.
+func (idx *Index) Update(files []string) error {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	return idx.update(files)
+}
+
+func (idx *Index) update(files []string) error {
+	current := map[string]os.FileInfo{}
+	for _, file := range files {
+		if stat, err := os.Stat(file); err == nil {
+			current[file] = stat
+		}
+	}
+
+	if idx.stale(current) {
+		idx.reset()
+		idx.dirty = true
+	}
+
+	for _, file := range files {
+		stat, ok := current[file]
+		if !ok {
+			continue
+		}
+		state := idx.Files[file]
+		if state.Inode == fsutil.Inode(stat) && state.Offset == stat.Size() {
+			continue
+		}
+		offset, err := idx.indexFile(file, state.Offset)
+		if err != nil {
+			return err
+		}
+		idx.Files[file] = FileState{Inode: fsutil.Inode(stat), Offset: offset}
+		idx.dirty = true
+	}
+	return nil
+}
+
+// Save writes the index if it changed since it was loaded or last saved.
+// Every writer indexes the same logs, so the last save wins.
+func (idx *Index) Save() error {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	if !idx.dirty {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(idx.path), 0755); err != nil {
+		return fmt.Errorf("creating index directory: %w", err)
+	}
+	unlock, err := fsutil.Lock(idx.path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if err := idx.save(); err != nil {
+		return err
+	}
+	idx.dirty = false
+	return nil
+}
+
+// Rebuild discards the index, re-indexes files from scratch and saves it
+func (idx *Index) Rebuild(files []string) error {
+	idx.mu.Lock()
+	idx.reset()
+	err := idx.update(files)
+	idx.dirty = true
+	idx.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	return idx.Save()
+}
+
+// stale reports whether any indexed file no longer matches what's on disk
+func (idx *Index) stale(current map[string]os.FileInfo) bool {
+	for file, state := range idx.Files {
+		stat, ok := current[file]
+		if !ok || fsutil.Inode(stat) != state.Inode || stat.Size() < state.Offset {
+			return true
+		}
+	}
+	return false
+}
+
+// indexFile indexes complete lines from offset and returns the new offset
+func (idx *Index) indexFile(path string, offset int64) (int64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return offset, fmt.Errorf("opening %s: %w", path, err)
+	}
+	defer file.Close()
+
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		return offset, fmt.Errorf("seeking %s: %w", path, err)
+	}
+
+	reader := bufio.NewReader(file)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			// Partial trailing line: pick it up once it's complete
+			return offset, nil
+		}
+		if err != nil {
+			return offset, fmt.Errorf("reading %s: %w", path, err)
+		}
+
+		if trimmed := bytes.TrimSpace(line); len(trimmed) > 0 {
+			if event, perr := events.Parse(trimmed); perr == nil {
+				idx.add(Doc{File: path, Offset: offset}, event)
+			}
+		}
+		offset += int64(len(line))
+	}
+}
+
+// add indexes one event's searchable fields
+func (idx *Index) add(doc Doc, event *events.Event) {
+	fields := []string{event.Content, event.Topic, event.Type, event.Context, strings.Join(event.Tags, " ")}
+
+	positions := map[string][]int{}
+	base := 0
+	for _, field := range fields {
+		tokens := Tokenize(field)
+		for _, token := range tokens {
+			positions[token.Term] = append(positions[token.Term], base+token.Pos)
+		}
+		doc.Length += len(tokens)
+		if len(tokens) > 0 {
+			base += tokens[len(tokens)-1].Pos + fieldGap
+		}
+	}
+
+	id := len(idx.Docs)
+	idx.Docs = append(idx.Docs, doc)
+	idx.TotalLength += doc.Length
+	for term, pos := range positions {
+		idx.Postings[term] = append(idx.Postings[term], Posting{Doc: id, Positions: pos})
+	}
+}
+
+// parseQuery splits a query into free terms and quoted phrases
+func parseQuery(q string) (terms []string, phrases [][]Token) {
+	parts := strings.Split(q, `"`)
+	for i, part := range parts {
+		tokens := Tokenize(part)
+		if i%2 == 1 && len(tokens) > 1 {
+			phrases = append(phrases, tokens)
+		}
+		for _, token := range tokens {
+			terms = append(terms, token.Term)
+		}
+	}
+	return terms, phrases
+}
+
+// Search ranks documents against q with BM25. Words match any document
+// containing them; "quoted phrases" must appear verbatim (after stemming).
+// A limit of 0 or less returns every hit.
+func (idx *Index) Search(q string, limit int) []Hit {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	terms, phrases := parseQuery(q)
+	if len(terms) == 0 || len(idx.Docs) == 0 {
+		return nil
+	}
+
+	n := float64(len(idx.Docs))
+	avgLength := float64(idx.TotalLength) / n
+	scores := map[int]float64{}
+
+	seen := map[string]bool{}
+	for _, term := range terms {
+		if seen[term] {
+			continue
+		}
+		seen[term] = true
+
+		postings := idx.Postings[term]
+		df := float64(len(postings))
+		if df == 0 {
+			continue
+		}
+		idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+		for _, p := range postings {
+			tf := float64(len(p.Positions))
+			length := float64(idx.Docs[p.Doc].Length)
+			scores[p.Doc] += idf * tf * (k1 + 1) / (tf + k1*(1-b+b*length/avgLength))
+		}
+	}
+
+	var hits []Hit
+	for id, score := range scores {
+		if !idx.containsPhrases(id, phrases) {
+			continue
+		}
+		hits = append(hits, Hit{Doc: idx.Docs[id], Score: score})
+	}
+
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		// Ties go to the more recently appended event
+		if hits[i].Doc.File != hits[j].Doc.File {
+			return hits[i].Doc.File > hits[j].Doc.File
+		}
+		return hits[i].Doc.Offset > hits[j].Doc.Offset
+	})
+
+	if limit > 0 && len(hits) > limit {
+		hits = hits[:limit]
+	}
+	return hits
+}
+
+// containsPhrases checks that every phrase occurs in the document with the
+// same relative token positions as in the query
+func (idx *Index) containsPhrases(id int, phrases [][]Token) bool {
+	for _, phrase := range phrases {
+		starts := idx.positions(phrase[0].Term, id)
+		found := false
+		for _, start := range starts {
+			match := true
+			for _, token := range phrase[1:] {
+				if !containsInt(idx.positions(token.Term, id), start+token.Pos-phrase[0].Pos) {
+					match = false
+					break
+				}
+			}
+			if match {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func (idx *Index) positions(term string, id int) []int {
+	postings := idx.Postings[term]
+	i := sort.Search(len(postings), func(i int) bool { return postings[i].Doc >= id })
+	if i < len(postings) && postings[i].Doc == id {
+		return postings[i].Positions
+	}
+	return nil
+}
+
+func containsInt(list []int, v int) bool {
+	for _, x := range list {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
+
+// Events reads the events docs point at, opening each file once. Events
+// that can't be read are nil.
+func Events(docs []Doc) []*events.Event {
+	result := make([]*events.Event, len(docs))
+	byFile := map[string][]int{}
+	for i, d := range docs {
+		byFile[d.File] = append(byFile[d.File], i)
+	}
+	for path, indexes := range byFile {
+		file, err := os.Open(path)
+		if err != nil {
+			continue
+		}
+		// In file order, so reads move forward
+		sort.Slice(indexes, func(i, j int) bool { return docs[indexes[i]].Offset < docs[indexes[j]].Offset })
+		reader := bufio.NewReader(file)
+		for _, i := range indexes {
+			if _, err := file.Seek(docs[i].Offset, io.SeekStart); err != nil {
+				break
+			}
+			reader.Reset(file)
+			line, err := reader.ReadBytes('\n')
+			if err != nil && err != io.EOF {
+				break
+			}
+			if event, err := events.Parse(bytes.TrimSpace(line)); err == nil {
+				result[i] = event
+			}
+		}
+		file.Close()
+	}
+	return result
+}
@@ -0,0 +1,39 @@
+package index
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Token is a stemmed term and its position in the source text. Positions
+// count stop words too, so phrase adjacency survives their removal.
+type Token struct {
+	Term string
+	Pos  int
+}
+
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true,
+	"be": true, "but": true, "by": true, "for": true, "if": true, "in": true,
+	"into": true, "is": true, "it": true, "no": true, "not": true, "of": true,
+	"on": true, "or": true, "such": true, "that": true, "the": true,
+	"their": true, "then": true, "there": true, "these": true, "they": true,
+	"this": true, "to": true, "was": true, "will": true, "with": true,
+}
+
+// Tokenize lowercases text, splits it on anything that isn't a letter or
+// digit, drops stop words and stems what remains
+func Tokenize(text string) []Token {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	tokens := make([]Token, 0, len(words))
+	for pos, word := range words {
+		if stopWords[word] {
+			continue
+		}
+		tokens = append(tokens, Token{Term: Stem(word), Pos: pos})
+	}
+	return tokens
+}
@@ -0,0 +1,110 @@
+package kg
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// Alias is a row of the aliases table, a variant spelling of a concept
+type Alias struct {
+	CanonicalID string
+	Alias       string
+	Source      string // Where the variant was seen, e.g. "human" or "ai"
+	Count       int    // Usage frequency
+}
+
+const aliasColumns = `canonical_id, alias, COALESCE(source, ''), COALESCE(count, 1)`
+
+func scanAlias(row scanner) (*Alias, error) {
+	var a Alias
+	if err := row.Scan(&a.CanonicalID, &a.Alias, &a.Source, &a.Count); err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// PutAlias inserts a or replaces the alias with the same concept and text
+func (d *DB) PutAlias(a *Alias) error {
+	if a.Count == 0 {
+		a.Count = 1
+	}
+	return putAlias(d.db, a)
+}
+
+func putAlias(db execer, a *Alias) error {
+	_, err := db.Exec(`
+		INSERT OR REPLACE INTO aliases (canonical_id, alias, source, count)
+		VALUES (?, ?, NULLIF(?, ''), ?)`,
+		a.CanonicalID, a.Alias, a.Source, a.Count)
+	if err != nil {
+		return fmt.Errorf("saving alias %q: %w", a.Alias, err)
+	}
+	return nil
+}
+
+// AddAliasUse counts another sighting of an alias, creating it if needed
+func (d *DB) AddAliasUse(canonicalID, alias, source string) error {
+	_, err := d.db.Exec(`
+		INSERT INTO aliases (canonical_id, alias, source, count) VALUES (?, ?, NULLIF(?, ''), 1)
+		ON CONFLICT (canonical_id, alias) DO UPDATE SET count = COALESCE(count, 1) + 1`,
+		canonicalID, alias, source)
+	if err != nil {
+		return fmt.Errorf("counting alias %q: %w", alias, err)
+	}
+	return nil
+}
+
+// Alias reads one alias
+func (d *DB) Alias(canonicalID, alias string) (*Alias, error) {
+	a, err := scanAlias(d.db.QueryRow(`
+		SELECT `+aliasColumns+` FROM aliases WHERE canonical_id = ? AND alias = ?`,
+		canonicalID, alias))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("alias %q: %w", alias, ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading alias %q: %w", alias, err)
+	}
+	return a, nil
+}
+
+// Aliases lists a concept's aliases by descending count; an empty ID
+// lists every alias
+func (d *DB) Aliases(canonicalID string) ([]*Alias, error) {
+	query := `SELECT ` + aliasColumns + ` FROM aliases`
+	var args []interface{}
+	if canonicalID != "" {
+		query += ` WHERE canonical_id = ?`
+		args = append(args, canonicalID)
+	}
+	query += ` ORDER BY count DESC, alias`
+
+	rows, err := d.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("listing aliases: %w", err)
+	}
+	defer rows.Close()
+
+	var aliases []*Alias
+	for rows.Next() {
+		a, err := scanAlias(rows)
+		if err != nil {
+			return nil, fmt.Errorf("listing aliases: %w", err)
+		}
+		aliases = append(aliases, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("listing aliases: %w", err)
+	}
+	return aliases, nil
+}
+
+// DeleteAlias removes one alias
+func (d *DB) DeleteAlias(canonicalID, alias string) error {
+	err := checkAffected(d.db.Exec(`DELETE FROM aliases WHERE canonical_id = ? AND alias = ?`, canonicalID, alias))
+	if err != nil {
+		return fmt.Errorf("deleting alias %q: %w", alias, err)
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package kg
+
+import "math"
+
+// PageRank settings
+const (
+	pageRankDamping    = 0.85
+	pageRankTolerance  = 1e-9
+	pageRankIterations = 100
+)
+
+// PageRank ranks concepts by how much strength flows into them, highest
+// first. Scores sum to 1; concepts without edges share what dangling nodes
+// give away.
+func (g *Graph) PageRank() []Score {
+	n := len(g.Concepts)
+	if n == 0 {
+		return nil
+	}
+
+	outWeight := make([]float64, n)
+	for i := range g.Concepts {
+		for _, a := range g.neighbours(i) {
+			outWeight[i] += a.edge.Strength
+		}
+	}
+
+	rank := make([]float64, n)
+	for i := range rank {
+		rank[i] = 1 / float64(n)
+	}
+	next := make([]float64, n)
+	for iter := 0; iter < pageRankIterations; iter++ {
+		dangling := 0.0
+		for i, w := range outWeight {
+			if w <= 0 {
+				dangling += rank[i]
+			}
+		}
+		base := (1-pageRankDamping)/float64(n) + pageRankDamping*dangling/float64(n)
+		for i := range next {
+			next[i] = base
+		}
+		for i := range g.Concepts {
+			if outWeight[i] <= 0 {
+				continue
+			}
+			for _, a := range g.neighbours(i) {
+				next[a.to] += pageRankDamping * rank[i] * a.edge.Strength / outWeight[i]
+			}
+		}
+
+		delta := 0.0
+		for i := range rank {
+			delta += math.Abs(next[i] - rank[i])
+		}
+		rank, next = next, rank
+		if delta < pageRankTolerance {
+			break
+		}
+	}
+	return g.scores(rank)
+}
+
+// Betweenness ranks concepts by the share of shortest paths between other
+// concepts that pass through them, highest first. Paths count edges, not
+// strength, and scores are normalised to 0-1.
+func (g *Graph) Betweenness() []Score {
+	n := len(g.Concepts)
+	if n == 0 {
+		return nil
+	}
+
+	// Parallel edges of different types are one step
+	adjacent := make([][]int, n)
+	for i := range g.Concepts {
+		seen := map[int]bool{i: true}
+		for _, a := range g.neighbours(i) {
+			if !seen[a.to] {
+				seen[a.to] = true
+				adjacent[i] = append(adjacent[i], a.to)
+			}
+		}
+	}
+
+	// Brandes' algorithm, one breadth-first search per source
+	centrality := make([]float64, n)
+	sigma := make([]float64, n)
+	dist := make([]int, n)
+	delta := make([]float64, n)
+	preds := make([][]int, n)
+	for s := 0; s < n; s++ {
+		for i := range sigma {
+			sigma[i], dist[i], delta[i], preds[i] = 0, -1, 0, preds[i][:0]
+		}
+		sigma[s], dist[s] = 1, 0
+		order := []int{s}
+		for q := 0; q < len(order); q++ {
+			v := order[q]
+			for _, w := range adjacent[v] {
+				if dist[w] < 0 {
+					dist[w] = dist[v] + 1
+					order = append(order, w)
+				}
+				if dist[w] == dist[v]+1 {
+					sigma[w] += sigma[v]
+					preds[w] = append(preds[w], v)
+				}
+			}
+		}
+		for q := len(order) - 1; q > 0; q-- {
+			w := order[q]
+			for _, v := range preds[w] {
+				delta[v] += sigma[v] / sigma[w] * (1 + delta[w])
+			}
+			centrality[w] += delta[w]
+		}
+	}
+
+	// Undirected searches count each pair from both ends, which the
+	// ordered-pair normalisation cancels out
+	if n > 2 {
+		scale := 1 / float64((n-1)*(n-2))
+		for i := range centrality {
+			centrality[i] *= scale
+		}
+	}
+	return g.scores(centrality)
+}
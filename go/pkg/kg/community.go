@@ -0,0 +1,158 @@
+package kg
+
+// louvainPasses bounds the node-moving passes of one Louvain level
+const louvainPasses = 100
+
+// weighted is an undirected graph with summed edge weights. adj[i][j] holds
+// the weight between i and j in both rows, and a self-loop twice, so a
+// node's degree is the sum of its row.
+type weighted struct {
+	adj []map[int]float64
+}
+
+// Communities finds densely connected groups of concepts with the Louvain
+// method, weighting edges by strength and ignoring direction. It returns
+// the groups, largest first, and their modularity.
+func (g *Graph) Communities() ([][]*Concept, float64) {
+	n := len(g.Concepts)
+	w := weighted{adj: make([]map[int]float64, n)}
+	for i := range w.adj {
+		w.adj[i] = map[int]float64{}
+	}
+	for _, e := range g.Edges {
+		s, t := g.index[e.SourceID], g.index[e.TargetID]
+		w.adj[s][t] += e.Strength
+		w.adj[t][s] += e.Strength
+	}
+
+	// membership maps each concept to its community at the current level
+	membership := make([]int, n)
+	for i := range membership {
+		membership[i] = i
+	}
+	for {
+		community, moved := w.moveNodes()
+		if !moved {
+			break
+		}
+		for i, c := range membership {
+			membership[i] = community[c]
+		}
+		w = w.aggregate(community)
+	}
+
+	sets := map[int][]int{}
+	for i, c := range membership {
+		sets[c] = append(sets[c], i)
+	}
+	return g.groups(sets), modularity(g, membership)
+}
+
+// moveNodes is the first Louvain phase: nodes join the neighbouring
+// community that most improves modularity until none moves. Communities
+// are renumbered from 0; false means no two nodes were merged.
+func (w weighted) moveNodes() ([]int, bool) {
+	n := len(w.adj)
+	community := make([]int, n)
+	degree := make([]float64, n)
+	total := make([]float64, n) // Sum of degrees in each community
+	m2 := 0.0
+	for i, row := range w.adj {
+		community[i] = i
+		for _, weight := range row {
+			degree[i] += weight
+		}
+		total[i] = degree[i]
+		m2 += degree[i]
+	}
+	if m2 == 0 {
+		return community, false
+	}
+
+	for pass := 0; pass < louvainPasses; pass++ {
+		changed := false
+		for i := 0; i < n; i++ {
+			// Weight from i into each neighbouring community
+			links := map[int]float64{}
+			for j, weight := range w.adj[i] {
+				if j != i {
+					links[community[j]] += weight
+				}
+			}
+
+			current := community[i]
+			total[current] -= degree[i]
+			best, bestGain := current, links[current]-total[current]*degree[i]/m2
+			for c, weight := range links {
+				gain := weight - total[c]*degree[i]/m2
+				// Ties go to the lower number so results don't depend on
+				// map order
+				if gain > bestGain || (gain == bestGain && c < best) {
+					best, bestGain = c, gain
+				}
+			}
+			total[best] += degree[i]
+			if best != current {
+				community[i] = best
+				changed = true
+			}
+		}
+		if !changed {
+			break
+		}
+	}
+
+	renumber := map[int]int{}
+	for i, c := range community {
+		if _, ok := renumber[c]; !ok {
+			renumber[c] = len(renumber)
+		}
+		community[i] = renumber[c]
+	}
+	// Nodes can move and move back, so only fewer communities is progress
+	return community, len(renumber) < n
+}
+
+// aggregate is the second Louvain phase: each community becomes a node and
+// the weight inside it a self-loop
+func (w weighted) aggregate(community []int) weighted {
+	size := 0
+	for _, c := range community {
+		size = max(size, c+1)
+	}
+	next := weighted{adj: make([]map[int]float64, size)}
+	for i := range next.adj {
+		next.adj[i] = map[int]float64{}
+	}
+	for i, row := range w.adj {
+		for j, weight := range row {
+			next.adj[community[i]][community[j]] += weight
+		}
+	}
+	return next
+}
+
+// modularity scores a division of the graph: the share of edge weight
+// inside communities minus what random wiring would put there
+func modularity(g *Graph, membership []int) float64 {
+	inside := map[int]float64{}
+	total := map[int]float64{}
+	m2 := 0.0
+	for _, e := range g.Edges {
+		s, t := membership[g.index[e.SourceID]], membership[g.index[e.TargetID]]
+		total[s] += e.Strength
+		total[t] += e.Strength
+		m2 += 2 * e.Strength
+		if s == t {
+			inside[s] += 2 * e.Strength
+		}
+	}
+	if m2 == 0 {
+		return 0
+	}
+	q := 0.0
+	for c, t := range total {
+		q += inside[c]/m2 - (t/m2)*(t/m2)
+	}
+	return q
+}
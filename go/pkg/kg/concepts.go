@@ -0,0 +1,200 @@
+package kg
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Concept is a row of the concepts table
+type Concept struct {
+	ID          string
+	Name        string // Canonical form
+	Weight      float64
+	HumanWeight float64 // Human contribution, 0-1
+	AIWeight    float64 // AI contribution, 0-1
+	Created     time.Time
+	Updated     time.Time
+}
+
+const conceptColumns = `id, name, COALESCE(weight, 1.0), COALESCE(human_weight, 0), COALESCE(ai_weight, 0), created, updated`
+
+// ConceptFilter selects concepts for Concepts
+type ConceptFilter struct {
+	MinWeight float64
+	Name      string // Substring of the name, case-insensitive
+	Limit     int    // 0 means no limit
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanConcept(row scanner) (*Concept, error) {
+	var c Concept
+	var created, updated string
+	if err := row.Scan(&c.ID, &c.Name, &c.Weight, &c.HumanWeight, &c.AIWeight, &created, &updated); err != nil {
+		return nil, err
+	}
+	c.Created, c.Updated = parseTime(created), parseTime(updated)
+	return &c, nil
+}
+
+// PutConcept inserts c or replaces the concept with its ID, as
+// run-distillation does. An empty ID is resolved from the name with
+// ResolveName and zero timestamps are set to now.
+func (d *DB) PutConcept(c *Concept) error {
+	if c.ID == "" {
+		id, name, err := d.ResolveName(c.Name)
+		if err != nil {
+			return err
+		}
+		c.ID, c.Name = id, name
+	}
+	now := time.Now().UTC().Truncate(time.Second)
+	if c.Created.IsZero() {
+		c.Created = now
+	}
+	if c.Updated.IsZero() {
+		c.Updated = now
+	}
+	return putConcept(d.db, c)
+}
+
+func putConcept(db execer, c *Concept) error {
+	_, err := db.Exec(`
+		INSERT OR REPLACE INTO concepts (id, name, weight, human_weight, ai_weight, created, updated)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		c.ID, c.Name, c.Weight, c.HumanWeight, c.AIWeight, formatTime(c.Created), formatTime(c.Updated))
+	if err != nil {
+		return fmt.Errorf("saving concept %s: %w", c.Name, err)
+	}
+	return nil
+}
+
+// UpdateConcept changes an existing concept and stamps it updated now
+func (d *DB) UpdateConcept(c *Concept) error {
+	c.Updated = time.Now().UTC().Truncate(time.Second)
+	err := checkAffected(d.db.Exec(`
+		UPDATE concepts SET name = ?, weight = ?, human_weight = ?, ai_weight = ?, updated = ?
+		WHERE id = ?`,
+		c.Name, c.Weight, c.HumanWeight, c.AIWeight, formatTime(c.Updated), c.ID))
+	if err != nil {
+		return fmt.Errorf("updating concept %s: %w", c.ID, err)
+	}
+	return nil
+}
+
+// Concept reads one concept by ID
+func (d *DB) Concept(id string) (*Concept, error) {
+	c, err := scanConcept(d.db.QueryRow(`SELECT `+conceptColumns+` FROM concepts WHERE id = ?`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("concept %s: %w", id, ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading concept %s: %w", id, err)
+	}
+	return c, nil
+}
+
+// ResolveName picks the concept a distilled name belongs to, as
+// run-distillation does: the concept with the name's ID if there is one,
+// else the concept it is an alias of, so that a concept merged away isn't
+// recreated, else a new concept with the name's ID. It returns the ID and
+// the canonical name.
+func (d *DB) ResolveName(name string) (string, string, error) {
+	id := ConceptID(name)
+	var canonical string
+	err := d.db.QueryRow(`SELECT id, name FROM concepts WHERE id = ?`, id).Scan(&id, &canonical)
+	if err == nil {
+		return id, canonical, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return "", "", fmt.Errorf("resolving concept %q: %w", name, err)
+	}
+	err = d.db.QueryRow(`
+		SELECT c.id, c.name FROM aliases a JOIN concepts c ON c.id = a.canonical_id
+		WHERE a.alias = ? COLLATE NOCASE
+		ORDER BY a.source = ? DESC, a.count DESC
+		LIMIT 1`, name, MergeSource).Scan(&id, &canonical)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ConceptID(name), name, nil
+	}
+	if err != nil {
+		return "", "", fmt.Errorf("resolving concept %q: %w", name, err)
+	}
+	return id, canonical, nil
+}
+
+// ConceptByName finds a concept by its canonical name or one of its
+// aliases, ignoring case
+func (d *DB) ConceptByName(name string) (*Concept, error) {
+	c, err := scanConcept(d.db.QueryRow(`
+		SELECT `+conceptColumns+` FROM concepts WHERE name = ? COLLATE NOCASE
+		UNION ALL
+		SELECT `+conceptColumns+` FROM concepts WHERE id IN (
+			SELECT canonical_id FROM aliases WHERE alias = ? COLLATE NOCASE)
+		LIMIT 1`, name, name))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("concept %q: %w", name, ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading concept %q: %w", name, err)
+	}
+	return c, nil
+}
+
+// Concepts lists concepts by descending weight
+func (d *DB) Concepts(filter ConceptFilter) ([]*Concept, error) {
+	query := `SELECT ` + conceptColumns + ` FROM concepts WHERE COALESCE(weight, 1.0) >= ?`
+	args := []interface{}{filter.MinWeight}
+	if filter.Name != "" {
+		query += ` AND name LIKE '%' || ? || '%'`
+		args = append(args, filter.Name)
+	}
+	query += ` ORDER BY weight DESC, name`
+	if filter.Limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, filter.Limit)
+	}
+
+	rows, err := d.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("listing concepts: %w", err)
+	}
+	defer rows.Close()
+
+	var concepts []*Concept
+	for rows.Next() {
+		c, err := scanConcept(rows)
+		if err != nil {
+			return nil, fmt.Errorf("listing concepts: %w", err)
+		}
+		concepts = append(concepts, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("listing concepts: %w", err)
+	}
+	return concepts, nil
+}
+
+// DeleteConcept removes a concept with its edges and aliases
+func (d *DB) DeleteConcept(id string) error {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return fmt.Errorf("deleting concept %s: %w", id, err)
+	}
+	defer tx.Rollback() // No-op after Commit
+
+	if _, err := tx.Exec(`DELETE FROM edges WHERE source_id = ? OR target_id = ?`, id, id); err != nil {
+		return fmt.Errorf("deleting edges of %s: %w", id, err)
+	}
+	if _, err := tx.Exec(`DELETE FROM aliases WHERE canonical_id = ?`, id); err != nil {
+		return fmt.Errorf("deleting aliases of %s: %w", id, err)
+	}
+	if err := checkAffected(tx.Exec(`DELETE FROM concepts WHERE id = ?`, id)); err != nil {
+		return fmt.Errorf("deleting concept %s: %w", id, err)
+	}
+	return tx.Commit()
+}
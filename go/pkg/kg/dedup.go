@@ -0,0 +1,179 @@
+package kg
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/durapensa/ks/pkg/index"
+)
+
+// DefaultMinScore is the similarity ProposeMerges is usually called with
+const DefaultMinScore = 0.8
+
+// shortName is the length below which names sharing no word are not
+// proposed on spelling alone: one edit turns order into border, or trust
+// into truth
+const shortName = 8
+
+// MergeProposal pairs a concept with a near-duplicate that could be merged
+// into it
+type MergeProposal struct {
+	Keep  *Concept // The heavier of the two
+	Merge *Concept
+	// KeepName and MergeName are the names or aliases that matched best
+	KeepName  string
+	MergeName string
+	// Score is the larger of Spelling and Tokens
+	Score    float64
+	Spelling float64 // Edit distance similarity of the normalized names
+	Tokens   float64 // Overlap of the stemmed words, as the search index has them
+}
+
+// ProposeMerges compares every pair of concepts by name and alias and
+// returns the pairs scoring at least minScore, best first
+func (d *DB) ProposeMerges(minScore float64) ([]*MergeProposal, error) {
+	concepts, err := d.Concepts(ConceptFilter{})
+	if err != nil {
+		return nil, fmt.Errorf("proposing merges: %w", err)
+	}
+	aliases, err := d.Aliases("")
+	if err != nil {
+		return nil, fmt.Errorf("proposing merges: %w", err)
+	}
+
+	names := map[string][]*conceptName{}
+	for _, c := range concepts {
+		names[c.ID] = append(names[c.ID], newConceptName(c.Name))
+	}
+	for _, a := range aliases {
+		if _, ok := names[a.CanonicalID]; ok {
+			names[a.CanonicalID] = append(names[a.CanonicalID], newConceptName(a.Alias))
+		}
+	}
+
+	var proposals []*MergeProposal
+	for i, a := range concepts {
+		for _, b := range concepts[i+1:] {
+			p := bestMatch(names[a.ID], names[b.ID], minScore)
+			if p == nil {
+				continue
+			}
+			// Concepts come heaviest first, so a is kept unless b wins the
+			// tie on age
+			p.Keep, p.Merge = a, b
+			if a.Weight == b.Weight && b.Created.Before(a.Created) {
+				p.Keep, p.Merge = b, a
+				p.KeepName, p.MergeName = p.MergeName, p.KeepName
+			}
+			proposals = append(proposals, p)
+		}
+	}
+	// Equal scores are told apart by the measure that didn't set them
+	sort.SliceStable(proposals, func(i, j int) bool {
+		a, b := proposals[i], proposals[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		return a.Spelling+a.Tokens > b.Spelling+b.Tokens
+	})
+	return proposals, nil
+}
+
+// conceptName is a name or alias prepared for comparison
+type conceptName struct {
+	text       string
+	normalized []rune
+	stems      map[string]bool
+}
+
+func newConceptName(text string) *conceptName {
+	n := &conceptName{
+		text:       text,
+		normalized: []rune(strings.Join(normalizeWords(text), " ")),
+		stems:      map[string]bool{},
+	}
+	for _, token := range index.Tokenize(text) {
+		n.stems[token.Term] = true
+	}
+	return n
+}
+
+// bestMatch finds the most similar pair of names, or nil if none reaches
+// minScore
+func bestMatch(as, bs []*conceptName, minScore float64) *MergeProposal {
+	var best *MergeProposal
+	for _, a := range as {
+		for _, b := range bs {
+			if len(a.normalized) == 0 || len(b.normalized) == 0 {
+				continue
+			}
+			tokens := tokenSimilarity(a.stems, b.stems)
+			// The length difference bounds the spelling score; skip the
+			// edit distance when the pair can't reach minScore either way
+			longest := max(len(a.normalized), len(b.normalized))
+			diff := len(a.normalized) - len(b.normalized)
+			if diff < 0 {
+				diff = -diff
+			}
+			if 1-float64(diff)/float64(longest) < minScore && tokens < minScore {
+				continue
+			}
+			spelling := 1 - float64(editDistance(a.normalized, b.normalized))/float64(longest)
+			score := max(spelling, tokens)
+			if tokens == 0 && longest < shortName {
+				score = 0
+			}
+			if score >= minScore && (best == nil || score > best.Score) {
+				best = &MergeProposal{KeepName: a.text, MergeName: b.text, Score: score, Spelling: spelling, Tokens: tokens}
+			}
+		}
+	}
+	return best
+}
+
+// normalizeWords lower-cases text and splits it into words of letters and
+// digits
+func normalizeWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// tokenSimilarity is the overlap coefficient of two sets of stems: the
+// shared stems over the smaller set, so a name whose words all appear in the
+// other name scores 1 however many words the other adds
+func tokenSimilarity(a, b map[string]bool) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	shared := 0
+	for s := range a {
+		if b[s] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(min(len(a), len(b)))
+}
+
+// editDistance is the Levenshtein distance between two strings
+func editDistance(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
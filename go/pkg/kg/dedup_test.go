@@ -0,0 +1,50 @@
+package kg
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// Pairs of concept names and whether ProposeMerges pairs them at the
+// default score
+func TestProposeMerges(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"emergence", "emergent behaviour", true},
+		{"emergent behavior", "emergent behaviour", true},
+		{"Knowledge Graph", "knowledge-graph", true},
+		{"order", "border", false},
+		{"trust", "truth", false},
+		{"emergence", "knowledge graph", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.a+"/"+tt.b, func(t *testing.T) {
+			d, err := Open(filepath.Join(t.TempDir(), "kg.db"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer d.Close()
+
+			created := time.Now().UTC().Truncate(time.Second)
+			for i, name := range []string{tt.a, tt.b} {
+				c := &Concept{ID: string(rune('A' + i)), Name: name, Weight: 1, Created: created, Updated: created}
+				if err := d.PutConcept(c); err != nil {
+					t.Fatal(err)
+				}
+			}
+			proposals, err := d.ProposeMerges(DefaultMinScore)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := len(proposals) == 1; got != tt.want {
+				t.Errorf("proposed %d merges, want proposed = %v", len(proposals), tt.want)
+				for _, p := range proposals {
+					t.Logf("%q/%q: score %.2f (spelling %.2f, tokens %.2f)", p.KeepName, p.MergeName, p.Score, p.Spelling, p.Tokens)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package kg
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Edge types used by the distillation tools; the column accepts any string
+const (
+	EdgeRelates     = "relates"
+	EdgeCauses      = "causes"
+	EdgeContradicts = "contradicts"
+)
+
+// Edge is a row of the edges table, a typed link between two concepts
+type Edge struct {
+	SourceID string
+	TargetID string
+	Type     string
+	Strength float64 // 0-1
+	Created  time.Time
+}
+
+const edgeColumns = `source_id, target_id, edge_type, COALESCE(strength, 1.0), created`
+
+// EdgeFilter selects edges for Edges; empty fields match everything
+type EdgeFilter struct {
+	SourceID string
+	TargetID string
+	// Concept matches edges on either end
+	Concept     string
+	Type        string
+	MinStrength float64
+}
+
+func scanEdge(row scanner) (*Edge, error) {
+	var e Edge
+	var created string
+	if err := row.Scan(&e.SourceID, &e.TargetID, &e.Type, &e.Strength, &created); err != nil {
+		return nil, err
+	}
+	e.Created = parseTime(created)
+	return &e, nil
+}
+
+// PutEdge inserts e or replaces the edge with the same ends and type
+func (d *DB) PutEdge(e *Edge) error {
+	if e.Created.IsZero() {
+		e.Created = time.Now().UTC().Truncate(time.Second)
+	}
+	return putEdge(d.db, e)
+}
+
+func putEdge(db execer, e *Edge) error {
+	_, err := db.Exec(`
+		INSERT OR REPLACE INTO edges (source_id, target_id, edge_type, strength, created)
+		VALUES (?, ?, ?, ?, ?)`,
+		e.SourceID, e.TargetID, e.Type, e.Strength, formatTime(e.Created))
+	if err != nil {
+		return fmt.Errorf("saving edge %s -%s-> %s: %w", e.SourceID, e.Type, e.TargetID, err)
+	}
+	return nil
+}
+
+// Edge reads one edge by its key
+func (d *DB) Edge(sourceID, targetID, edgeType string) (*Edge, error) {
+	e, err := scanEdge(d.db.QueryRow(`
+		SELECT `+edgeColumns+` FROM edges
+		WHERE source_id = ? AND target_id = ? AND edge_type = ?`,
+		sourceID, targetID, edgeType))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("edge %s -%s-> %s: %w", sourceID, edgeType, targetID, ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading edge %s -%s-> %s: %w", sourceID, edgeType, targetID, err)
+	}
+	return e, nil
+}
+
+// Edges lists edges by descending strength
+func (d *DB) Edges(filter EdgeFilter) ([]*Edge, error) {
+	query := `SELECT ` + edgeColumns + ` FROM edges WHERE COALESCE(strength, 1.0) >= ?`
+	args := []interface{}{filter.MinStrength}
+	if filter.SourceID != "" {
+		query += ` AND source_id = ?`
+		args = append(args, filter.SourceID)
+	}
+	if filter.TargetID != "" {
+		query += ` AND target_id = ?`
+		args = append(args, filter.TargetID)
+	}
+	if filter.Concept != "" {
+		query += ` AND (source_id = ? OR target_id = ?)`
+		args = append(args, filter.Concept, filter.Concept)
+	}
+	if filter.Type != "" {
+		query += ` AND edge_type = ?`
+		args = append(args, filter.Type)
+	}
+	query += ` ORDER BY strength DESC, source_id, target_id`
+
+	rows, err := d.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("listing edges: %w", err)
+	}
+	defer rows.Close()
+
+	var edges []*Edge
+	for rows.Next() {
+		e, err := scanEdge(rows)
+		if err != nil {
+			return nil, fmt.Errorf("listing edges: %w", err)
+		}
+		edges = append(edges, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("listing edges: %w", err)
+	}
+	return edges, nil
+}
+
+// EdgeTypes lists the distinct edge types in use
+func (d *DB) EdgeTypes() ([]string, error) {
+	rows, err := d.db.Query(`SELECT DISTINCT edge_type FROM edges ORDER BY edge_type`)
+	if err != nil {
+		return nil, fmt.Errorf("listing edge types: %w", err)
+	}
+	defer rows.Close()
+
+	var types []string
+	for rows.Next() {
+		var t string
+		if err := rows.Scan(&t); err != nil {
+			return nil, fmt.Errorf("listing edge types: %w", err)
+		}
+		types = append(types, t)
+	}
+	return types, rows.Err()
+}
+
+// DeleteEdge removes one edge
+func (d *DB) DeleteEdge(sourceID, targetID, edgeType string) error {
+	err := checkAffected(d.db.Exec(`
+		DELETE FROM edges WHERE source_id = ? AND target_id = ? AND edge_type = ?`,
+		sourceID, targetID, edgeType))
+	if err != nil {
+		return fmt.Errorf("deleting edge %s -%s-> %s: %w", sourceID, edgeType, targetID, err)
+	}
+	return nil
+}
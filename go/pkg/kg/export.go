@@ -0,0 +1,243 @@
+package kg
+
+import (
+	"bufio"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ExportFormats accepted by Export
+var ExportFormats = []string{"dot", "graphml", "gexf", "cytoscape"}
+
+// ExportExtensions maps output file extensions to export formats
+var ExportExtensions = map[string]string{
+	".dot":     "dot",
+	".gv":      "dot",
+	".graphml": "graphml",
+	".gexf":    "gexf",
+	".json":    "cytoscape",
+	".cyjs":    "cytoscape",
+}
+
+// aliasSeparator joins aliases in formats without list attributes
+const aliasSeparator = "; "
+
+// Export writes the concepts and edges selected by filter in one of
+// ExportFormats. Nodes carry weight, human and AI contribution, aliases
+// and timestamps; edges carry type, strength and creation time.
+func (d *DB) Export(w io.Writer, format string, filter GraphFilter) error {
+	g, err := d.Graph(filter)
+	if err != nil {
+		return err
+	}
+	all, err := d.Aliases("")
+	if err != nil {
+		return fmt.Errorf("exporting graph: %w", err)
+	}
+	aliases := map[string][]string{}
+	for _, a := range all {
+		aliases[a.CanonicalID] = append(aliases[a.CanonicalID], a.Alias)
+	}
+
+	b := bufio.NewWriter(w)
+	switch format {
+	case "dot":
+		writeDOT(b, g, aliases)
+	case "graphml":
+		writeGraphML(b, g, aliases)
+	case "gexf":
+		writeGEXF(b, g, aliases)
+	case "cytoscape":
+		if err := writeCytoscape(b, g, aliases); err != nil {
+			return fmt.Errorf("exporting graph: %w", err)
+		}
+	default:
+		return fmt.Errorf("unknown export format %q (want %s)", format, strings.Join(ExportFormats, ", "))
+	}
+	if err := b.Flush(); err != nil {
+		return fmt.Errorf("exporting graph: %w", err)
+	}
+	return nil
+}
+
+// edgeID is unique because an edge's ends and type are its key
+func edgeID(e *Edge) string {
+	return e.SourceID + "-" + e.Type + "-" + e.TargetID
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// dotQuote makes a double-quoted DOT string
+func dotQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	s = strings.ReplaceAll(s, "\n", `\n`)
+	return `"` + s + `"`
+}
+
+func writeDOT(w *bufio.Writer, g *Graph, aliases map[string][]string) {
+	w.WriteString("digraph kg {\n")
+	for _, c := range g.Concepts {
+		fmt.Fprintf(w, "  %s [label=%s, weight=%s, human_weight=%s, ai_weight=%s, aliases=%s, created=%s, updated=%s];\n",
+			dotQuote(c.ID), dotQuote(c.Name), formatFloat(c.Weight), formatFloat(c.HumanWeight), formatFloat(c.AIWeight),
+			dotQuote(strings.Join(aliases[c.ID], aliasSeparator)), dotQuote(formatTime(c.Created)), dotQuote(formatTime(c.Updated)))
+	}
+	for _, e := range g.Edges {
+		// Graphviz's own edge weight must be an integer for dot, so
+		// strength keeps its own name
+		fmt.Fprintf(w, "  %s -> %s [label=%s, edge_type=%s, strength=%s, created=%s];\n",
+			dotQuote(e.SourceID), dotQuote(e.TargetID), dotQuote(e.Type), dotQuote(e.Type),
+			formatFloat(e.Strength), dotQuote(formatTime(e.Created)))
+	}
+	w.WriteString("}\n")
+}
+
+// xmlAttr escapes an attribute value
+func xmlAttr(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+// GraphML keys: Gephi reads "label" as the node label and "weight" as the
+// edge weight, which is the strength
+var graphMLKeys = []struct{ id, target, name, typ string }{
+	{"label", "node", "label", "string"},
+	{"weight", "node", "weight", "double"},
+	{"human_weight", "node", "human_weight", "double"},
+	{"ai_weight", "node", "ai_weight", "double"},
+	{"aliases", "node", "aliases", "string"},
+	{"created", "node", "created", "string"},
+	{"updated", "node", "updated", "string"},
+	{"edge_type", "edge", "edge_type", "string"},
+	{"strength", "edge", "strength", "double"},
+	{"edge_weight", "edge", "weight", "double"},
+	{"edge_created", "edge", "created", "string"},
+}
+
+func writeGraphML(w *bufio.Writer, g *Graph, aliases map[string][]string) {
+	w.WriteString(xml.Header)
+	w.WriteString(`<graphml xmlns="http://graphml.graphdrawing.org/xmlns">` + "\n")
+	for _, k := range graphMLKeys {
+		fmt.Fprintf(w, "  <key id=%q for=%q attr.name=%q attr.type=%q/>\n", k.id, k.target, k.name, k.typ)
+	}
+	w.WriteString(`  <graph id="kg" edgedefault="directed">` + "\n")
+	data := func(key, value string) {
+		fmt.Fprintf(w, "      <data key=%q>%s</data>\n", key, xmlAttr(value))
+	}
+	for _, c := range g.Concepts {
+		fmt.Fprintf(w, "    <node id=\"%s\">\n", xmlAttr(c.ID))
+		data("label", c.Name)
+		data("weight", formatFloat(c.Weight))
+		data("human_weight", formatFloat(c.HumanWeight))
+		data("ai_weight", formatFloat(c.AIWeight))
+		data("aliases", strings.Join(aliases[c.ID], aliasSeparator))
+		data("created", formatTime(c.Created))
+		data("updated", formatTime(c.Updated))
+		w.WriteString("    </node>\n")
+	}
+	for _, e := range g.Edges {
+		fmt.Fprintf(w, "    <edge id=\"%s\" source=\"%s\" target=\"%s\">\n", xmlAttr(edgeID(e)), xmlAttr(e.SourceID), xmlAttr(e.TargetID))
+		data("edge_type", e.Type)
+		data("strength", formatFloat(e.Strength))
+		data("edge_weight", formatFloat(e.Strength))
+		data("edge_created", formatTime(e.Created))
+		w.WriteString("    </edge>\n")
+	}
+	w.WriteString("  </graph>\n</graphml>\n")
+}
+
+func writeGEXF(w *bufio.Writer, g *Graph, aliases map[string][]string) {
+	w.WriteString(xml.Header)
+	w.WriteString(`<gexf xmlns="http://gexf.net/1.3" version="1.3">` + "\n")
+	w.WriteString("  <meta>\n    <creator>ks</creator>\n    <description>Knowledge graph</description>\n  </meta>\n")
+	w.WriteString(`  <graph mode="static" defaultedgetype="directed">` + "\n")
+	w.WriteString(`    <attributes class="node">` + "\n")
+	for _, a := range [][2]string{{"weight", "double"}, {"human_weight", "double"}, {"ai_weight", "double"}, {"aliases", "string"}, {"created", "string"}, {"updated", "string"}} {
+		fmt.Fprintf(w, "      <attribute id=%q title=%q type=%q/>\n", a[0], a[0], a[1])
+	}
+	w.WriteString("    </attributes>\n")
+	w.WriteString(`    <attributes class="edge">` + "\n")
+	for _, a := range [][2]string{{"edge_type", "string"}, {"strength", "double"}, {"created", "string"}} {
+		fmt.Fprintf(w, "      <attribute id=%q title=%q type=%q/>\n", a[0], a[0], a[1])
+	}
+	w.WriteString("    </attributes>\n")
+
+	value := func(key, v string) {
+		fmt.Fprintf(w, "          <attvalue for=%q value=\"%s\"/>\n", key, xmlAttr(v))
+	}
+	w.WriteString("    <nodes>\n")
+	for _, c := range g.Concepts {
+		fmt.Fprintf(w, "      <node id=\"%s\" label=\"%s\">\n        <attvalues>\n", xmlAttr(c.ID), xmlAttr(c.Name))
+		value("weight", formatFloat(c.Weight))
+		value("human_weight", formatFloat(c.HumanWeight))
+		value("ai_weight", formatFloat(c.AIWeight))
+		value("aliases", strings.Join(aliases[c.ID], aliasSeparator))
+		value("created", formatTime(c.Created))
+		value("updated", formatTime(c.Updated))
+		w.WriteString("        </attvalues>\n      </node>\n")
+	}
+	w.WriteString("    </nodes>\n    <edges>\n")
+	for _, e := range g.Edges {
+		fmt.Fprintf(w, "      <edge id=\"%s\" source=\"%s\" target=\"%s\" label=\"%s\" weight=\"%s\">\n        <attvalues>\n",
+			xmlAttr(edgeID(e)), xmlAttr(e.SourceID), xmlAttr(e.TargetID), xmlAttr(e.Type), formatFloat(e.Strength))
+		value("edge_type", e.Type)
+		value("strength", formatFloat(e.Strength))
+		value("created", formatTime(e.Created))
+		w.WriteString("        </attvalues>\n      </edge>\n")
+	}
+	w.WriteString("    </edges>\n  </graph>\n</gexf>\n")
+}
+
+// Cytoscape.js elements JSON
+type cytoscapeGraph struct {
+	Elements struct {
+		Nodes []cytoscapeElement `json:"nodes"`
+		Edges []cytoscapeElement `json:"edges"`
+	} `json:"elements"`
+}
+
+type cytoscapeElement struct {
+	Data map[string]interface{} `json:"data"`
+}
+
+func writeCytoscape(w *bufio.Writer, g *Graph, aliases map[string][]string) error {
+	var out cytoscapeGraph
+	out.Elements.Nodes = []cytoscapeElement{}
+	out.Elements.Edges = []cytoscapeElement{}
+	for _, c := range g.Concepts {
+		names := aliases[c.ID]
+		if names == nil {
+			names = []string{}
+		}
+		out.Elements.Nodes = append(out.Elements.Nodes, cytoscapeElement{Data: map[string]interface{}{
+			"id":           c.ID,
+			"name":         c.Name,
+			"weight":       c.Weight,
+			"human_weight": c.HumanWeight,
+			"ai_weight":    c.AIWeight,
+			"aliases":      names,
+			"created":      formatTime(c.Created),
+			"updated":      formatTime(c.Updated),
+		}})
+	}
+	for _, e := range g.Edges {
+		out.Elements.Edges = append(out.Elements.Edges, cytoscapeElement{Data: map[string]interface{}{
+			"id":        edgeID(e),
+			"source":    e.SourceID,
+			"target":    e.TargetID,
+			"edge_type": e.Type,
+			"strength":  e.Strength,
+			"created":   formatTime(e.Created),
+		}})
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(out)
+}
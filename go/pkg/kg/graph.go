@@ -0,0 +1,206 @@
+package kg
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// ErrNoPath is returned when two concepts are not connected
+var ErrNoPath = errors.New("no path")
+
+// GraphFilter selects the concepts and edges loaded by Graph
+type GraphFilter struct {
+	MinWeight   float64  // Concepts below this weight are left out with their edges
+	Types       []string // Edge types to keep; empty keeps every type
+	MinStrength float64
+	// Directed follows edges from source to target only; otherwise edges
+	// are walked both ways. Components and communities ignore direction.
+	Directed bool
+}
+
+// Graph is the knowledge graph in memory for analysis. Nodes are numbered
+// in concept order, heaviest first.
+type Graph struct {
+	Concepts []*Concept
+	Edges    []*Edge
+	Directed bool
+
+	index map[string]int
+	out   [][]arc
+	in    [][]arc
+}
+
+// arc is an edge as seen from one of its ends
+type arc struct {
+	to   int
+	edge *Edge
+}
+
+// Score is an analysis result for one concept
+type Score struct {
+	Concept *Concept
+	Value   float64
+}
+
+// Route is a path between two concepts, Edges[i] joining Concepts[i] and
+// Concepts[i+1]
+type Route struct {
+	Concepts []*Concept
+	Edges    []*Edge
+	// Strength is the product of the edge strengths
+	Strength float64
+}
+
+// Graph loads the concepts and edges selected by filter. Edges to
+// concepts that are filtered out or no longer exist are left out.
+func (d *DB) Graph(filter GraphFilter) (*Graph, error) {
+	concepts, err := d.Concepts(ConceptFilter{MinWeight: filter.MinWeight})
+	if err != nil {
+		return nil, fmt.Errorf("loading graph: %w", err)
+	}
+	edges, err := d.Edges(EdgeFilter{MinStrength: filter.MinStrength})
+	if err != nil {
+		return nil, fmt.Errorf("loading graph: %w", err)
+	}
+	if len(filter.Types) > 0 {
+		keep := map[string]bool{}
+		for _, t := range filter.Types {
+			keep[t] = true
+		}
+		var kept []*Edge
+		for _, e := range edges {
+			if keep[e.Type] {
+				kept = append(kept, e)
+			}
+		}
+		edges = kept
+	}
+	return NewGraph(concepts, edges, filter.Directed), nil
+}
+
+// NewGraph builds a graph from concepts and the edges between them
+func NewGraph(concepts []*Concept, edges []*Edge, directed bool) *Graph {
+	g := &Graph{
+		Concepts: concepts,
+		Directed: directed,
+		index:    make(map[string]int, len(concepts)),
+		out:      make([][]arc, len(concepts)),
+		in:       make([][]arc, len(concepts)),
+	}
+	for i, c := range concepts {
+		g.index[c.ID] = i
+	}
+	for _, e := range edges {
+		s, okS := g.index[e.SourceID]
+		t, okT := g.index[e.TargetID]
+		if !okS || !okT {
+			continue
+		}
+		g.Edges = append(g.Edges, e)
+		g.out[s] = append(g.out[s], arc{to: t, edge: e})
+		g.in[t] = append(g.in[t], arc{to: s, edge: e})
+	}
+	return g
+}
+
+// node finds a concept's node number
+func (g *Graph) node(id string) (int, error) {
+	i, ok := g.index[id]
+	if !ok {
+		return 0, fmt.Errorf("concept %s: %w", id, ErrNotFound)
+	}
+	return i, nil
+}
+
+// Concept finds a concept of the graph by ID, or nil
+func (g *Graph) Concept(id string) *Concept {
+	i, ok := g.index[id]
+	if !ok {
+		return nil
+	}
+	return g.Concepts[i]
+}
+
+// neighbours are the arcs a walk can follow from node i
+func (g *Graph) neighbours(i int) []arc {
+	if g.Directed {
+		return g.out[i]
+	}
+	return append(g.out[i][:len(g.out[i]):len(g.out[i])], g.in[i]...)
+}
+
+// Degree counts the edges at a concept, both ways
+func (g *Graph) Degree(id string) int {
+	i, ok := g.index[id]
+	if !ok {
+		return 0
+	}
+	return len(g.out[i]) + len(g.in[i])
+}
+
+// Components groups concepts connected by edges in either direction,
+// largest first
+func (g *Graph) Components() [][]*Concept {
+	parent := make([]int, len(g.Concepts))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	for s, arcs := range g.out {
+		for _, a := range arcs {
+			if rs, rt := find(s), find(a.to); rs != rt {
+				parent[max(rs, rt)] = min(rs, rt)
+			}
+		}
+	}
+
+	groups := map[int][]int{}
+	for i := range g.Concepts {
+		root := find(i)
+		groups[root] = append(groups[root], i)
+	}
+	return g.groups(groups)
+}
+
+// groups turns sets of node numbers into concept lists, largest first and
+// otherwise in node order
+func (g *Graph) groups(sets map[int][]int) [][]*Concept {
+	list := make([][]int, 0, len(sets))
+	for _, set := range sets {
+		sort.Ints(set)
+		list = append(list, set)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if len(list[i]) != len(list[j]) {
+			return len(list[i]) > len(list[j])
+		}
+		return list[i][0] < list[j][0]
+	})
+
+	result := make([][]*Concept, len(list))
+	for i, set := range list {
+		for _, n := range set {
+			result[i] = append(result[i], g.Concepts[n])
+		}
+	}
+	return result
+}
+
+// scores pairs per-node values with their concepts, highest first
+func (g *Graph) scores(values []float64) []Score {
+	result := make([]Score, len(values))
+	for i, v := range values {
+		result[i] = Score{Concept: g.Concepts[i], Value: v}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Value > result[j].Value
+	})
+	return result
+}
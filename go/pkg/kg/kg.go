@@ -0,0 +1,188 @@
+package kg
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/durapensa/ks/pkg/config"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// FileName is the knowledge graph database inside a knowledge directory
+const FileName = "kg.db"
+
+// Path locates kg.db: a conversation keeps its own graph in its knowledge
+// directory; otherwise it is ./knowledge/kg.db whenever the working
+// directory has a knowledge directory, like tools/kg/query, and
+// $KS_ROOT/knowledge/kg.db if not
+func Path(cfg *config.Config) string {
+	if cfg.IsConversation {
+		return filepath.Join(cfg.ConversationDir, "knowledge", FileName)
+	}
+	if stat, err := os.Stat("knowledge"); err == nil && stat.IsDir() {
+		if dir, err := filepath.Abs("knowledge"); err == nil {
+			return filepath.Join(dir, FileName)
+		}
+	}
+	return filepath.Join(cfg.KSRoot, "knowledge", FileName)
+}
+
+// ExperimentPath locates the graph of a logex conversation under
+// KS_EXPERIMENTS_DIR by its directory name
+func ExperimentPath(cfg *config.Config, name string) string {
+	return filepath.Join(cfg.ExperimentsDir, name, "knowledge", FileName)
+}
+
+// TimeFormat is how tools/kg writes timestamps (date -u +%Y-%m-%dT%H:%M:%SZ)
+const TimeFormat = "2006-01-02T15:04:05Z"
+
+// ErrNotFound is returned when a concept, edge, alias or run doesn't exist
+var ErrNotFound = errors.New("not found")
+
+// ConceptID derives a concept's ID from its name like run-distillation:
+// the first 16 hex digits of its SHA-256
+func ConceptID(name string) string {
+	sum := sha256.Sum256([]byte(name))
+	return hex.EncodeToString(sum[:])[:16]
+}
+
+// DB is an open knowledge graph database
+type DB struct {
+	Path string
+	db   *sql.DB
+}
+
+// OpenReadOnly opens an existing database without writing to it
+func OpenReadOnly(path string) (*DB, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("opening knowledge graph: %w", err)
+	}
+	db, err := sql.Open("sqlite3", "file:"+path+"?mode=ro")
+	if err != nil {
+		return nil, fmt.Errorf("opening knowledge graph: %w", err)
+	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("opening knowledge graph: %w", err)
+	}
+	return &DB{Path: path, db: db}, nil
+}
+
+// Open opens the database for reading and writing, creating it and its
+// directory if needed, and applies pending migrations
+func Open(path string) (*DB, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("opening knowledge graph: %w", err)
+	}
+	// One connection keeps transactions and PRAGMAs on the same handle and
+	// serialises writers
+	db, err := sql.Open("sqlite3", "file:"+path+"?_busy_timeout=5000")
+	if err != nil {
+		return nil, fmt.Errorf("opening knowledge graph: %w", err)
+	}
+	db.SetMaxOpenConns(1)
+	d := &DB{Path: path, db: db}
+	if err := d.Migrate(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("opening knowledge graph: %w", err)
+	}
+	return d, nil
+}
+
+// Close closes the database
+func (d *DB) Close() error {
+	return d.db.Close()
+}
+
+// Stats summarises the graph like tools/kg/query --stats
+type Stats struct {
+	Concepts int
+	Edges    int
+	Aliases  int
+
+	DistillationRuns int
+	// LastRunStatus and LastRunAt describe the latest distillation run
+	LastRunStatus string
+	LastRunAt     string
+}
+
+// Stats counts concepts, edges, aliases and distillation runs
+func (d *DB) Stats() (*Stats, error) {
+	stats := &Stats{}
+	counts := []struct {
+		table string
+		dest  *int
+	}{
+		{"concepts", &stats.Concepts},
+		{"edges", &stats.Edges},
+		{"aliases", &stats.Aliases},
+		{"distillation_runs", &stats.DistillationRuns},
+	}
+	for _, c := range counts {
+		if err := d.db.QueryRow("SELECT COUNT(*) FROM " + c.table).Scan(c.dest); err != nil {
+			return nil, fmt.Errorf("counting %s: %w", c.table, err)
+		}
+	}
+
+	var completedAt sql.NullString
+	err := d.db.QueryRow(`
+		SELECT status, started_at, completed_at FROM distillation_runs
+		ORDER BY id DESC LIMIT 1`).Scan(&stats.LastRunStatus, &stats.LastRunAt, &completedAt)
+	switch {
+	case err == sql.ErrNoRows:
+	case err != nil:
+		return nil, fmt.Errorf("reading distillation runs: %w", err)
+	case completedAt.Valid:
+		stats.LastRunAt = completedAt.String
+	}
+	return stats, nil
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(TimeFormat)
+}
+
+// parseTime reads a stored timestamp; anything unreadable is the zero time
+func parseTime(value string) time.Time {
+	for _, layout := range []string{time.RFC3339, "2006-01-02 15:04:05"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// nullTime stores the zero time as NULL
+func nullTime(t time.Time) sql.NullString {
+	return sql.NullString{String: formatTime(t), Valid: !t.IsZero()}
+}
+
+// execer is a database or a transaction
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+// checkAffected turns an update or delete that matched nothing into
+// ErrNotFound
+func checkAffected(result sql.Result, err error) error {
+	if err != nil {
+		return err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,345 @@
+package kg
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// MergeSource is the alias source given to a merged concept's name
+const MergeSource = "merge"
+
+// Merge is a row of the merges table, the audit record of one concept
+// merged into another
+type Merge struct {
+	ID         int64
+	KeptID     string
+	MergedID   string
+	MergedName string
+	Score      float64 // Similarity that proposed the merge; 0 if chosen by hand
+	MergedAt   time.Time
+	UndoneAt   time.Time // Zero unless undone
+}
+
+// mergeSnapshot is what a merge changes, saved so that it can be undone
+type mergeSnapshot struct {
+	Concepts []*Concept `json:"concepts"`
+	Aliases  []*Alias   `json:"aliases"`
+	Edges    []*Edge    `json:"edges"`
+}
+
+const mergeColumns = `id, kept_id, merged_id, merged_name, COALESCE(score, 0), merged_at, COALESCE(undone_at, '')`
+
+func scanMerge(row scanner) (*Merge, error) {
+	var m Merge
+	var mergedAt, undoneAt string
+	if err := row.Scan(&m.ID, &m.KeptID, &m.MergedID, &m.MergedName, &m.Score, &mergedAt, &undoneAt); err != nil {
+		return nil, err
+	}
+	m.MergedAt, m.UndoneAt = parseTime(mergedAt), parseTime(undoneAt)
+	return &m, nil
+}
+
+// MergeConcepts folds the concept mergeID into keepID in one transaction.
+// Edges are re-pointed at the kept concept, keeping the stronger of two
+// that collide and dropping those between the pair. The merged concept's
+// name and aliases become aliases of the kept one, counts summed. Weights
+// are summed and the human and AI contributions averaged by weight. The
+// merge is recorded with score, 0 for a merge chosen by hand.
+func (d *DB) MergeConcepts(keepID, mergeID string, score float64) (*Merge, error) {
+	if keepID == mergeID {
+		return nil, fmt.Errorf("merging concept %s into itself", keepID)
+	}
+	keep, err := d.Concept(keepID)
+	if err != nil {
+		return nil, err
+	}
+	merged, err := d.Concept(mergeID)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := mergeSnapshot{Concepts: []*Concept{keep, merged}}
+	for _, id := range []string{keep.ID, merged.ID} {
+		aliases, err := d.Aliases(id)
+		if err != nil {
+			return nil, fmt.Errorf("merging %s: %w", merged.Name, err)
+		}
+		snapshot.Aliases = append(snapshot.Aliases, aliases...)
+	}
+	seen := map[string]bool{}
+	for _, id := range []string{keep.ID, merged.ID} {
+		edges, err := d.Edges(EdgeFilter{Concept: id})
+		if err != nil {
+			return nil, fmt.Errorf("merging %s: %w", merged.Name, err)
+		}
+		for _, e := range edges {
+			if !seen[edgeID(e)] {
+				seen[edgeID(e)] = true
+				snapshot.Edges = append(snapshot.Edges, e)
+			}
+		}
+	}
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return nil, fmt.Errorf("merging %s: %w", merged.Name, err)
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	combined := combineConcepts(keep, merged, now)
+	edges := repointEdges(snapshot.Edges, keep.ID, merged.ID)
+	aliases := combineAliases(keep, merged, snapshot.Aliases)
+
+	tx, err := d.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("merging %s: %w", merged.Name, err)
+	}
+	defer tx.Rollback() // No-op after Commit
+
+	for _, id := range []string{keep.ID, merged.ID} {
+		if _, err := tx.Exec(`DELETE FROM edges WHERE source_id = ? OR target_id = ?`, id, id); err != nil {
+			return nil, fmt.Errorf("merging %s: %w", merged.Name, err)
+		}
+		if _, err := tx.Exec(`DELETE FROM aliases WHERE canonical_id = ?`, id); err != nil {
+			return nil, fmt.Errorf("merging %s: %w", merged.Name, err)
+		}
+	}
+	if _, err := tx.Exec(`DELETE FROM concepts WHERE id = ?`, merged.ID); err != nil {
+		return nil, fmt.Errorf("merging %s: %w", merged.Name, err)
+	}
+	if err := putConcept(tx, combined); err != nil {
+		return nil, err
+	}
+	for _, e := range edges {
+		if err := putEdge(tx, e); err != nil {
+			return nil, err
+		}
+	}
+	for _, a := range aliases {
+		if err := putAlias(tx, a); err != nil {
+			return nil, err
+		}
+	}
+
+	m := &Merge{KeptID: keep.ID, MergedID: merged.ID, MergedName: merged.Name, Score: score, MergedAt: now}
+	result, err := tx.Exec(`
+		INSERT INTO merges (kept_id, merged_id, merged_name, score, merged_at, snapshot)
+		VALUES (?, ?, ?, NULLIF(?, 0), ?, ?)`,
+		m.KeptID, m.MergedID, m.MergedName, m.Score, formatTime(m.MergedAt), string(data))
+	if err != nil {
+		return nil, fmt.Errorf("recording merge of %s: %w", merged.Name, err)
+	}
+	if m.ID, err = result.LastInsertId(); err != nil {
+		return nil, fmt.Errorf("recording merge of %s: %w", merged.Name, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("merging %s: %w", merged.Name, err)
+	}
+	return m, nil
+}
+
+// combineConcepts is keep after absorbing merged
+func combineConcepts(keep, merged *Concept, now time.Time) *Concept {
+	c := *keep
+	c.Weight = keep.Weight + merged.Weight
+	if c.Weight > 0 {
+		c.HumanWeight = (keep.HumanWeight*keep.Weight + merged.HumanWeight*merged.Weight) / c.Weight
+		c.AIWeight = (keep.AIWeight*keep.Weight + merged.AIWeight*merged.Weight) / c.Weight
+	}
+	if !merged.Created.IsZero() && (c.Created.IsZero() || merged.Created.Before(c.Created)) {
+		c.Created = merged.Created
+	}
+	c.Updated = now
+	return &c
+}
+
+// repointEdges moves the edges of mergeID to keepID. Edges that end up
+// with the same key keep the larger strength and earlier creation, and
+// edges between the two concepts are dropped.
+func repointEdges(edges []*Edge, keepID, mergeID string) []*Edge {
+	var result []*Edge
+	byKey := map[string]*Edge{}
+	for _, e := range edges {
+		moved := *e
+		if moved.SourceID == mergeID {
+			moved.SourceID = keepID
+		}
+		if moved.TargetID == mergeID {
+			moved.TargetID = keepID
+		}
+		if moved.SourceID == keepID && moved.TargetID == keepID {
+			continue
+		}
+		key := edgeID(&moved)
+		if existing, ok := byKey[key]; ok {
+			existing.Strength = max(existing.Strength, moved.Strength)
+			if moved.Created.Before(existing.Created) {
+				existing.Created = moved.Created
+			}
+			continue
+		}
+		byKey[key] = &moved
+		result = append(result, &moved)
+	}
+	return result
+}
+
+// combineAliases gives keep the aliases of both concepts and the name of
+// merged, summing the counts of aliases they share. Aliases matching the
+// kept name are dropped.
+func combineAliases(keep, merged *Concept, aliases []*Alias) []*Alias {
+	all := append([]*Alias{}, aliases...)
+	all = append(all, &Alias{CanonicalID: merged.ID, Alias: merged.Name, Source: MergeSource, Count: 1})
+
+	var result []*Alias
+	byText := map[string]*Alias{}
+	for _, a := range all {
+		if strings.EqualFold(a.Alias, keep.Name) {
+			continue
+		}
+		if existing, ok := byText[a.Alias]; ok {
+			existing.Count += a.Count
+			continue
+		}
+		moved := *a
+		moved.CanonicalID = keep.ID
+		byText[a.Alias] = &moved
+		result = append(result, &moved)
+	}
+	return result
+}
+
+// Merges lists the audit trail, newest first
+func (d *DB) Merges() ([]*Merge, error) {
+	rows, err := d.db.Query(`SELECT ` + mergeColumns + ` FROM merges ORDER BY id DESC`)
+	if err != nil {
+		return nil, fmt.Errorf("listing merges: %w", err)
+	}
+	defer rows.Close()
+
+	var merges []*Merge
+	for rows.Next() {
+		m, err := scanMerge(rows)
+		if err != nil {
+			return nil, fmt.Errorf("listing merges: %w", err)
+		}
+		merges = append(merges, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("listing merges: %w", err)
+	}
+	return merges, nil
+}
+
+// UndoMerge restores both concepts of a merge with their aliases and
+// edges as they were before it. Edges the kept concept gained since are
+// kept; later changes to its weight and aliases are lost. Later merges
+// involving either concept or a neighbour they had must be undone first,
+// and edges to concepts deleted since are not restored.
+func (d *DB) UndoMerge(id int64) (*Merge, error) {
+	m, err := scanMerge(d.db.QueryRow(`SELECT `+mergeColumns+` FROM merges WHERE id = ?`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("merge %d: %w", id, ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading merge %d: %w", id, err)
+	}
+	if !m.UndoneAt.IsZero() {
+		return nil, fmt.Errorf("merge %d was already undone", id)
+	}
+
+	var data string
+	if err := d.db.QueryRow(`SELECT snapshot FROM merges WHERE id = ?`, id).Scan(&data); err != nil {
+		return nil, fmt.Errorf("reading merge %d: %w", id, err)
+	}
+	var snapshot mergeSnapshot
+	if err := json.Unmarshal([]byte(data), &snapshot); err != nil {
+		return nil, fmt.Errorf("reading merge %d: %w", id, err)
+	}
+
+	// A later merge of any concept the snapshot refers to has moved or
+	// removed rows the snapshot would put back
+	involved := map[string]bool{m.KeptID: true, m.MergedID: true}
+	for _, e := range snapshot.Edges {
+		involved[e.SourceID], involved[e.TargetID] = true, true
+	}
+	var concepts []interface{}
+	for concept := range involved {
+		concepts = append(concepts, concept)
+	}
+	args := append([]interface{}{id}, concepts...)
+	args = append(args, concepts...)
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(concepts)), ", ")
+	var later int64
+	err = d.db.QueryRow(`
+		SELECT id FROM merges
+		WHERE id > ? AND undone_at IS NULL
+		  AND (kept_id IN (`+placeholders+`) OR merged_id IN (`+placeholders+`))
+		ORDER BY id DESC LIMIT 1`, args...).Scan(&later)
+	switch {
+	case err == nil:
+		return nil, fmt.Errorf("merge %d: undo merge %d first", id, later)
+	case !errors.Is(err, sql.ErrNoRows):
+		return nil, fmt.Errorf("reading merges: %w", err)
+	}
+	_, err = d.Concept(m.MergedID)
+	if err == nil {
+		return nil, fmt.Errorf("merge %d: concept %s exists again", id, m.MergedID)
+	}
+	if !errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+
+	tx, err := d.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("undoing merge %d: %w", id, err)
+	}
+	defer tx.Rollback() // No-op after Commit
+
+	// Edges re-pointed by the merge keep their creation time, so anything
+	// newer was added later
+	if _, err := tx.Exec(`DELETE FROM edges WHERE (source_id = ? OR target_id = ?) AND created <= ?`,
+		m.KeptID, m.KeptID, formatTime(m.MergedAt)); err != nil {
+		return nil, fmt.Errorf("undoing merge %d: %w", id, err)
+	}
+	if _, err := tx.Exec(`DELETE FROM aliases WHERE canonical_id = ?`, m.KeptID); err != nil {
+		return nil, fmt.Errorf("undoing merge %d: %w", id, err)
+	}
+	for _, c := range snapshot.Concepts {
+		if err := putConcept(tx, c); err != nil {
+			return nil, err
+		}
+	}
+	for _, e := range snapshot.Edges {
+		var exists bool
+		err := tx.QueryRow(`
+			SELECT EXISTS (SELECT 1 FROM concepts WHERE id = ?)
+			   AND EXISTS (SELECT 1 FROM concepts WHERE id = ?)`, e.SourceID, e.TargetID).Scan(&exists)
+		if err != nil {
+			return nil, fmt.Errorf("undoing merge %d: %w", id, err)
+		}
+		if !exists {
+			continue // A neighbour deleted since
+		}
+		if err := putEdge(tx, e); err != nil {
+			return nil, err
+		}
+	}
+	for _, a := range snapshot.Aliases {
+		if err := putAlias(tx, a); err != nil {
+			return nil, err
+		}
+	}
+
+	m.UndoneAt = time.Now().UTC().Truncate(time.Second)
+	if _, err := tx.Exec(`UPDATE merges SET undone_at = ? WHERE id = ?`, formatTime(m.UndoneAt), id); err != nil {
+		return nil, fmt.Errorf("undoing merge %d: %w", id, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("undoing merge %d: %w", id, err)
+	}
+	return m, nil
+}
@@ -0,0 +1,77 @@
+package kg
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Merges undone out of order must not leave edges to merged concepts or
+// lose edges of their neighbours
+func TestUndoMergeOutOfOrder(t *testing.T) {
+	d, err := Open(filepath.Join(t.TempDir(), "kg.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close()
+
+	created := time.Now().UTC().Add(-time.Hour).Truncate(time.Second)
+	for _, id := range []string{"A", "B", "Y", "Z"} {
+		if err := d.PutConcept(&Concept{ID: id, Name: id, Weight: 1, Created: created, Updated: created}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, e := range [][2]string{{"A", "Z"}, {"B", "Y"}} {
+		if err := d.PutEdge(&Edge{SourceID: e[0], TargetID: e[1], Type: "related", Strength: 1, Created: created}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := graphState(t, d)
+
+	first, err := d.MergeConcepts("A", "B", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := d.MergeConcepts("Y", "Z", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The first merge's edge A-Z now belongs to Y
+	if _, err := d.UndoMerge(first.ID); err == nil {
+		t.Fatalf("undoing merge %d before merge %d: want an error", first.ID, second.ID)
+	}
+	if _, err := d.UndoMerge(second.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := d.UndoMerge(first.ID); err != nil {
+		t.Fatal(err)
+	}
+	if got := graphState(t, d); got != want {
+		t.Errorf("graph after undoing both merges:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+// graphState lists the concepts and edges of d, one per line
+func graphState(t *testing.T, d *DB) string {
+	t.Helper()
+	concepts, err := d.Concepts(ConceptFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	edges, err := d.Edges(EdgeFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var lines []string
+	for _, c := range concepts {
+		lines = append(lines, "concept "+c.ID)
+	}
+	for _, e := range edges {
+		lines = append(lines, "edge "+e.SourceID+"-"+e.TargetID)
+	}
+	sort.Strings(lines)
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,116 @@
+package kg
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Migrations are applied in file name order and tracked in PRAGMA
+// user_version. 001 is tools/kg/schema.sql, which run-distillation --init
+// loads; keep the two identical.
+//
+//go:generate cp ../../../tools/kg/schema.sql migrations/001_schema.sql
+//go:embed migrations/*.sql
+var migrations embed.FS
+
+// migration is one numbered schema change
+type migration struct {
+	version int
+	name    string
+	sql     string
+}
+
+func loadMigrations() ([]migration, error) {
+	files, err := fs.Glob(migrations, "migrations/*.sql")
+	if err != nil {
+		return nil, err
+	}
+	var list []migration
+	for _, file := range files {
+		name := path.Base(file)
+		prefix, _, _ := strings.Cut(name, "_")
+		version, err := strconv.Atoi(prefix)
+		if err != nil {
+			return nil, fmt.Errorf("migration %s: name must start with a number", name)
+		}
+		data, err := migrations.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, migration{version: version, name: name, sql: string(data)})
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].version < list[j].version })
+	return list, nil
+}
+
+// SchemaVersion is the version Migrate brings a database to
+func SchemaVersion() int {
+	list, err := loadMigrations()
+	if err != nil || len(list) == 0 {
+		return 0
+	}
+	return list[len(list)-1].version
+}
+
+// Migrate applies the migrations the database hasn't seen yet, each in its
+// own transaction
+func (d *DB) Migrate() error {
+	list, err := loadMigrations()
+	if err != nil {
+		return fmt.Errorf("loading migrations: %w", err)
+	}
+
+	var current int
+	if err := d.db.QueryRow("PRAGMA user_version").Scan(&current); err != nil {
+		return fmt.Errorf("reading schema version: %w", err)
+	}
+	if current == 0 {
+		// Databases created by run-distillation --init have the schema
+		// but no version
+		var tables int
+		if err := d.db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'concepts'`).Scan(&tables); err != nil {
+			return fmt.Errorf("reading schema: %w", err)
+		}
+		if tables > 0 {
+			current = 1
+		}
+	}
+
+	for _, m := range list {
+		if m.version <= current {
+			continue
+		}
+		if err := d.apply(m); err != nil {
+			return fmt.Errorf("applying migration %s: %w", m.name, err)
+		}
+		current = m.version
+	}
+
+	// Record the baseline of an unversioned database too
+	if _, err := d.db.Exec(fmt.Sprintf("PRAGMA user_version = %d", current)); err != nil {
+		return fmt.Errorf("recording schema version: %w", err)
+	}
+	return nil
+}
+
+func (d *DB) apply(m migration) error {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() // No-op after Commit
+
+	if _, err := tx.Exec(m.sql); err != nil {
+		return err
+	}
+	// PRAGMA can't take parameters; version is an int we parsed
+	if _, err := tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", m.version)); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
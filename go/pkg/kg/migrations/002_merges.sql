@@ -0,0 +1,15 @@
+-- Audit trail of concept merges made by the deduplicator
+
+CREATE TABLE merges (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    kept_id TEXT NOT NULL,        -- concept that absorbed the other
+    merged_id TEXT NOT NULL,      -- concept that was removed
+    merged_name TEXT NOT NULL,
+    score REAL,                   -- similarity that proposed the merge, NULL if chosen by hand
+    merged_at TEXT NOT NULL,
+    undone_at TEXT,
+    snapshot TEXT NOT NULL        -- JSON of both concepts, their aliases and edges before the merge
+);
+
+CREATE INDEX idx_merges_kept ON merges(kept_id);
+CREATE INDEX idx_merges_merged ON merges(merged_id);
@@ -0,0 +1,127 @@
+package kg
+
+import (
+	"container/heap"
+	"fmt"
+	"math"
+)
+
+// ShortestPath finds a path with the fewest edges between two concepts
+func (g *Graph) ShortestPath(fromID, toID string) (*Route, error) {
+	from, to, err := g.ends(fromID, toID)
+	if err != nil {
+		return nil, err
+	}
+
+	prev := make([]*arc, len(g.Concepts))
+	seen := make([]bool, len(g.Concepts))
+	seen[from] = true
+	queue := []int{from}
+	for len(queue) > 0 && !seen[to] {
+		n := queue[0]
+		queue = queue[1:]
+		for _, a := range g.neighbours(n) {
+			if seen[a.to] {
+				continue
+			}
+			seen[a.to] = true
+			prev[a.to] = &arc{to: n, edge: a.edge}
+			queue = append(queue, a.to)
+		}
+	}
+	if !seen[to] {
+		return nil, fmt.Errorf("%s to %s: %w", fromID, toID, ErrNoPath)
+	}
+	return g.path(from, to, prev), nil
+}
+
+// StrongestPath finds the path whose edge strengths have the largest
+// product, so one weak link costs more than several strong ones
+func (g *Graph) StrongestPath(fromID, toID string) (*Route, error) {
+	from, to, err := g.ends(fromID, toID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Dijkstra over -log(strength); strengths above 1 count as 1 and
+	// edges of strength 0 are never taken
+	cost := make([]float64, len(g.Concepts))
+	for i := range cost {
+		cost[i] = math.Inf(1)
+	}
+	prev := make([]*arc, len(g.Concepts))
+	done := make([]bool, len(g.Concepts))
+	cost[from] = 0
+	pending := &nodeQueue{{node: from}}
+	for pending.Len() > 0 {
+		n := heap.Pop(pending).(queued).node
+		if done[n] {
+			continue
+		}
+		done[n] = true
+		if n == to {
+			break
+		}
+		for _, a := range g.neighbours(n) {
+			if done[a.to] || a.edge.Strength <= 0 {
+				continue
+			}
+			c := cost[n] - math.Log(math.Min(a.edge.Strength, 1))
+			if c < cost[a.to] {
+				cost[a.to] = c
+				prev[a.to] = &arc{to: n, edge: a.edge}
+				heap.Push(pending, queued{node: a.to, cost: c})
+			}
+		}
+	}
+	if !done[to] {
+		return nil, fmt.Errorf("%s to %s: %w", fromID, toID, ErrNoPath)
+	}
+	return g.path(from, to, prev), nil
+}
+
+func (g *Graph) ends(fromID, toID string) (int, int, error) {
+	from, err := g.node(fromID)
+	if err != nil {
+		return 0, 0, err
+	}
+	to, err := g.node(toID)
+	if err != nil {
+		return 0, 0, err
+	}
+	return from, to, nil
+}
+
+// path follows prev links back from to, where prev[n] holds the node
+// before n and the edge between them
+func (g *Graph) path(from, to int, prev []*arc) *Route {
+	p := &Route{Strength: 1}
+	for n := to; ; n = prev[n].to {
+		p.Concepts = append([]*Concept{g.Concepts[n]}, p.Concepts...)
+		if n == from {
+			break
+		}
+		p.Edges = append([]*Edge{prev[n].edge}, p.Edges...)
+		p.Strength *= prev[n].edge.Strength
+	}
+	return p
+}
+
+// nodeQueue is a min-heap of nodes by cost
+type nodeQueue []queued
+
+type queued struct {
+	node int
+	cost float64
+}
+
+func (q nodeQueue) Len() int            { return len(q) }
+func (q nodeQueue) Less(i, j int) bool  { return q[i].cost < q[j].cost }
+func (q nodeQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *nodeQueue) Push(x interface{}) { *q = append(*q, x.(queued)) }
+func (q *nodeQueue) Pop() interface{} {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return item
+}
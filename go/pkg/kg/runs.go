@@ -0,0 +1,131 @@
+package kg
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Distillation run statuses
+const (
+	RunRunning   = "running"
+	RunCompleted = "completed"
+	RunFailed    = "failed"
+)
+
+// DistillationRun is a row of the distillation_runs table
+type DistillationRun struct {
+	ID                int64
+	StartedAt         time.Time
+	CompletedAt       time.Time // Zero while running
+	EventsProcessed   int
+	ConceptsExtracted int
+	EdgesCreated      int
+	Status            string
+}
+
+const runColumns = `id, started_at, COALESCE(completed_at, ''), COALESCE(events_processed, 0),
+	COALESCE(concepts_extracted, 0), COALESCE(edges_created, 0), COALESCE(status, 'running')`
+
+func scanRun(row scanner) (*DistillationRun, error) {
+	var r DistillationRun
+	var started, completed string
+	if err := row.Scan(&r.ID, &started, &completed, &r.EventsProcessed, &r.ConceptsExtracted, &r.EdgesCreated, &r.Status); err != nil {
+		return nil, err
+	}
+	r.StartedAt, r.CompletedAt = parseTime(started), parseTime(completed)
+	return &r, nil
+}
+
+// StartRun records a new running distillation and sets its ID.
+// run-distillation uses the Unix start time as the ID; a zero ID does the
+// same.
+func (d *DB) StartRun(r *DistillationRun) error {
+	if r.StartedAt.IsZero() {
+		r.StartedAt = time.Now().UTC().Truncate(time.Second)
+	}
+	if r.ID == 0 {
+		r.ID = r.StartedAt.Unix()
+	}
+	if r.Status == "" {
+		r.Status = RunRunning
+	}
+	_, err := d.db.Exec(`
+		INSERT INTO distillation_runs (id, started_at, completed_at, events_processed, concepts_extracted, edges_created, status)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		r.ID, formatTime(r.StartedAt), nullTime(r.CompletedAt), r.EventsProcessed, r.ConceptsExtracted, r.EdgesCreated, r.Status)
+	if err != nil {
+		return fmt.Errorf("recording distillation run: %w", err)
+	}
+	return nil
+}
+
+// UpdateRun saves the counts and status of a run
+func (d *DB) UpdateRun(r *DistillationRun) error {
+	err := checkAffected(d.db.Exec(`
+		UPDATE distillation_runs
+		SET completed_at = ?, events_processed = ?, concepts_extracted = ?, edges_created = ?, status = ?
+		WHERE id = ?`,
+		nullTime(r.CompletedAt), r.EventsProcessed, r.ConceptsExtracted, r.EdgesCreated, r.Status, r.ID))
+	if err != nil {
+		return fmt.Errorf("updating distillation run %d: %w", r.ID, err)
+	}
+	return nil
+}
+
+// FinishRun marks a run completed or failed now
+func (d *DB) FinishRun(r *DistillationRun, status string) error {
+	r.Status = status
+	r.CompletedAt = time.Now().UTC().Truncate(time.Second)
+	return d.UpdateRun(r)
+}
+
+// Run reads one distillation run
+func (d *DB) Run(id int64) (*DistillationRun, error) {
+	r, err := scanRun(d.db.QueryRow(`SELECT `+runColumns+` FROM distillation_runs WHERE id = ?`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("distillation run %d: %w", id, ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading distillation run %d: %w", id, err)
+	}
+	return r, nil
+}
+
+// Runs lists the most recent runs first; limit 0 lists all
+func (d *DB) Runs(limit int) ([]*DistillationRun, error) {
+	query := `SELECT ` + runColumns + ` FROM distillation_runs ORDER BY started_at DESC, id DESC`
+	var args []interface{}
+	if limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, limit)
+	}
+
+	rows, err := d.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("listing distillation runs: %w", err)
+	}
+	defer rows.Close()
+
+	var runs []*DistillationRun
+	for rows.Next() {
+		r, err := scanRun(rows)
+		if err != nil {
+			return nil, fmt.Errorf("listing distillation runs: %w", err)
+		}
+		runs = append(runs, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("listing distillation runs: %w", err)
+	}
+	return runs, nil
+}
+
+// DeleteRun removes a run record
+func (d *DB) DeleteRun(id int64) error {
+	if err := checkAffected(d.db.Exec(`DELETE FROM distillation_runs WHERE id = ?`, id)); err != nil {
+		return fmt.Errorf("deleting distillation run %d: %w", id, err)
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package logex
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/durapensa/ks/pkg/config"
+	"github.com/durapensa/ks/pkg/events"
+)
+
+// Defaults of the KS_CONVERSATION_* layout in .ks-env
+const (
+	DefaultConfigFile       = "logex-config.yaml"
+	DefaultHotLog           = "knowledge/events/hot.jsonl"
+	DefaultOrchestrationLog = "supervise/orchestration.jsonl"
+)
+
+// StopSignal is the file orchestrate-worker checks for a manual stop,
+// relative to the conversation directory
+const StopSignal = "supervise/stop_signal"
+
+// IdleAfter is how long a started conversation may go without logging
+// before it is reported idle rather than running
+const IdleAfter = 10 * time.Minute
+
+// Layout locates a conversation's files from the KS_CONVERSATION_* paths
+type Layout struct {
+	ConfigFile       string
+	HotLog           string
+	OrchestrationLog string
+}
+
+// NewLayout reads the conversation layout from cfg, falling back to the
+// .ks-env defaults
+func NewLayout(cfg *config.Config) Layout {
+	l := Layout{
+		ConfigFile:       cfg.ConversationConfig,
+		HotLog:           cfg.ConversationHotLog,
+		OrchestrationLog: cfg.ConversationOrchestrationLog,
+	}
+	if l.ConfigFile == "" {
+		l.ConfigFile = DefaultConfigFile
+	}
+	if l.HotLog == "" {
+		l.HotLog = DefaultHotLog
+	}
+	if l.OrchestrationLog == "" {
+		l.OrchestrationLog = DefaultOrchestrationLog
+	}
+	return l
+}
+
+// Experiment is the progress of one logex conversation
+type Experiment struct {
+	Name string
+	Dir  string
+
+	Config    *config.ConversationConfig
+	ConfigErr error
+
+	// Events is the number of knowledge events in the conversation hot log
+	Events int
+
+	// Orchestration log status
+	LogEvents  int
+	LogSkipped int
+	LogErr     error
+	Progress
+
+	TurnLimit int
+
+	// StopRequested is set while a stop signal for the latest run is present
+	StopRequested bool
+}
+
+// State summarises the experiment as not started, running, idle or ended
+func (e *Experiment) State(now time.Time) string {
+	switch {
+	case e.Ended:
+		return "ended"
+	case !e.Started:
+		return "not started"
+	case !e.LastActivity.IsZero() && now.Sub(e.LastActivity) > IdleAfter:
+		return "idle"
+	}
+	return "running"
+}
+
+// Discover finds every directory under KS_EXPERIMENTS_DIR that holds a
+// logex-config.yaml, sorted by name
+func Discover(cfg *config.Config) ([]*Experiment, error) {
+	if cfg.ExperimentsDir == "" {
+		return nil, fmt.Errorf("KS_EXPERIMENTS_DIR is not set")
+	}
+	layout := NewLayout(cfg)
+
+	configs, err := filepath.Glob(filepath.Join(cfg.ExperimentsDir, "*", layout.ConfigFile))
+	if err != nil {
+		return nil, fmt.Errorf("listing experiments: %w", err)
+	}
+	sort.Strings(configs)
+
+	var experiments []*Experiment
+	for _, path := range configs {
+		experiments = append(experiments, Load(layout, filepath.Dir(path)))
+	}
+	return experiments, nil
+}
+
+// Load reads the configuration and logs of the conversation in dir.
+// Problems are recorded on the experiment so one broken conversation does
+// not hide the others.
+func Load(layout Layout, dir string) *Experiment {
+	e := &Experiment{Name: filepath.Base(dir), Dir: dir}
+
+	e.Config, e.ConfigErr = config.LoadConversationConfig(filepath.Join(dir, layout.ConfigFile))
+	if e.Config != nil {
+		e.TurnLimit = e.Config.TurnLimit()
+	}
+
+	e.Events, _ = events.CountLines(filepath.Join(dir, layout.HotLog))
+
+	log, skipped, err := ReadLog(filepath.Join(dir, layout.OrchestrationLog))
+	e.LogEvents, e.LogSkipped, e.LogErr = len(log), skipped, err
+	for _, event := range log {
+		e.Apply(event)
+	}
+	e.StopRequested = StopPending(dir, e.StartedAt)
+	return e
+}
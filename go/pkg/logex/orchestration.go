@@ -0,0 +1,128 @@
+package logex
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// Event types written to supervise/orchestration.jsonl. The orchestrator
+// records conversation and turn events; claude-instance records the
+// conversant events.
+const (
+	ConversationStarted = "conversation_started"
+	ConversationEnded   = "conversation_ended"
+	TurnStarted         = "turn_started"
+	TurnCompleted       = "turn_completed"
+
+	SessionStarted        = "session_started"
+	SessionCompleted      = "session_completed"
+	SessionFailed         = "session_failed"
+	SessionInterrupted    = "session_interrupted"
+	ResponseGenerated     = "response_generated"
+	FallbackResponse      = "fallback_response"
+	ToolExecuted          = "tool_executed"
+	ToolFailed            = "tool_failed"
+	ToolsParsed           = "tools_parsed"
+	KnowledgeCaptured     = "knowledge_captured"
+	MainKnowledgeCaptured = "main_knowledge_captured"
+)
+
+// maxLineSize bounds one log line; responses can be long
+const maxLineSize = 1024 * 1024
+
+// Event is a line of the orchestration log. Orchestrator events carry
+// Details, Turn and Speaker; conversant events carry Conversant and Content.
+type Event struct {
+	Timestamp  string `json:"timestamp"`
+	Type       string `json:"type"`
+	Details    string `json:"details,omitempty"`
+	Turn       int    `json:"turn"`
+	Speaker    string `json:"speaker,omitempty"`
+	Conversant string `json:"conversant,omitempty"`
+	Content    string `json:"content,omitempty"`
+}
+
+// Time parses the event timestamp
+func (e *Event) Time() (time.Time, error) {
+	return time.Parse(time.RFC3339, e.Timestamp)
+}
+
+// Who is the conversant an event belongs to, if any
+func (e *Event) Who() string {
+	if e.Conversant != "" {
+		return e.Conversant
+	}
+	return e.Speaker
+}
+
+// The orchestrator builds its JSON by string interpolation, so details
+// containing quotes or newlines produce invalid lines. Those still end with
+// the fields it controls.
+var (
+	looseTimestamp = regexp.MustCompile(`^\{"timestamp":"([^"]*)"`)
+	looseType      = regexp.MustCompile(`^\{"timestamp":"[^"]*","type":"([a-z_]+)"`)
+	looseTail      = regexp.MustCompile(`"turn":(\d+),"speaker":"([^"]*)"\}\s*$`)
+	looseDetails   = regexp.MustCompile(`(?s)"details":"(.*)","turn":\d+,"speaker"`)
+)
+
+// ParseEvent parses one log line, recovering the orchestrator's fields from
+// lines whose details broke the JSON
+func ParseEvent(line []byte) (*Event, error) {
+	var event Event
+	err := json.Unmarshal(line, &event)
+	if err == nil {
+		return &event, nil
+	}
+
+	ts := looseTimestamp.FindSubmatch(line)
+	typ := looseType.FindSubmatch(line)
+	tail := looseTail.FindSubmatch(line)
+	if ts == nil || typ == nil || tail == nil {
+		return nil, fmt.Errorf("parsing orchestration event: %w", err)
+	}
+	event.Timestamp = string(ts[1])
+	event.Type = string(typ[1])
+	event.Turn, _ = strconv.Atoi(string(tail[1]))
+	event.Speaker = string(tail[2])
+	if details := looseDetails.FindSubmatch(line); details != nil {
+		event.Details = string(details[1])
+	}
+	return &event, nil
+}
+
+// ReadLog reads an orchestration log. A missing log has no events; lines
+// that cannot be parsed at all are counted in skipped.
+func ReadLog(path string) (events []*Event, skipped int, err error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, 0, nil
+	}
+	if err != nil {
+		return nil, 0, fmt.Errorf("opening orchestration log: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		event, err := ParseEvent(line)
+		if err != nil {
+			skipped++
+			continue
+		}
+		events = append(events, event)
+	}
+	if err := scanner.Err(); err != nil {
+		return events, skipped, fmt.Errorf("reading orchestration log: %w", err)
+	}
+	return events, skipped, nil
+}
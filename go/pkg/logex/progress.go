@@ -0,0 +1,99 @@
+package logex
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Progress is what the orchestration log says about a conversation so far
+type Progress struct {
+	Turns        int
+	LastSpeaker  string
+	Failures     int
+	Started      bool
+	StartedAt    time.Time // When the latest run logged conversation_started
+	Ended        bool
+	LastEvent    *Event
+	LastActivity time.Time
+}
+
+// Apply folds the next orchestration event into the progress
+func (p *Progress) Apply(event *Event) {
+	p.LastEvent = event
+	if t, err := event.Time(); err == nil {
+		p.LastActivity = t
+	}
+
+	switch event.Type {
+	case ConversationStarted:
+		// A rerun in the same directory starts over
+		*p = Progress{Started: true, LastEvent: p.LastEvent, LastActivity: p.LastActivity}
+		p.StartedAt, _ = event.Time()
+	case ConversationEnded:
+		p.Ended = true
+	case TurnStarted, TurnCompleted:
+		p.Started = true
+		p.LastSpeaker = event.Speaker
+		if event.Type == TurnCompleted {
+			p.Turns = event.Turn + 1
+		}
+	case ResponseGenerated, FallbackResponse:
+		p.LastSpeaker = event.Conversant
+	case SessionFailed, ToolFailed:
+		p.Failures++
+	}
+}
+
+// StopRequested reports whether a manual stop signal is present in dir.
+// orchestrate-worker only checks for it and never removes it, so a signal
+// outlives the run it stopped unless ClearStaleStop or ClearStop is called.
+func StopRequested(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, StopSignal))
+	return err == nil
+}
+
+// StopPending reports whether a stop signal for the run started at
+// startedAt is present, ignoring one left by an earlier run. Unlike
+// ClearStaleStop it changes nothing on disk.
+func StopPending(dir string, startedAt time.Time) bool {
+	stat, err := os.Stat(filepath.Join(dir, StopSignal))
+	return err == nil && !stat.ModTime().Before(startedAt)
+}
+
+// ClearStaleStop removes a stop signal written before startedAt, the start
+// of the latest run, so a rerun isn't stopped after its first turn
+func ClearStaleStop(dir string, startedAt time.Time) error {
+	if startedAt.IsZero() {
+		return nil
+	}
+	stat, err := os.Stat(filepath.Join(dir, StopSignal))
+	if err != nil || !stat.ModTime().Before(startedAt) {
+		return nil
+	}
+	return ClearStop(dir)
+}
+
+// ClearStop withdraws a stop signal
+func ClearStop(dir string) error {
+	err := os.Remove(filepath.Join(dir, StopSignal))
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("removing stop signal: %w", err)
+	}
+	return nil
+}
+
+// RequestStop writes the stop signal orchestrate-worker checks after each
+// turn. It only takes effect when exit_conditions.manual_stop is set.
+func RequestStop(dir string) error {
+	path := filepath.Join(dir, StopSignal)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("creating supervise directory: %w", err)
+	}
+	content := time.Now().UTC().Format(time.RFC3339) + "\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		return fmt.Errorf("writing stop signal: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+package logex
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/durapensa/ks/pkg/fsutil"
+)
+
+// Tail reads an orchestration log incrementally, returning only lines
+// appended since the previous read
+type Tail struct {
+	Path string
+
+	inode   uint64
+	offset  int64
+	partial []byte
+}
+
+// NewTail starts reading path from the beginning
+func NewTail(path string) *Tail {
+	return &Tail{Path: path}
+}
+
+// Read returns the events appended since the last call. reset is true when
+// the log was truncated or replaced and the events start over from the
+// beginning of the new file.
+func (t *Tail) Read() (events []*Event, reset bool, skipped int, err error) {
+	file, err := os.Open(t.Path)
+	if os.IsNotExist(err) {
+		return nil, false, 0, nil
+	}
+	if err != nil {
+		return nil, false, 0, fmt.Errorf("opening orchestration log: %w", err)
+	}
+	defer file.Close()
+
+	stat, err := file.Stat()
+	if err != nil {
+		return nil, false, 0, fmt.Errorf("reading orchestration log: %w", err)
+	}
+	if inode := fsutil.Inode(stat); inode != t.inode || stat.Size() < t.offset {
+		reset = t.offset > 0
+		t.inode, t.offset, t.partial = inode, 0, nil
+	}
+	if stat.Size() == t.offset {
+		return nil, reset, 0, nil
+	}
+
+	if _, err := file.Seek(t.offset, io.SeekStart); err != nil {
+		return nil, reset, 0, fmt.Errorf("reading orchestration log: %w", err)
+	}
+	data, err := io.ReadAll(io.LimitReader(file, stat.Size()-t.offset))
+	if err != nil {
+		return nil, reset, 0, fmt.Errorf("reading orchestration log: %w", err)
+	}
+	t.offset += int64(len(data))
+
+	// Keep an unterminated last line for the next read
+	data = append(t.partial, data...)
+	end := bytes.LastIndexByte(data, '\n')
+	if end < 0 {
+		t.partial = data
+		return nil, reset, 0, nil
+	}
+	t.partial = append([]byte(nil), data[end+1:]...)
+
+	for _, line := range bytes.Split(data[:end], []byte("\n")) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		event, err := ParseEvent(line)
+		if err != nil {
+			skipped++
+			continue
+		}
+		events = append(events, event)
+	}
+	return events, reset, skipped, nil
+}
+
+// EntryKind classifies transcript entries
+type EntryKind int
+
+const (
+	// EntryMessage is a conversant's response
+	EntryMessage EntryKind = iota
+	// EntryTools groups the tool activity that followed a response
+	EntryTools
+	// EntryFailure is a failed or interrupted session
+	EntryFailure
+	// EntrySystem is a conversation or turn marker
+	EntrySystem
+)
+
+// Entry is one block of a conversation transcript
+type Entry struct {
+	Kind EntryKind
+	Who  string
+	Time time.Time
+	Text string
+
+	// Fallback marks a response the instance made up after a failure
+	Fallback bool
+	// Tools holds the tool_* and knowledge events of an EntryTools block
+	Tools []*Event
+	// Turn is the completed turn count of a turn marker
+	Turn int
+}
+
+// Failed counts the failed tools in an EntryTools block
+func (e *Entry) Failed() int {
+	failed := 0
+	for _, tool := range e.Tools {
+		if tool.Type == ToolFailed {
+			failed++
+		}
+	}
+	return failed
+}
+
+// Transcript turns orchestration events into a chat-style conversation
+type Transcript struct {
+	Entries []*Entry
+	Progress
+}
+
+// Add appends the next orchestration event
+func (t *Transcript) Add(event *Event) {
+	t.Apply(event)
+	ts, _ := event.Time()
+
+	switch event.Type {
+	case ConversationStarted:
+		t.add(&Entry{Kind: EntrySystem, Time: ts, Text: "Conversation started: " + event.Details})
+	case ConversationEnded:
+		// The worker records the end twice on a normal exit
+		if last := t.last(); last != nil && last.Kind == EntrySystem && strings.HasPrefix(last.Text, "Conversation ended") {
+			return
+		}
+		t.add(&Entry{Kind: EntrySystem, Time: ts, Text: "Conversation ended: " + event.Details})
+	case TurnCompleted:
+		t.add(&Entry{Kind: EntrySystem, Time: ts, Who: event.Speaker, Turn: t.Turns, Text: "Turn completed"})
+	case ResponseGenerated, FallbackResponse:
+		t.add(&Entry{
+			Kind:     EntryMessage,
+			Who:      event.Conversant,
+			Time:     ts,
+			Text:     strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(event.Content), "END_SESSION")),
+			Fallback: event.Type == FallbackResponse,
+		})
+	case ToolExecuted, ToolFailed, ToolsParsed, KnowledgeCaptured, MainKnowledgeCaptured:
+		// Tool activity of one session collects into a single block
+		if last := t.last(); last != nil && last.Kind == EntryTools && last.Who == event.Conversant {
+			last.Tools = append(last.Tools, event)
+			return
+		}
+		t.add(&Entry{Kind: EntryTools, Who: event.Conversant, Time: ts, Tools: []*Event{event}})
+	case SessionFailed, SessionInterrupted:
+		t.add(&Entry{Kind: EntryFailure, Who: event.Conversant, Time: ts, Text: event.Type + ": " + event.Content})
+	}
+}
+
+func (t *Transcript) add(entry *Entry) {
+	t.Entries = append(t.Entries, entry)
+}
+
+func (t *Transcript) last() *Entry {
+	if len(t.Entries) == 0 {
+		return nil
+	}
+	return t.Entries[len(t.Entries)-1]
+}
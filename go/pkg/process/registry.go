@@ -0,0 +1,280 @@
+package process
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/durapensa/ks/pkg/fsutil"
+)
+
+// StaleTimeout matches ks_cleanup_stale_processes
+const StaleTimeout = 30 * time.Minute
+
+// ErrStale is returned by Kill for a running record whose process is gone
+var ErrStale = errors.New("stale record; the process is no longer running")
+
+// Registry subdirectories under KS_PROCESS_REGISTRY. ks_ensure_dirs creates
+// "completed" while ks_complete_background_process writes to "success", so
+// both are read as successful runs.
+const (
+	StatusRunning = "running"
+	StatusSuccess = "success"
+	StatusFailed  = "failed"
+
+	activeDir    = "active"
+	completedDir = "completed"
+)
+
+// Process is a record written by ks_register_background_process
+type Process struct {
+	Task        string `json:"task"`
+	PID         int    `json:"pid"`
+	StartTime   string `json:"start_time"`
+	StartEpoch  int64  `json:"start_epoch"`
+	Description string `json:"description"`
+	Status      string `json:"status"`
+	EndTime     string `json:"end_time,omitempty"`
+	EndEpoch    int64  `json:"end_epoch,omitempty"`
+	OutputFile  string `json:"output_file,omitempty"`
+
+	// File is the registry file the record was read from
+	File string `json:"-"`
+}
+
+// Alive reports whether the process still exists (kill -0)
+func (p *Process) Alive() bool {
+	if p.PID <= 0 {
+		return false
+	}
+	err := syscall.Kill(p.PID, 0)
+	return err == nil || err == syscall.EPERM
+}
+
+// Age is how long ago the process started
+func (p *Process) Age(now time.Time) time.Duration {
+	if p.StartEpoch == 0 {
+		return 0
+	}
+	return now.Sub(time.Unix(p.StartEpoch, 0))
+}
+
+// Duration is the run time, or the time so far for running processes
+func (p *Process) Duration(now time.Time) time.Duration {
+	if p.EndEpoch > 0 && p.StartEpoch > 0 {
+		return time.Unix(p.EndEpoch, 0).Sub(time.Unix(p.StartEpoch, 0))
+	}
+	return p.Age(now)
+}
+
+// Stale reports whether a running entry is dead or older than StaleTimeout
+func (p *Process) Stale(now time.Time) bool {
+	if p.Status != StatusRunning {
+		return false
+	}
+	return !p.Alive() || p.Age(now) > StaleTimeout
+}
+
+// Registry reads and updates KS_PROCESS_REGISTRY
+type Registry struct {
+	dir string
+}
+
+// NewRegistry creates a registry rooted at KS_PROCESS_REGISTRY
+func NewRegistry(dir string) *Registry {
+	return &Registry{dir: dir}
+}
+
+// Active lists processes registered as running
+func (r *Registry) Active() ([]Process, error) {
+	return r.list(activeDir)
+}
+
+// Succeeded lists processes that completed successfully
+func (r *Registry) Succeeded() ([]Process, error) {
+	success, err := r.list(StatusSuccess)
+	if err != nil {
+		return nil, err
+	}
+	completed, err := r.list(completedDir)
+	if err != nil {
+		return nil, err
+	}
+	return sortByStart(append(success, completed...)), nil
+}
+
+// Failed lists processes that failed or were cleaned up as stale
+func (r *Registry) Failed() ([]Process, error) {
+	return r.list(StatusFailed)
+}
+
+// All lists active, successful and failed processes in that order
+func (r *Registry) All() ([]Process, error) {
+	var all []Process
+	for _, list := range []func() ([]Process, error){r.Active, r.Succeeded, r.Failed} {
+		processes, err := list()
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, processes...)
+	}
+	return all, nil
+}
+
+func (r *Registry) list(sub string) ([]Process, error) {
+	files, err := filepath.Glob(filepath.Join(r.dir, sub, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	var processes []Process
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			continue // Moved by a concurrent completion
+		}
+		var p Process
+		if err := json.Unmarshal(data, &p); err != nil {
+			continue
+		}
+		p.File = file
+		processes = append(processes, p)
+	}
+	return sortByStart(processes), nil
+}
+
+// sortByStart orders processes newest first
+func sortByStart(processes []Process) []Process {
+	sort.SliceStable(processes, func(i, j int) bool {
+		return processes[i].StartEpoch > processes[j].StartEpoch
+	})
+	return processes
+}
+
+// unsafeChars matches what ks_sanitize_string strips from task names
+var unsafeChars = regexp.MustCompile(`[^a-zA-Z0-9 _.:-]`)
+
+// Register records a running process in active/, like
+// ks_register_background_process
+func (r *Registry) Register(task string, pid int, description string) (*Process, error) {
+	now := time.Now()
+	p := &Process{
+		Task:        task,
+		PID:         pid,
+		StartTime:   now.UTC().Format("2006-01-02T15:04:05Z"),
+		StartEpoch:  now.Unix(),
+		Description: description,
+		Status:      StatusRunning,
+	}
+
+	safeName := strings.ReplaceAll(unsafeChars.ReplaceAllString(task, ""), " ", "_")
+	dir := filepath.Join(r.dir, activeDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("creating %s: %w", dir, err)
+	}
+	p.File = filepath.Join(dir, fmt.Sprintf("%s-%d.json", safeName, pid))
+
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("encoding process: %w", err)
+	}
+	if err := fsutil.WriteFileAtomic(p.File, append(data, '\n'), 0644); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Complete moves an active record to success/ or failed/ with end time and
+// output file, like ks_complete_background_process
+func (r *Registry) Complete(p *Process, status, outputFile string) error {
+	now := time.Now()
+	p.Status = status
+	p.EndTime = now.UTC().Format("2006-01-02T15:04:05Z")
+	p.EndEpoch = now.Unix()
+	p.OutputFile = outputFile
+
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding process: %w", err)
+	}
+
+	targetDir := filepath.Join(r.dir, status)
+	if err := os.MkdirAll(targetDir, 0755); err != nil {
+		return fmt.Errorf("creating %s: %w", targetDir, err)
+	}
+	target := filepath.Join(targetDir, filepath.Base(p.File))
+	if err := fsutil.WriteFileAtomic(target, append(data, '\n'), 0644); err != nil {
+		return err
+	}
+	if err := os.Remove(p.File); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("removing active record: %w", err)
+	}
+	p.File = target
+	return nil
+}
+
+// CleanupStale moves dead or timed-out active entries to failed/ and
+// returns how many were moved
+func (r *Registry) CleanupStale() (int, error) {
+	active, err := r.Active()
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	cleaned := 0
+	for i := range active {
+		if !active[i].Stale(now) {
+			continue
+		}
+		if err := r.Complete(&active[i], StatusFailed, ""); err != nil {
+			return cleaned, err
+		}
+		cleaned++
+	}
+	return cleaned, nil
+}
+
+// Kill terminates a running process (SIGTERM, then SIGKILL after a grace
+// period as monitor-background-processes does) and records it as failed.
+// Live processes are killed however long they have run; records whose
+// process is gone are refused with ErrStale and left to CleanupStale.
+func (r *Registry) Kill(p *Process) error {
+	if p.Status != StatusRunning {
+		return fmt.Errorf("%s (PID %d) is not running", p.Task, p.PID)
+	}
+	if !p.Alive() {
+		return fmt.Errorf("%s (PID %d): %w", p.Task, p.PID, ErrStale)
+	}
+
+	if err := syscall.Kill(p.PID, syscall.SIGTERM); err != nil && err != syscall.ESRCH {
+		return fmt.Errorf("killing %d: %w", p.PID, err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for p.Alive() && time.Now().Before(deadline) {
+		time.Sleep(100 * time.Millisecond)
+	}
+	if p.Alive() {
+		syscall.Kill(p.PID, syscall.SIGKILL)
+	}
+
+	return r.Complete(p, StatusFailed, p.OutputFile)
+}
+
+// Remove deletes a finished process record
+func (r *Registry) Remove(p *Process) error {
+	if p.Status == StatusRunning {
+		return fmt.Errorf("%s (PID %d) is still registered as running", p.Task, p.PID)
+	}
+	if err := os.Remove(p.File); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("removing %s: %w", p.File, err)
+	}
+	return nil
+}
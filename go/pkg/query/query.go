@@ -0,0 +1,237 @@
+package query
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/durapensa/ks/pkg/events"
+	"github.com/durapensa/ks/pkg/index"
+)
+
+// DefaultLimit matches the CAPTURE_SEARCH limit default
+const DefaultLimit = 20
+
+// Options mirrors the CAPTURE_SEARCH option set of tools/capture/query
+type Options struct {
+	Days    int       // Search last N days
+	Since   time.Time // Search since date (takes precedence over Days)
+	Until   time.Time // Only events before this time
+	Search  string    // Case-insensitive regex, matched against the whole event
+	Type    string    // Filter by event type
+	Topic   string    // Filter by topic
+	Limit   int       // Limit results (0 uses DefaultLimit, negative means no limit)
+	Reverse bool      // Show oldest first
+	Count   bool      // Only count matches
+}
+
+// Result holds the matching events and the total before limiting
+type Result struct {
+	Events []*events.Event
+	Total  int
+}
+
+// Engine searches the hot log and the cold-* archives
+type Engine struct {
+	HotLog     string
+	ArchiveDir string
+
+	// Index, when set, answers plain-word Search terms with BM25-ranked
+	// results instead of a linear regex scan. Searches using regex syntax
+	// and Reverse (oldest first) queries still scan, so they mean the same
+	// with or without an index.
+	Index *index.Index
+}
+
+// New creates a query engine over the given hot log and archive directory
+func New(hotLog, archiveDir string) *Engine {
+	return &Engine{
+		HotLog:     hotLog,
+		ArchiveDir: archiveDir,
+	}
+}
+
+// filterDate resolves Since/Days the way ks_get_filter_date does
+func (o Options) filterDate() time.Time {
+	if !o.Since.IsZero() {
+		return o.Since
+	}
+	if o.Days > 0 {
+		return time.Now().UTC().AddDate(0, 0, -o.Days)
+	}
+	return time.Time{}
+}
+
+// Files returns the logs that may contain events newer than since, like
+// ks_collect_files_since: the hot log first, then archives newest first
+func (e *Engine) Files(since time.Time) []string {
+	var files []string
+
+	if stat, err := os.Stat(e.HotLog); err == nil && stat.Size() > 0 && !stat.ModTime().Before(since) {
+		files = append(files, e.HotLog)
+	}
+
+	archives, _ := filepath.Glob(filepath.Join(e.ArchiveDir, "*.jsonl"))
+	sort.Sort(sort.Reverse(sort.StringSlice(archives)))
+	for _, archive := range archives {
+		if stat, err := os.Stat(archive); err == nil && stat.Size() > 0 && stat.ModTime().After(since) {
+			files = append(files, archive)
+		}
+	}
+
+	return files
+}
+
+// Run executes the query. Unreadable lines are skipped, as jq does in the
+// bash tool, so one corrupted archive doesn't hide everything else.
+func (e *Engine) Run(opts Options) (*Result, error) {
+	if e.ranked(opts) {
+		return e.runRanked(opts)
+	}
+
+	var pattern *regexp.Regexp
+	if opts.Search != "" {
+		var err error
+		pattern, err = regexp.Compile("(?i)" + opts.Search)
+		if err != nil {
+			// Not a valid regex: fall back to a literal match
+			pattern = regexp.MustCompile("(?i)" + regexp.QuoteMeta(opts.Search))
+		}
+	}
+
+	since := opts.filterDate()
+	result := &Result{}
+
+	for _, file := range e.Files(since) {
+		reader, err := events.NewReader(file)
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", file, err)
+		}
+		for {
+			event, err := reader.Next()
+			var parseErr *events.ParseError
+			if errors.As(err, &parseErr) {
+				continue
+			}
+			if err != nil {
+				reader.Close()
+				return nil, fmt.Errorf("reading %s: %w", file, err)
+			}
+			if event == nil {
+				break
+			}
+			if !matches(event, opts, since, pattern) {
+				continue
+			}
+			result.Total++
+			if !opts.Count {
+				result.Events = append(result.Events, event)
+			}
+		}
+		reader.Close()
+	}
+
+	// Timestamps are ISO-8601 UTC, so string order is chronological
+	sort.SliceStable(result.Events, func(i, j int) bool {
+		if opts.Reverse {
+			return result.Events[i].Timestamp < result.Events[j].Timestamp
+		}
+		return result.Events[i].Timestamp > result.Events[j].Timestamp
+	})
+
+	result.limit(opts.Limit)
+	return result, nil
+}
+
+// RankedSearch reports whether an index can answer search: plain words and
+// quoted phrases, with no regex syntax
+func RankedSearch(search string) bool {
+	return search != "" && regexp.QuoteMeta(search) == search
+}
+
+// ranked reports whether opts can be answered from the index without
+// changing their meaning
+func (e *Engine) ranked(opts Options) bool {
+	return e.Index != nil && !opts.Reverse && RankedSearch(opts.Search)
+}
+
+// runRanked answers the search from the inverted index, keeping BM25 order
+// and applying the remaining filters to each hit. The index is brought up
+// to date in memory; saving it is up to its owner.
+func (e *Engine) runRanked(opts Options) (*Result, error) {
+	if err := e.Index.Update(e.Files(time.Time{})); err != nil {
+		return nil, fmt.Errorf("updating index: %w", err)
+	}
+
+	since := opts.filterDate()
+	result := &Result{}
+
+	hits := e.Index.Search(opts.Search, 0)
+	docs := make([]index.Doc, len(hits))
+	for i, hit := range hits {
+		docs[i] = hit.Doc
+	}
+	for _, event := range index.Events(docs) {
+		if event == nil || !matches(event, opts, since, nil) {
+			continue
+		}
+		result.Total++
+		if !opts.Count {
+			result.Events = append(result.Events, event)
+		}
+	}
+
+	result.limit(opts.Limit)
+	return result, nil
+}
+
+// limit trims Events to n (0 uses DefaultLimit, negative keeps everything)
+func (r *Result) limit(n int) {
+	if n == 0 {
+		n = DefaultLimit
+	}
+	if n > 0 && len(r.Events) > n {
+		r.Events = r.Events[:n]
+	}
+}
+
+func matches(event *events.Event, opts Options, since time.Time, pattern *regexp.Regexp) bool {
+	if !since.IsZero() {
+		t, err := event.Time()
+		if err != nil || t.Before(since) {
+			return false
+		}
+	}
+	if !opts.Until.IsZero() {
+		t, err := event.Time()
+		if err != nil || !t.Before(opts.Until) {
+			return false
+		}
+	}
+	if opts.Type != "" && event.Type != opts.Type {
+		return false
+	}
+	if opts.Topic != "" && !hasTopic(event, opts.Topic) {
+		return false
+	}
+	if pattern != nil && !pattern.MatchString(event.Raw) {
+		return false
+	}
+	return true
+}
+
+// hasTopic checks the top-level topic and the older metadata.topic
+func hasTopic(event *events.Event, topic string) bool {
+	if strings.EqualFold(event.Topic, topic) {
+		return true
+	}
+	if value, ok := event.Metadata["topic"].(string); ok {
+		return strings.EqualFold(value, topic)
+	}
+	return false
+}
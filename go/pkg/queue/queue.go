@@ -0,0 +1,198 @@
+package queue
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/durapensa/ks/pkg/fsutil"
+)
+
+// StatusPendingReview marks an analysis whose findings await review
+const StatusPendingReview = "pending_review"
+
+// Entry is one analysis in analysis_queue.json
+type Entry struct {
+	Status       string `json:"status"`
+	FindingsFile string `json:"findings_file"`
+	CompletedAt  string `json:"completed_at"`
+
+	// Extra holds any other fields, which are written back untouched as
+	// jq would
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// entryFields are the keys Entry reads into its own fields
+var entryFields = []string{"status", "findings_file", "completed_at"}
+
+func (e *Entry) UnmarshalJSON(data []byte) error {
+	type known Entry
+	var k known
+	if err := json.Unmarshal(data, &k); err != nil {
+		return err
+	}
+	var extra map[string]json.RawMessage
+	if err := json.Unmarshal(data, &extra); err != nil {
+		return err
+	}
+	for _, key := range entryFields {
+		delete(extra, key)
+	}
+	if len(extra) == 0 {
+		extra = nil
+	}
+	*e = Entry(k)
+	e.Extra = extra
+	return nil
+}
+
+func (e Entry) MarshalJSON() ([]byte, error) {
+	out := map[string]interface{}{}
+	for key, value := range e.Extra {
+		out[key] = value
+	}
+	out["status"] = e.Status
+	out["findings_file"] = e.FindingsFile
+	out["completed_at"] = e.CompletedAt
+	return json.Marshal(out)
+}
+
+// Pending matches an element of ks_queue_list_pending's output
+type Pending struct {
+	Type  string `json:"type"`
+	Value Entry  `json:"value"`
+}
+
+// Queue reads and writes KS_ANALYSIS_QUEUE. Writes hold the same flock as
+// tools/lib/queue.sh and replace the file by atomic rename.
+type Queue struct {
+	path string
+}
+
+// New creates a queue backed by the given analysis_queue.json
+func New(path string) *Queue {
+	return &Queue{path: path}
+}
+
+// document is the file layout: {"analyses": {...}}. Unknown top-level
+// keys are carried through untouched.
+type document struct {
+	Analyses map[string]Entry
+	extra    map[string]json.RawMessage
+}
+
+func (q *Queue) read() (*document, error) {
+	doc := &document{
+		Analyses: map[string]Entry{},
+		extra:    map[string]json.RawMessage{},
+	}
+
+	data, err := os.ReadFile(q.path)
+	if os.IsNotExist(err) {
+		return doc, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading queue: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &doc.extra); err != nil {
+		return nil, fmt.Errorf("parsing queue: %w", err)
+	}
+	if raw, ok := doc.extra["analyses"]; ok {
+		if err := json.Unmarshal(raw, &doc.Analyses); err != nil {
+			return nil, fmt.Errorf("parsing analyses: %w", err)
+		}
+		delete(doc.extra, "analyses")
+	}
+	if doc.Analyses == nil {
+		doc.Analyses = map[string]Entry{}
+	}
+	return doc, nil
+}
+
+func (q *Queue) write(doc *document) error {
+	out := map[string]interface{}{}
+	for key, value := range doc.extra {
+		out[key] = value
+	}
+	out["analyses"] = doc.Analyses
+
+	data, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding queue: %w", err)
+	}
+	return fsutil.WriteFileAtomic(q.path, append(data, '\n'), 0644)
+}
+
+// update runs fn on the current document under the queue lock and saves it
+func (q *Queue) update(fn func(*document)) error {
+	if err := os.MkdirAll(filepath.Dir(q.path), 0755); err != nil {
+		return fmt.Errorf("creating queue directory: %w", err)
+	}
+	unlock, err := fsutil.Lock(q.path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	doc, err := q.read()
+	if err != nil {
+		return err
+	}
+	fn(doc)
+	return q.write(doc)
+}
+
+// Check reports whether analysisType is clear to run, i.e. has no
+// pending review (ks_queue_check)
+func (q *Queue) Check(analysisType string) (bool, error) {
+	doc, err := q.read()
+	if err != nil {
+		return false, err
+	}
+	return doc.Analyses[analysisType].Status != StatusPendingReview, nil
+}
+
+// AddPending records findingsFile as awaiting review (ks_queue_add_pending)
+func (q *Queue) AddPending(analysisType, findingsFile string) error {
+	return q.update(func(doc *document) {
+		doc.Analyses[analysisType] = Entry{
+			Status:       StatusPendingReview,
+			FindingsFile: findingsFile,
+			CompletedAt:  time.Now().UTC().Format("2006-01-02T15:04:05Z"),
+		}
+	})
+}
+
+// Clear removes analysisType after review (ks_queue_clear)
+func (q *Queue) Clear(analysisType string) error {
+	return q.update(func(doc *document) {
+		delete(doc.Analyses, analysisType)
+	})
+}
+
+// ListPending returns analyses awaiting review, oldest first
+// (ks_queue_list_pending)
+func (q *Queue) ListPending() ([]Pending, error) {
+	doc, err := q.read()
+	if err != nil {
+		return nil, err
+	}
+
+	pending := []Pending{}
+	for analysisType, entry := range doc.Analyses {
+		if entry.Status == StatusPendingReview {
+			pending = append(pending, Pending{Type: analysisType, Value: entry})
+		}
+	}
+	sort.Slice(pending, func(i, j int) bool {
+		if pending[i].Value.CompletedAt != pending[j].Value.CompletedAt {
+			return pending[i].Value.CompletedAt < pending[j].Value.CompletedAt
+		}
+		return pending[i].Type < pending[j].Type
+	})
+	return pending, nil
+}
@@ -0,0 +1,69 @@
+package queue
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// Rewriting the queue keeps fields queue.sh and other tools added, at the
+// top level and per entry, and drops only what was cleared
+func TestQueueKeepsUnknownFields(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "analysis_queue.json")
+	initial := `{
+  "analyses": {
+    "theme-analysis": {"status": "pending_review", "findings_file": "/f/themes.json", "completed_at": "2025-06-17T10:00:00Z", "retries": 2},
+    "connections": {"status": "pending_review", "findings_file": "/f/conn.json", "completed_at": "2025-06-16T10:00:00Z"}
+  },
+  "version": 1
+}`
+	if err := os.WriteFile(path, []byte(initial), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	q := New(path)
+	pending, err := q.ListPending()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var types []string
+	for _, p := range pending {
+		types = append(types, p.Type)
+	}
+	if want := []string{"connections", "theme-analysis"}; !reflect.DeepEqual(types, want) {
+		t.Errorf("pending = %v, want oldest first %v", types, want)
+	}
+
+	if err := q.Clear("connections"); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got map[string]interface{}
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{
+		"analyses": map[string]interface{}{
+			"theme-analysis": map[string]interface{}{
+				"status": "pending_review", "findings_file": "/f/themes.json",
+				"completed_at": "2025-06-17T10:00:00Z", "retries": float64(2),
+			},
+		},
+		"version": float64(1),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("after Clear:\n%s\nwant %v", data, want)
+	}
+
+	if clear, err := q.Check("connections"); err != nil || !clear {
+		t.Errorf("Check(connections) = %v, %v; want clear", clear, err)
+	}
+	if clear, err := q.Check("theme-analysis"); err != nil || clear {
+		t.Errorf("Check(theme-analysis) = %v, %v; want pending", clear, err)
+	}
+}
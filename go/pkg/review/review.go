@@ -0,0 +1,499 @@
+package review
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/durapensa/ks/pkg/config"
+	"github.com/durapensa/ks/pkg/fsutil"
+	"github.com/durapensa/ks/pkg/queue"
+)
+
+// Defaults of the INTROSPECT --batch-size and --confidence-threshold options
+const (
+	DefaultBatchSize           = 5
+	DefaultConfidenceThreshold = 0.5
+)
+
+// RejectionReasons are the reasons review-findings accepts; anything else
+// is recorded as "other"
+var RejectionReasons = []string{"too_vague", "not_relevant", "duplicate", "other"}
+
+// Options are the INTROSPECT options that shape a review session
+type Options struct {
+	// BatchSize is how many findings are reviewed at once
+	BatchSize int
+	// ConfidenceThreshold hides findings whose confidence is below it
+	ConfidenceThreshold float64
+	// AutoApprove approves findings at or above the threshold unseen
+	AutoApprove bool
+}
+
+// DefaultOptions returns the option defaults of review-findings
+func DefaultOptions() Options {
+	return Options{
+		BatchSize:           DefaultBatchSize,
+		ConfidenceThreshold: DefaultConfidenceThreshold,
+	}
+}
+
+// Finding is one element of a findings file. The analyses emit different
+// shapes (themes, connections, patterns), so fields are read on demand.
+type Finding struct {
+	Raw json.RawMessage
+	// Index is the finding's position in its batch
+	Index  int
+	fields map[string]interface{}
+}
+
+func (f Finding) str(keys ...string) string {
+	for _, key := range keys {
+		switch v := f.fields[key].(type) {
+		case string:
+			if v != "" {
+				return v
+			}
+		case float64:
+			return fmt.Sprint(v)
+		}
+	}
+	return ""
+}
+
+func (f Finding) list(keys ...string) []string {
+	for _, key := range keys {
+		switch v := f.fields[key].(type) {
+		case []interface{}:
+			var items []string
+			for _, item := range v {
+				if s, ok := item.(string); ok {
+					items = append(items, s)
+				} else if item != nil {
+					data, _ := json.Marshal(item)
+					items = append(items, string(data))
+				}
+			}
+			if len(items) > 0 {
+				return items
+			}
+		case string:
+			if v != "" {
+				return []string{v}
+			}
+		}
+	}
+	return nil
+}
+
+// Name is the theme or finding name
+func (f Finding) Name() string { return f.str("name") }
+
+// Description is the finding's description
+func (f Finding) Description() string { return f.str("description") }
+
+// Pattern is set for recurring thought pattern findings
+func (f Finding) Pattern() string { return f.str("pattern") }
+
+// Type is the finding's own type, "theme" when it has none
+func (f Finding) Type() string {
+	if t := f.str("type"); t != "" {
+		return t
+	}
+	return "theme"
+}
+
+// Content is the main text, chosen the way review-findings does
+func (f Finding) Content() string {
+	if name := f.Name(); name != "" {
+		if description := f.Description(); description != "" {
+			return name + ": " + description
+		}
+		return name
+	}
+	return f.str("content", "theme", "insight", "pattern", "description")
+}
+
+// Relationship describes a connection finding
+func (f Finding) Relationship() string { return f.str("relationship") }
+
+// Significance explains why a pattern matters
+func (f Finding) Significance() string { return f.str("significance") }
+
+// Occurrences is how often a pattern was seen
+func (f Finding) Occurrences() string { return f.str("occurrences") }
+
+// ConfidenceLabel is the displayed confidence: .confidence // .frequency
+func (f Finding) ConfidenceLabel() string { return f.str("confidence", "frequency") }
+
+// Confidence is the numeric .confidence, if the analysis provided one
+func (f Finding) Confidence() (float64, bool) {
+	v, ok := f.fields["confidence"].(float64)
+	return v, ok
+}
+
+// Examples are a pattern's examples
+func (f Finding) Examples() []string { return f.list("examples") }
+
+// Quotes is the supporting evidence: .context // .supporting_quotes
+func (f Finding) Quotes() []string { return f.list("context", "supporting_quotes") }
+
+// Events are the events a connection links
+func (f Finding) Events() []string { return f.list("events") }
+
+// Batch is one pending analysis and its findings
+type Batch struct {
+	Type         string
+	FindingsFile string
+	CompletedAt  string
+	Findings     []Finding
+}
+
+// Load reads the findings of a pending queue entry. Files wrap the list as
+// {"findings": [...]}; a bare array is accepted too. A null or empty list
+// loads as a batch with no findings.
+func Load(pending queue.Pending) (*Batch, error) {
+	batch := &Batch{
+		Type:         pending.Type,
+		FindingsFile: pending.Value.FindingsFile,
+		CompletedAt:  pending.Value.CompletedAt,
+	}
+
+	data, err := os.ReadFile(batch.FindingsFile)
+	if err != nil {
+		return nil, fmt.Errorf("reading findings: %w", err)
+	}
+
+	var items []json.RawMessage
+	var wrapped struct {
+		Findings json.RawMessage `json:"findings"`
+	}
+	if err := json.Unmarshal(data, &wrapped); err == nil {
+		if len(wrapped.Findings) > 0 && string(wrapped.Findings) != "null" {
+			if err := json.Unmarshal(wrapped.Findings, &items); err != nil {
+				return nil, fmt.Errorf("parsing findings: %w", err)
+			}
+		}
+	} else if err := json.Unmarshal(data, &items); err != nil {
+		return nil, fmt.Errorf("parsing findings: %w", err)
+	}
+
+	for _, item := range items {
+		if string(item) == "null" {
+			continue
+		}
+		finding := Finding{Index: len(batch.Findings), fields: map[string]interface{}{}}
+		var compact bytes.Buffer
+		if err := json.Compact(&compact, item); err != nil {
+			continue
+		}
+		finding.Raw = compact.Bytes()
+		json.Unmarshal(item, &finding.fields)
+		batch.Findings = append(batch.Findings, finding)
+	}
+	return batch, nil
+}
+
+// Partition splits findings by the confidence options: hidden fall below
+// the threshold, auto are approved without review, and manual need a
+// decision. Findings without a numeric confidence are always manual.
+func (o Options) Partition(findings []Finding) (manual, auto, hidden []Finding) {
+	for _, f := range findings {
+		confidence, ok := f.Confidence()
+		switch {
+		case !ok:
+			manual = append(manual, f)
+		case confidence < o.ConfidenceThreshold:
+			hidden = append(hidden, f)
+		case o.AutoApprove:
+			auto = append(auto, f)
+		default:
+			manual = append(manual, f)
+		}
+	}
+	return manual, auto, hidden
+}
+
+// Reviewer records decisions in KS_DERIVED_DIR and settles the queue
+type Reviewer struct {
+	derivedDir    string
+	backgroundDir string
+	model         string
+	queue         *queue.Queue
+}
+
+// NewReviewer creates a reviewer for the knowledge base in cfg
+func NewReviewer(cfg *config.Config) *Reviewer {
+	model := cfg.Model
+	if model == "" {
+		model = "sonnet"
+	}
+	return &Reviewer{
+		derivedDir:    cfg.DerivedDir,
+		backgroundDir: cfg.BackgroundDir,
+		model:         model,
+		queue:         queue.New(cfg.AnalysisQueue),
+	}
+}
+
+// approvedEntry is a line of derived/approved.jsonl
+type approvedEntry struct {
+	Timestamp string           `json:"ts"`
+	Type      string           `json:"type"`
+	Finding   json.RawMessage  `json:"finding"`
+	Metadata  approvedMetadata `json:"metadata"`
+}
+
+type approvedMetadata struct {
+	Source       string `json:"source"`
+	ApprovedAt   string `json:"approved_at"`
+	AnalysisType string `json:"analysis_type"`
+	Model        string `json:"model"`
+	CompletedAt  string `json:"completed_at"`
+}
+
+// rejectedEntry is a line of derived/rejected.jsonl
+type rejectedEntry struct {
+	Timestamp    string          `json:"ts"`
+	AnalysisType string          `json:"analysis_type"`
+	Finding      json.RawMessage `json:"finding"`
+	Rejection    rejection       `json:"rejection"`
+}
+
+type rejection struct {
+	Reason     string `json:"reason"`
+	Notes      string `json:"notes"`
+	RejectedAt string `json:"rejected_at"`
+}
+
+// EventType maps an analysis to the event type of its approved findings
+func EventType(analysisType string) string {
+	switch analysisType {
+	case "connections", "connection-analysis":
+		return "connection"
+	}
+	return "insight"
+}
+
+func timestamp() string {
+	return time.Now().UTC().Format("2006-01-02T15:04:05Z")
+}
+
+// Approve appends the finding to derived/approved.jsonl
+func (r *Reviewer) Approve(b *Batch, f Finding) error {
+	now := timestamp()
+	completedAt := b.CompletedAt
+	if completedAt == "" {
+		completedAt = "unknown"
+	}
+	return r.append("approved.jsonl", approvedEntry{
+		Timestamp: now,
+		Type:      EventType(b.Type),
+		Finding:   f.Raw,
+		Metadata: approvedMetadata{
+			Source:       "background-analysis",
+			ApprovedAt:   now,
+			AnalysisType: b.Type,
+			Model:        r.model,
+			CompletedAt:  completedAt,
+		},
+	})
+}
+
+// Reject appends the finding to derived/rejected.jsonl for future learning
+func (r *Reviewer) Reject(b *Batch, f Finding, reason, notes string) error {
+	valid := false
+	for _, known := range RejectionReasons {
+		if reason == known {
+			valid = true
+		}
+	}
+	if !valid {
+		reason = "other"
+	}
+
+	now := timestamp()
+	return r.append("rejected.jsonl", rejectedEntry{
+		Timestamp:    now,
+		AnalysisType: b.Type,
+		Finding:      f.Raw,
+		Rejection:    rejection{Reason: reason, Notes: notes, RejectedAt: now},
+	})
+}
+
+func (r *Reviewer) append(name string, entry interface{}) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("encoding %s entry: %w", name, err)
+	}
+	line = append(line, '\n')
+
+	if err := os.MkdirAll(r.derivedDir, 0755); err != nil {
+		return fmt.Errorf("creating derived directory: %w", err)
+	}
+	path := filepath.Join(r.derivedDir, name)
+	unlock, err := fsutil.Lock(path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("opening %s: %w", name, err)
+	}
+	defer file.Close()
+	if _, err := file.Write(line); err != nil {
+		return fmt.Errorf("writing %s: %w", name, err)
+	}
+	return nil
+}
+
+// Decision values
+const (
+	Approved = "approved"
+	Rejected = "rejected"
+	Skipped  = "skipped"
+)
+
+// Decision is the verdict on one finding. Decisions are kept beside the
+// findings file until the review finishes, so a paused review resumes
+// where it stopped.
+type Decision struct {
+	Index    int    `json:"index"`
+	Decision string `json:"decision"`
+	Reason   string `json:"reason,omitempty"`
+	Notes    string `json:"notes,omitempty"`
+	// Written is set once Finish has appended the decision to approved.jsonl
+	// or rejected.jsonl, so finishing again doesn't write it twice
+	Written bool `json:"written,omitempty"`
+}
+
+// Outcome counts what Finish settled
+type Outcome struct {
+	Approved  int
+	Rejected  int
+	Skipped   int
+	Undecided int // Findings without a decision, such as those below the confidence threshold
+}
+
+// decisionsFile is the sidecar holding a batch's decisions so far
+func (b *Batch) decisionsFile() string {
+	return b.FindingsFile + ".decisions"
+}
+
+// Decisions returns the decisions recorded for the batch by finding index;
+// a later decision on the same finding replaces an earlier one
+func (b *Batch) Decisions() (map[int]Decision, error) {
+	decisions := map[int]Decision{}
+	data, err := os.ReadFile(b.decisionsFile())
+	if os.IsNotExist(err) {
+		return decisions, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading decisions: %w", err)
+	}
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		var d Decision
+		if len(bytes.TrimSpace(line)) == 0 || json.Unmarshal(line, &d) != nil {
+			continue
+		}
+		if d.Index >= 0 && d.Index < len(b.Findings) {
+			decisions[d.Index] = d
+		}
+	}
+	return decisions, nil
+}
+
+// Decide records a decision in the batch's sidecar. Nothing reaches
+// approved.jsonl or rejected.jsonl until Finish.
+func (r *Reviewer) Decide(b *Batch, d Decision) error {
+	line, err := json.Marshal(d)
+	if err != nil {
+		return fmt.Errorf("encoding decision: %w", err)
+	}
+	file, err := os.OpenFile(b.decisionsFile(), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("recording decision: %w", err)
+	}
+	defer file.Close()
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("recording decision: %w", err)
+	}
+	return nil
+}
+
+// Finish writes the recorded decisions to approved.jsonl and rejected.jsonl
+// and clears the analysis from the queue. Batches that had findings are
+// archived to KS_BACKGROUND_DIR/reviewed, as review-findings does.
+// Findings without a decision are counted in the outcome but written
+// nowhere. Finish can be retried after an error without writing any
+// decision twice.
+func (r *Reviewer) Finish(b *Batch) (*Outcome, error) {
+	decisions, err := b.Decisions()
+	if err != nil {
+		return nil, err
+	}
+	outcome := &Outcome{}
+	for _, f := range b.Findings {
+		d, ok := decisions[f.Index]
+		switch {
+		case !ok:
+			outcome.Undecided++
+			continue
+		case d.Decision == Approved:
+			outcome.Approved++
+		case d.Decision == Rejected:
+			outcome.Rejected++
+		default:
+			outcome.Skipped++
+			continue
+		}
+		if d.Written {
+			continue
+		}
+		if d.Decision == Approved {
+			err = r.Approve(b, f)
+		} else {
+			err = r.Reject(b, f, d.Reason, d.Notes)
+		}
+		if err != nil {
+			return nil, err
+		}
+		d.Written = true
+		if err := r.Decide(b, d); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := r.queue.Clear(b.Type); err != nil {
+		return nil, err
+	}
+	if err := os.Remove(b.decisionsFile()); err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("removing decisions: %w", err)
+	}
+	if len(b.Findings) == 0 {
+		return outcome, nil
+	}
+
+	archiveDir := filepath.Join(r.backgroundDir, "reviewed")
+	if err := os.MkdirAll(archiveDir, 0755); err != nil {
+		return nil, fmt.Errorf("creating reviewed directory: %w", err)
+	}
+	target := filepath.Join(archiveDir, filepath.Base(b.FindingsFile)+"."+time.Now().Format("20060102-150405"))
+	if err := os.Rename(b.FindingsFile, target); err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("archiving findings: %w", err)
+	}
+	return outcome, nil
+}
+
+// Summary describes a batch for the pending list
+func (b *Batch) Summary() string {
+	if len(b.Findings) == 0 {
+		return "Empty (no findings)"
+	}
+	return fmt.Sprintf("%d findings", len(b.Findings))
+}
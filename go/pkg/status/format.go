@@ -0,0 +1,162 @@
+package status
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Formats accepted by Write
+var Formats = []string{"text", "json", "prometheus"}
+
+// Write renders the report in one of Formats
+func (r *Report) Write(w io.Writer, format string) error {
+	switch format {
+	case "text":
+		return r.WriteText(w)
+	case "json":
+		return r.WriteJSON(w)
+	case "prometheus":
+		return r.WritePrometheus(w)
+	}
+	return fmt.Errorf("unknown format %q (want %s)", format, strings.Join(Formats, ", "))
+}
+
+// WriteJSON writes the report as indented JSON
+func (r *Report) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+// WriteText writes the report for people
+func (r *Report) WriteText(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("Knowledge System Status\n")
+	b.WriteString("────────────────────────\n")
+	if r.Context.Conversation {
+		fmt.Fprintf(&b, "Context:          %s (conversation)\n", r.Context.Name)
+	}
+	fmt.Fprintf(&b, "Knowledge:        %s\n", r.Context.KnowledgeDir)
+	fmt.Fprintf(&b, "Events:           %d hot, %d archived in %d files\n",
+		r.Events.Hot, r.Events.Archive, r.Events.ArchiveFiles)
+
+	fmt.Fprintf(&b, "Pending reviews:  %d", r.Reviews.Pending)
+	if len(r.Reviews.Analyses) > 0 {
+		fmt.Fprintf(&b, " (%s)", strings.Join(r.Reviews.Analyses, ", "))
+	}
+	b.WriteString("\n")
+	fmt.Fprintf(&b, "Processes:        %d active, %d stale, %d failed\n",
+		r.Processes.Active, r.Processes.Stale, r.Processes.Failed)
+
+	b.WriteString("Triggers:\n")
+	for _, t := range r.Triggers {
+		state := fmt.Sprintf("%d/%d events, %d to go", t.EventsSince, t.Threshold, t.Remaining)
+		switch {
+		case t.Pending:
+			state = "review pending"
+		case t.Ready:
+			state = "ready"
+		}
+		fmt.Fprintf(&b, "  %-20s %s\n", t.Analysis, state)
+	}
+
+	if r.KG.Available {
+		fmt.Fprintf(&b, "Knowledge graph:  %d concepts, %d edges, %d aliases",
+			r.KG.Concepts, r.KG.Edges, r.KG.Aliases)
+		if r.KG.LastRunStatus != "" {
+			fmt.Fprintf(&b, " (last distillation %s %s)", r.KG.LastRunStatus, r.KG.LastRunAt)
+		}
+		b.WriteString("\n")
+	} else {
+		fmt.Fprintf(&b, "Knowledge graph:  not found at %s\n", r.KG.Path)
+	}
+
+	rot := r.Rotation
+	fmt.Fprintf(&b, "Rotation:         %d/%d events, %d/%d bytes, oldest %dh/%dh",
+		rot.HotEvents, rot.MaxEvents, rot.HotSizeBytes, rot.MaxSizeBytes, rot.OldestEventHours, rot.MaxAgeHours)
+	if rot.Due {
+		fmt.Fprintf(&b, " - due (%s)", strings.Join(rot.Reasons, ", "))
+	}
+	b.WriteString("\n")
+
+	for _, e := range r.Errors {
+		fmt.Fprintf(&b, "Error: %s\n", e)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WritePrometheus writes the report in the Prometheus text exposition
+// format, e.g. for node_exporter's textfile collector
+func (r *Report) WritePrometheus(w io.Writer) error {
+	var b strings.Builder
+	metric := func(name, kind, help string, samples ...string) {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+		for _, s := range samples {
+			fmt.Fprintf(&b, "%s%s\n", name, s)
+		}
+	}
+	value := func(labels string, v interface{}) string {
+		if labels != "" {
+			labels = "{" + labels + "}"
+		}
+		return fmt.Sprintf("%s %v", labels, v)
+	}
+	label := func(name, v string) string {
+		return name + "=" + strconv.Quote(v)
+	}
+
+	metric("ks_events", "gauge", "Events in the hot log and archives.",
+		value(label("log", "hot"), r.Events.Hot),
+		value(label("log", "archive"), r.Events.Archive))
+	metric("ks_archive_files", "gauge", "Archived event log files.", value("", r.Events.ArchiveFiles))
+	metric("ks_reviews_pending", "gauge", "Analyses waiting for review.", value("", r.Reviews.Pending))
+	metric("ks_processes", "gauge", "Background process registry entries by state.",
+		value(label("state", "active"), r.Processes.Active),
+		value(label("state", "stale"), r.Processes.Stale),
+		value(label("state", "failed"), r.Processes.Failed))
+
+	var since, threshold, ready, pending []string
+	for _, t := range r.Triggers {
+		l := label("analysis", t.Analysis)
+		since = append(since, value(l, t.EventsSince))
+		threshold = append(threshold, value(l, t.Threshold))
+		ready = append(ready, value(l, boolValue(t.Ready)))
+		pending = append(pending, value(l, boolValue(t.Pending)))
+	}
+	metric("ks_trigger_events_since", "gauge", "Events since the analysis last ran.", since...)
+	metric("ks_trigger_threshold", "gauge", "Events needed to trigger the analysis.", threshold...)
+	metric("ks_trigger_ready", "gauge", "Whether the analysis threshold is reached.", ready...)
+	metric("ks_trigger_review_pending", "gauge", "Whether earlier findings await review.", pending...)
+
+	metric("ks_kg_available", "gauge", "Whether the knowledge graph database exists.", value("", boolValue(r.KG.Available)))
+	metric("ks_kg_concepts", "gauge", "Concepts in the knowledge graph.", value("", r.KG.Concepts))
+	metric("ks_kg_edges", "gauge", "Edges in the knowledge graph.", value("", r.KG.Edges))
+	metric("ks_kg_aliases", "gauge", "Concept aliases in the knowledge graph.", value("", r.KG.Aliases))
+	metric("ks_kg_distillation_runs", "gauge", "Recorded distillation runs.", value("", r.KG.DistillationRuns))
+
+	rot := r.Rotation
+	metric("ks_rotation_threshold", "gauge", "rotate-logs thresholds.",
+		value(label("kind", "size_bytes"), rot.MaxSizeBytes),
+		value(label("kind", "events"), rot.MaxEvents),
+		value(label("kind", "age_hours"), rot.MaxAgeHours))
+	metric("ks_rotation_current", "gauge", "Hot log measures compared against the rotation thresholds.",
+		value(label("kind", "size_bytes"), rot.HotSizeBytes),
+		value(label("kind", "events"), rot.HotEvents),
+		value(label("kind", "age_hours"), rot.OldestEventHours))
+	metric("ks_rotation_due", "gauge", "Whether rotate-logs would rotate the hot log.", value("", boolValue(rot.Due)))
+	metric("ks_status_errors", "gauge", "Report sections that could not be read.", value("", len(r.Errors)))
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func boolValue(v bool) int {
+	if v {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,303 @@
+package status
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/durapensa/ks/pkg/config"
+	"github.com/durapensa/ks/pkg/events"
+	"github.com/durapensa/ks/pkg/kg"
+	"github.com/durapensa/ks/pkg/process"
+	"github.com/durapensa/ks/pkg/queue"
+	"github.com/durapensa/ks/pkg/triggers"
+)
+
+// Default thresholds of tools/plumbing/rotate-logs
+const (
+	DefaultRotateMaxSize   = 5242880 // bytes
+	DefaultRotateMaxEvents = 1000
+	DefaultRotateMaxAge    = 168 // hours
+)
+
+// Report is a snapshot of the knowledge system. Field names are part of the
+// JSON and Prometheus output; add fields rather than renaming them.
+type Report struct {
+	GeneratedAt string    `json:"generated_at"`
+	Context     Context   `json:"context"`
+	Events      Events    `json:"events"`
+	Reviews     Reviews   `json:"reviews"`
+	Processes   Processes `json:"processes"`
+	Triggers    []Trigger `json:"triggers"`
+	KG          KG        `json:"kg"`
+	Rotation    Rotation  `json:"rotation"`
+	Errors      []string  `json:"errors,omitempty"`
+}
+
+// Context identifies the knowledge base the report describes
+type Context struct {
+	Root         string `json:"root"`
+	KnowledgeDir string `json:"knowledge_dir"`
+	HotLog       string `json:"hot_log"`
+	Conversation bool   `json:"conversation"`
+	Name         string `json:"name,omitempty"`
+}
+
+// Events counts lines in the hot log and its archives
+type Events struct {
+	Hot          int `json:"hot"`
+	Archive      int `json:"archive"`
+	ArchiveFiles int `json:"archive_files"`
+	Total        int `json:"total"`
+}
+
+// Reviews lists analyses waiting for review
+type Reviews struct {
+	Pending  int      `json:"pending"`
+	Analyses []string `json:"analyses"`
+}
+
+// Processes counts background process registry entries
+type Processes struct {
+	Active int `json:"active"`
+	Stale  int `json:"stale"`
+	Failed int `json:"failed"`
+}
+
+// Trigger is the readiness of one background analysis
+type Trigger struct {
+	Analysis    string `json:"analysis"`
+	Threshold   int    `json:"threshold"`
+	EventsSince int    `json:"events_since"`
+	Remaining   int    `json:"remaining"`
+	Ready       bool   `json:"ready"`
+	Pending     bool   `json:"pending"`
+}
+
+// KG summarises the knowledge graph database
+type KG struct {
+	Path             string `json:"path"`
+	Available        bool   `json:"available"`
+	Concepts         int    `json:"concepts"`
+	Edges            int    `json:"edges"`
+	Aliases          int    `json:"aliases"`
+	DistillationRuns int    `json:"distillation_runs"`
+	LastRunStatus    string `json:"last_run_status,omitempty"`
+	LastRunAt        string `json:"last_run_at,omitempty"`
+}
+
+// Rotation compares the hot log with the rotate-logs thresholds
+type Rotation struct {
+	MaxSizeBytes     int64    `json:"max_size_bytes"`
+	MaxEvents        int      `json:"max_events"`
+	MaxAgeHours      int      `json:"max_age_hours"`
+	HotSizeBytes     int64    `json:"hot_size_bytes"`
+	HotEvents        int      `json:"hot_events"`
+	OldestEventHours int      `json:"oldest_event_age_hours"`
+	Due              bool     `json:"due"`
+	Reasons          []string `json:"reasons"`
+}
+
+// Collect builds a report for cfg. Sections that cannot be read are left
+// empty and noted in Errors, so one broken file doesn't hide the rest.
+func Collect(cfg *config.Config, now time.Time) *Report {
+	r := &Report{
+		GeneratedAt: now.UTC().Format(time.RFC3339),
+		Context: Context{
+			Root:         cfg.KSRoot,
+			KnowledgeDir: cfg.KnowledgeDir,
+			HotLog:       cfg.HotLog,
+			Conversation: cfg.IsConversation,
+			Name:         cfg.ContextName,
+		},
+		Triggers: []Trigger{},
+		Reviews:  Reviews{Analyses: []string{}},
+		Rotation: Rotation{Reasons: []string{}},
+	}
+	note := func(section string, err error) {
+		r.Errors = append(r.Errors, fmt.Sprintf("%s: %v", section, err))
+	}
+
+	if err := r.collectEvents(cfg); err != nil {
+		note("events", err)
+	}
+
+	if pending, err := queue.New(cfg.AnalysisQueue).ListPending(); err != nil {
+		note("reviews", err)
+	} else {
+		r.Reviews.Pending = len(pending)
+		for _, p := range pending {
+			r.Reviews.Analyses = append(r.Reviews.Analyses, p.Type)
+		}
+	}
+
+	if err := r.collectProcesses(cfg, now); err != nil {
+		note("processes", err)
+	}
+
+	if st, err := triggers.New(cfg).Status(); err != nil {
+		note("triggers", err)
+	} else {
+		for _, t := range st.Triggers {
+			r.Triggers = append(r.Triggers, Trigger{
+				Analysis:    t.Analysis.Name,
+				Threshold:   t.Threshold,
+				EventsSince: t.EventsSince,
+				Remaining:   t.Remaining,
+				Ready:       t.Ready,
+				Pending:     t.Pending,
+			})
+		}
+	}
+
+	if err := r.collectKG(cfg); err != nil {
+		note("kg", err)
+	}
+
+	if err := r.collectRotation(cfg, now); err != nil {
+		note("rotation", err)
+	}
+	return r
+}
+
+func (r *Report) collectEvents(cfg *config.Config) error {
+	hot, err := events.CountLines(cfg.HotLog)
+	if err != nil {
+		return err
+	}
+	r.Events.Hot = hot
+
+	archives, err := filepath.Glob(filepath.Join(cfg.ArchiveDir, "*.jsonl"))
+	if err != nil {
+		return err
+	}
+	for _, archive := range archives {
+		count, err := events.CountLines(archive)
+		if err != nil {
+			return err
+		}
+		r.Events.Archive += count
+	}
+	r.Events.ArchiveFiles = len(archives)
+	r.Events.Total = r.Events.Hot + r.Events.Archive
+	return nil
+}
+
+func (r *Report) collectProcesses(cfg *config.Config, now time.Time) error {
+	registry := process.NewRegistry(cfg.ProcessRegistry)
+	active, err := registry.Active()
+	if err != nil {
+		return err
+	}
+	for i := range active {
+		if active[i].Stale(now) {
+			r.Processes.Stale++
+		} else {
+			r.Processes.Active++
+		}
+	}
+
+	failed, err := registry.Failed()
+	if err != nil {
+		return err
+	}
+	r.Processes.Failed = len(failed)
+	return nil
+}
+
+func (r *Report) collectKG(cfg *config.Config) error {
+	r.KG.Path = kg.Path(cfg)
+	db, err := kg.OpenReadOnly(r.KG.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	stats, err := db.Stats()
+	if err != nil {
+		return err
+	}
+	r.KG = KG{
+		Path:             r.KG.Path,
+		Available:        true,
+		Concepts:         stats.Concepts,
+		Edges:            stats.Edges,
+		Aliases:          stats.Aliases,
+		DistillationRuns: stats.DistillationRuns,
+		LastRunStatus:    stats.LastRunStatus,
+		LastRunAt:        stats.LastRunAt,
+	}
+	return nil
+}
+
+// collectRotation applies the checks of rotate-logs' should_rotate
+func (r *Report) collectRotation(cfg *config.Config, now time.Time) error {
+	rot := &r.Rotation
+	rot.MaxSizeBytes = DefaultRotateMaxSize
+	rot.MaxEvents = DefaultRotateMaxEvents
+	rot.MaxAgeHours = DefaultRotateMaxAge
+	rot.HotEvents = r.Events.Hot
+
+	stat, err := os.Stat(cfg.HotLog)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	rot.HotSizeBytes = stat.Size()
+
+	oldest, err := firstEventTime(cfg.HotLog)
+	if err != nil {
+		return err
+	}
+	if !oldest.IsZero() {
+		rot.OldestEventHours = max(int(now.Sub(oldest).Hours()), 0)
+	}
+
+	if rot.HotSizeBytes > rot.MaxSizeBytes {
+		rot.Reasons = append(rot.Reasons, "size")
+	}
+	if rot.HotEvents > rot.MaxEvents {
+		rot.Reasons = append(rot.Reasons, "events")
+	}
+	if rot.OldestEventHours > rot.MaxAgeHours {
+		rot.Reasons = append(rot.Reasons, "age")
+	}
+	rot.Due = len(rot.Reasons) > 0
+	return nil
+}
+
+// firstEventTime reads the timestamp of the first line, as rotate-logs
+// does with head -1
+func firstEventTime(path string) (time.Time, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	if !scanner.Scan() {
+		return time.Time{}, scanner.Err()
+	}
+	var first struct {
+		Timestamp string `json:"ts"`
+	}
+	if json.Unmarshal(scanner.Bytes(), &first) != nil {
+		return time.Time{}, nil
+	}
+	ts, err := time.Parse(time.RFC3339, first.Timestamp)
+	if err != nil {
+		return time.Time{}, nil
+	}
+	return ts, nil
+}
@@ -0,0 +1,405 @@
+package triggers
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/durapensa/ks/pkg/config"
+	"github.com/durapensa/ks/pkg/events"
+	"github.com/durapensa/ks/pkg/fsutil"
+	"github.com/durapensa/ks/pkg/process"
+	"github.com/durapensa/ks/pkg/query"
+	"github.com/durapensa/ks/pkg/queue"
+)
+
+// StateFile is the state check-event-triggers keeps in KS_BACKGROUND_DIR
+const StateFile = ".event_trigger_state"
+
+// Analysis describes one event-count triggered background analysis
+type Analysis struct {
+	// Name is the analysis type used in the queue (e.g. theme-analysis)
+	Name string
+	// Label is the short name shown in the dashboard
+	Label string
+	// ThresholdVar overrides DefaultThreshold when set in .ks-env
+	ThresholdVar     string
+	DefaultThreshold int
+
+	// Tool is relative to KS_TOOLS_DIR
+	Tool string
+	Args []string
+	// AllEvents pipes the hot log and archives to the tool's stdin
+	AllEvents bool
+
+	// FindingsPrefix names the findings file; ResultKey is the field of
+	// the tool's JSON output that becomes {findings: ...}
+	FindingsPrefix string
+	ResultKey      string
+}
+
+// Analyses are the triggers of check-event-triggers, in the order it
+// checks them
+var Analyses = []Analysis{
+	{
+		Name:             "theme-analysis",
+		Label:            "Theme",
+		ThresholdVar:     "KS_EVENT_THRESHOLD_THEMES",
+		DefaultThreshold: 10,
+		Tool:             "analyze/extract-themes",
+		Args:             []string{"--days", "3", "--format", "json"},
+		FindingsPrefix:   "themes",
+		ResultKey:        "themes",
+	},
+	{
+		Name:             "connection-analysis",
+		Label:            "Connections",
+		ThresholdVar:     "KS_EVENT_THRESHOLD_CONNECTIONS",
+		DefaultThreshold: 20,
+		Tool:             "analyze/find-connections",
+		Args:             []string{"--days", "7", "--format", "json"},
+		FindingsPrefix:   "connections",
+		ResultKey:        "connections",
+	},
+	{
+		Name:             "pattern-analysis",
+		Label:            "Patterns",
+		ThresholdVar:     "KS_EVENT_THRESHOLD_PATTERNS",
+		DefaultThreshold: 30,
+		Tool:             "analyze/identify-recurring-thought-patterns",
+		Args:             []string{"--days", "14", "--format", "json"},
+		AllEvents:        true,
+		FindingsPrefix:   "patterns",
+		ResultKey:        "patterns",
+	},
+}
+
+// State mirrors .event_trigger_state
+type State struct {
+	LastCount             int    `json:"last_count"`
+	LastThemeTrigger      int    `json:"last_theme_trigger"`
+	LastConnectionTrigger int    `json:"last_connection_trigger"`
+	LastPatternTrigger    int    `json:"last_pattern_trigger"`
+	LastCheck             string `json:"last_check"`
+}
+
+// lastTrigger returns the state field recording when name last ran
+func (s *State) lastTrigger(name string) *int {
+	switch name {
+	case "theme-analysis":
+		return &s.LastThemeTrigger
+	case "connection-analysis":
+		return &s.LastConnectionTrigger
+	case "pattern-analysis":
+		return &s.LastPatternTrigger
+	}
+	return nil
+}
+
+// Readiness is how close one analysis is to triggering
+type Readiness struct {
+	Analysis    Analysis
+	Threshold   int
+	EventsSince int
+	// Remaining is the number of events still needed, 0 when ready
+	Remaining int
+	Ready     bool
+	// Pending means earlier findings await review, which blocks a new run
+	Pending bool
+}
+
+// Status is the trigger state at one point in time
+type Status struct {
+	Count    int
+	State    State
+	Triggers []Readiness
+}
+
+// Readiness returns the entry for the named analysis
+func (s *Status) Readiness(name string) (Readiness, bool) {
+	for _, r := range s.Triggers {
+		if r.Analysis.Name == name {
+			return r, true
+		}
+	}
+	return Readiness{}, false
+}
+
+// Engine evaluates and fires triggers for one knowledge directory
+type Engine struct {
+	cfg       *config.Config
+	statePath string
+	queue     *queue.Queue
+	registry  *process.Registry
+}
+
+// New creates an engine using the paths and thresholds in cfg
+func New(cfg *config.Config) *Engine {
+	return &Engine{
+		cfg:       cfg,
+		statePath: filepath.Join(cfg.BackgroundDir, StateFile),
+		queue:     queue.New(cfg.AnalysisQueue),
+		registry:  process.NewRegistry(cfg.ProcessRegistry),
+	}
+}
+
+// Threshold returns the event count that triggers a, honouring the
+// KS_EVENT_THRESHOLD_* overrides
+func (e *Engine) Threshold(a Analysis) int {
+	if value, ok := e.cfg.Env[a.ThresholdVar]; ok {
+		if n, err := strconv.Atoi(value); err == nil && n > 0 {
+			return n
+		}
+	}
+	return a.DefaultThreshold
+}
+
+// LoadState reads .event_trigger_state; a missing file is the zero state
+func (e *Engine) LoadState() (State, error) {
+	var state State
+	data, err := os.ReadFile(e.statePath)
+	if os.IsNotExist(err) {
+		return state, nil
+	}
+	if err != nil {
+		return state, fmt.Errorf("reading trigger state: %w", err)
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return state, fmt.Errorf("parsing trigger state: %w", err)
+	}
+	return state, nil
+}
+
+// SaveState replaces .event_trigger_state
+func (e *Engine) SaveState(state State) error {
+	if err := os.MkdirAll(filepath.Dir(e.statePath), 0755); err != nil {
+		return fmt.Errorf("creating background directory: %w", err)
+	}
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding trigger state: %w", err)
+	}
+	return fsutil.WriteFileAtomic(e.statePath, append(data, '\n'), 0644)
+}
+
+// CountEvents counts hot log lines, as ks_count_new_events does
+func (e *Engine) CountEvents() (int, error) {
+	return events.CountLines(e.cfg.HotLog)
+}
+
+// Evaluate computes readiness for every analysis at the given event count.
+// It does not consult the queue, so Pending is always false.
+func (e *Engine) Evaluate(count int, state State) []Readiness {
+	triggers := make([]Readiness, 0, len(Analyses))
+	for _, a := range Analyses {
+		threshold := e.Threshold(a)
+		since := count - *state.lastTrigger(a.Name)
+		r := Readiness{
+			Analysis:    a,
+			Threshold:   threshold,
+			EventsSince: since,
+			Ready:       since >= threshold,
+		}
+		if !r.Ready {
+			r.Remaining = threshold - since
+		}
+		triggers = append(triggers, r)
+	}
+	return triggers
+}
+
+// Status reads the current count, state and queue without changing them
+func (e *Engine) Status() (*Status, error) {
+	state, err := e.LoadState()
+	if err != nil {
+		return nil, err
+	}
+	count, err := e.CountEvents()
+	if err != nil {
+		return nil, err
+	}
+
+	status := &Status{Count: count, State: state, Triggers: e.Evaluate(count, state)}
+	for i := range status.Triggers {
+		clear, err := e.queue.Check(status.Triggers[i].Analysis.Name)
+		if err != nil {
+			return nil, err
+		}
+		status.Triggers[i].Pending = !clear
+	}
+	return status, nil
+}
+
+// Check is check-event-triggers: when events were added since the last
+// check it starts every ready analysis that has no pending review, then
+// records the new count. An analysis that fails to start keeps its old
+// state, and the count isn't advanced, so the next check tries it again.
+// The returned runs finish in the background, even after the caller exits.
+func (e *Engine) Check() ([]*Run, error) {
+	status, err := e.Status()
+	if err != nil {
+		return nil, err
+	}
+
+	state := status.State
+	if status.Count <= state.LastCount {
+		return nil, nil
+	}
+
+	var runs []*Run
+	var firstErr error
+	for _, r := range status.Triggers {
+		if !r.Ready || r.Pending {
+			continue
+		}
+		run, err := e.Spawn(r.Analysis)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		runs = append(runs, run)
+		*state.lastTrigger(r.Analysis.Name) = status.Count
+	}
+
+	if firstErr == nil {
+		state.LastCount = status.Count
+	}
+	state.LastCheck = time.Now().UTC().Format("2006-01-02T15:04:05Z")
+	if err := e.SaveState(state); err != nil {
+		return runs, err
+	}
+	return runs, firstErr
+}
+
+// Run is a spawned analysis
+type Run struct {
+	Analysis     Analysis
+	FindingsFile string
+	Process      *process.Process
+	done         chan error
+}
+
+// Wait blocks until the analysis has finished and its findings are queued
+func (r *Run) Wait() error {
+	return <-r.done
+}
+
+// runScript runs one analysis detached from whoever spawned it, like the
+// subshells of check-event-triggers, so the findings are queued and the
+// registry record completed even if ksd quits first. It reads the record
+// path from stdin, which Spawn writes once the record is registered.
+//
+// Arguments: tool, result key, analysis type, findings file, number of
+// tool arguments, the tool arguments, then event files to pipe to the tool.
+const runScript = `
+read -r record || true
+tool=$1 key=$2 analysis=$3 findings=$4 nargs=$5
+shift 5
+args=("${@:1:nargs}")
+inputs=("${@:nargs+1}")
+
+source "$KS_ROOT/lib/core.sh"
+source "$KS_ROOT/tools/lib/queue.sh"
+
+if (( ${#inputs[@]} )); then
+    output=$(cat "${inputs[@]}" | "$tool" "${args[@]}" 2>/dev/null)
+else
+    output=$("$tool" "${args[@]}" 2>/dev/null </dev/null)
+fi
+
+status=failed
+if [[ -n "$output" ]] && jq --arg key "$key" '{findings: .[$key]}' <<< "$output" > "$findings" && [[ -s "$findings" ]]; then
+    ks_queue_add_pending "$analysis" "$findings" && status=success
+fi
+if [[ "$status" != success ]]; then
+    rm -f "$findings"
+    findings=""
+fi
+
+# As ks_complete_background_process, for the record Spawn registered
+if [[ -n "$record" && -f "$record" ]]; then
+    target="${record%/active/*}/$status/${record##*/}"
+    mkdir -p "${target%/*}"
+    jq --arg end_time "$(ks_timestamp)" \
+       --arg end_epoch "$(date +%s)" \
+       --arg status "$status" \
+       --arg output "$findings" \
+       '. + {end_time: $end_time, end_epoch: ($end_epoch | tonumber), status: $status, output_file: $output}' \
+       "$record" > "$target.tmp" && mv "$target.tmp" "$target" && rm -f "$record"
+fi
+[[ "$status" == success ]]
+`
+
+// Spawn starts a in the background and registers it in the process
+// registry. The detached child writes the findings file, queues it for
+// review and completes the record itself.
+func (e *Engine) Spawn(a Analysis) (*Run, error) {
+	findingsDir := filepath.Join(e.cfg.BackgroundDir, "findings")
+	if err := os.MkdirAll(findingsDir, 0755); err != nil {
+		return nil, fmt.Errorf("creating findings directory: %w", err)
+	}
+	run := &Run{
+		Analysis: a,
+		FindingsFile: filepath.Join(findingsDir,
+			fmt.Sprintf("%s-%s.json", a.FindingsPrefix, time.Now().Format("20060102-150405"))),
+		done: make(chan error, 1),
+	}
+
+	args := []string{"-c", runScript, "ks-" + a.Name,
+		filepath.Join(e.cfg.ToolsDir, a.Tool), a.ResultKey, a.Name, run.FindingsFile,
+		strconv.Itoa(len(a.Args))}
+	args = append(args, a.Args...)
+	if a.AllEvents {
+		files := query.New(e.cfg.HotLog, e.cfg.ArchiveDir).Files(time.Time{})
+		if len(files) == 0 {
+			return nil, fmt.Errorf("%s: no event files to analyze", a.Name)
+		}
+		args = append(args, files...)
+	}
+
+	cmd := exec.Command("bash", args...)
+	cmd.Env = os.Environ()
+	for key, value := range e.cfg.Env {
+		cmd.Env = append(cmd.Env, key+"="+value)
+	}
+	if e.cfg.IsConversation {
+		cmd.Dir = e.cfg.ConversationDir
+	}
+	// A session of its own keeps the analysis running when ksd exits
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("starting %s: %w", a.Name, err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("starting %s: %w", a.Name, err)
+	}
+
+	proc, err := e.registry.Register(a.Name, cmd.Process.Pid, "Event-triggered "+a.Label+" analysis")
+	if err != nil {
+		proc = nil // The analysis still runs; it just isn't tracked
+	}
+	run.Process = proc
+	record := ""
+	if proc != nil {
+		record = proc.File
+	}
+	fmt.Fprintln(stdin, record)
+	stdin.Close()
+
+	go func() {
+		if err := cmd.Wait(); err != nil {
+			run.done <- fmt.Errorf("%s: %w", a.Name, err)
+			return
+		}
+		run.done <- nil
+	}()
+	return run, nil
+}
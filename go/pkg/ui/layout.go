@@ -0,0 +1,23 @@
+package ui
+
+import "strings"
+
+// DefaultWidth is used until the terminal reports its size
+const DefaultWidth = 80
+
+// MinWidth keeps layouts readable in very narrow terminals
+const MinWidth = 40
+
+// Width is the layout width for a terminal width from tea.WindowSizeMsg;
+// 0 means no size has arrived yet
+func Width(terminal int) int {
+	if terminal <= 0 {
+		return DefaultWidth
+	}
+	return max(terminal, MinWidth)
+}
+
+// Rule is a horizontal line of char across width columns
+func (t Theme) Rule(char string, width int) string {
+	return t.Separator.Render(strings.Repeat(char, max(width, 1)))
+}
@@ -0,0 +1,165 @@
+package ui
+
+import (
+	"os"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Theme names. Auto picks Dark or Light from the terminal background.
+const (
+	Auto         = "auto"
+	Dark         = "dark"
+	Light        = "light"
+	HighContrast = "high-contrast"
+)
+
+// Names lists the themes accepted by New
+var Names = []string{Auto, Dark, Light, HighContrast}
+
+// Palette is the set of colours a theme is built from
+type Palette struct {
+	Title       lipgloss.Color
+	Header      lipgloss.Color
+	Muted       lipgloss.Color // Status lines and secondary text
+	Warning     lipgloss.Color
+	OK          lipgloss.Color
+	Help        lipgloss.Color
+	Border      lipgloss.Color
+	Text        lipgloss.Color
+	SelectedFg  lipgloss.Color
+	SelectedBg  lipgloss.Color
+	HighlightFg lipgloss.Color
+	HighlightBg lipgloss.Color
+	// Series tells apart items such as conversants
+	Series []lipgloss.Color
+}
+
+// Palettes by theme name
+var Palettes = map[string]Palette{
+	Dark: {
+		Title: "205", Header: "212", Muted: "248", Warning: "214", OK: "46",
+		Help: "241", Border: "237", Text: "252",
+		SelectedFg: "255", SelectedBg: "237",
+		HighlightFg: "229", HighlightBg: "58",
+		Series: []lipgloss.Color{"39", "208", "141", "114", "204", "220"},
+	},
+	Light: {
+		Title: "162", Header: "90", Muted: "240", Warning: "130", OK: "28",
+		Help: "244", Border: "250", Text: "236",
+		SelectedFg: "232", SelectedBg: "254",
+		HighlightFg: "94", HighlightBg: "229",
+		Series: []lipgloss.Color{"25", "166", "91", "28", "161", "136"},
+	},
+	HighContrast: {
+		Title: "15", Header: "15", Muted: "15", Warning: "11", OK: "10",
+		Help: "15", Border: "15", Text: "15",
+		SelectedFg: "0", SelectedBg: "15",
+		HighlightFg: "0", HighlightBg: "11",
+		Series: []lipgloss.Color{"14", "11", "13", "10", "9", "15"},
+	},
+}
+
+// Theme is the resolved set of styles the screens render with
+type Theme struct {
+	// Name is the palette in use, "none" when NO_COLOR is set
+	Name string
+
+	Title     lipgloss.Style
+	Header    lipgloss.Style
+	Label     lipgloss.Style
+	Status    lipgloss.Style
+	Pending   lipgloss.Style
+	Ready     lipgloss.Style
+	Help      lipgloss.Style
+	Separator lipgloss.Style
+	Selected  lipgloss.Style
+	Normal    lipgloss.Style
+	Highlight lipgloss.Style
+	Series    []lipgloss.Style
+
+	// Pane and FocusedPane frame the panes of a split layout
+	Pane        lipgloss.Style
+	FocusedPane lipgloss.Style
+}
+
+// NoColor reports whether colour is turned off, see https://no-color.org
+func NoColor() bool {
+	return os.Getenv("NO_COLOR") != ""
+}
+
+// Resolve maps a theme name to a palette name. Auto asks the terminal for
+// its background, so call it before the program takes over the screen.
+// Unknown names fall back to Dark.
+func Resolve(name string) string {
+	if name == "" || name == Auto {
+		if lipgloss.HasDarkBackground() {
+			return Dark
+		}
+		return Light
+	}
+	if _, ok := Palettes[name]; !ok {
+		return Dark
+	}
+	return name
+}
+
+// New builds the theme called name. With NO_COLOR set the styles keep
+// bold and reverse video but use no colours.
+func New(name string) Theme {
+	if NoColor() {
+		return plain()
+	}
+	name = Resolve(name)
+	p := Palettes[name]
+
+	t := Theme{
+		Name:      name,
+		Title:     lipgloss.NewStyle().Bold(true).Foreground(p.Title).MarginBottom(1),
+		Header:    lipgloss.NewStyle().Bold(true).Foreground(p.Header).MarginBottom(1),
+		Label:     lipgloss.NewStyle().Bold(true).Foreground(p.Header),
+		Status:    lipgloss.NewStyle().Foreground(p.Muted),
+		Pending:   lipgloss.NewStyle().Foreground(p.Warning),
+		Ready:     lipgloss.NewStyle().Foreground(p.OK),
+		Help:      lipgloss.NewStyle().Foreground(p.Help).MarginTop(1),
+		Separator: lipgloss.NewStyle().Foreground(p.Border),
+		Selected:  lipgloss.NewStyle().Background(p.SelectedBg).Foreground(p.SelectedFg),
+		Normal:    lipgloss.NewStyle().Foreground(p.Text),
+		Highlight: lipgloss.NewStyle().Bold(true).Background(p.HighlightBg).Foreground(p.HighlightFg),
+
+		Pane:        lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).BorderForeground(p.Border),
+		FocusedPane: lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).BorderForeground(p.Title),
+	}
+	for _, c := range p.Series {
+		t.Series = append(t.Series, lipgloss.NewStyle().Bold(true).Foreground(c))
+	}
+	return t
+}
+
+// plain is the colourless theme used under NO_COLOR; the focused pane
+// gets a thick border instead of a colour
+func plain() Theme {
+	return Theme{
+		Name:      "none",
+		Title:     lipgloss.NewStyle().Bold(true).MarginBottom(1),
+		Header:    lipgloss.NewStyle().Bold(true).MarginBottom(1),
+		Label:     lipgloss.NewStyle().Bold(true),
+		Status:    lipgloss.NewStyle(),
+		Pending:   lipgloss.NewStyle().Bold(true),
+		Ready:     lipgloss.NewStyle(),
+		Help:      lipgloss.NewStyle().MarginTop(1),
+		Separator: lipgloss.NewStyle(),
+		Selected:  lipgloss.NewStyle().Reverse(true),
+		Normal:    lipgloss.NewStyle(),
+		Highlight: lipgloss.NewStyle().Bold(true).Underline(true),
+		Series:    []lipgloss.Style{lipgloss.NewStyle().Bold(true)},
+
+		Pane:        lipgloss.NewStyle().Border(lipgloss.NormalBorder()),
+		FocusedPane: lipgloss.NewStyle().Border(lipgloss.ThickBorder()),
+	}
+}
+
+// SeriesStyle is the style of the i-th item of a series, wrapping around
+func (t Theme) SeriesStyle(i int) lipgloss.Style {
+	return t.Series[i%len(t.Series)]
+}
@@ -14,6 +14,15 @@ ks_timestamp() {
     date -u '+%Y-%m-%dT%H:%M:%SZ'
 }
 
+ks_lock_file() {
+    # Lock file for appending to or replacing a log, the same file
+    # pkg/fsutil.LockPath names for Go writers. Never remove it: flock locks
+    # belong to the inode, so a recreated file would not exclude a writer
+    # still holding the old one. Closing the descriptor releases the lock.
+    # Usage: ( flock 200; ... ) 200>"$(ks_lock_file "$LOG")"
+    echo "${1}.lock"
+}
+
 ks_sanitize_string() {
     # Basic sanitization for user input to prevent command injection
     # Usage: CLEAN_VAR=$(ks_sanitize_string "$USER_INPUT")
@@ -38,14 +38,18 @@ ks_queue_add_pending() {
     local findings_file="$2"
     ks_queue_init
     
-    local temp_file=$(mktemp)
-    jq --arg type "$analysis_type" \
-       --arg file "$findings_file" \
-       --arg time "$(ks_timestamp)" \
-       '.analyses[$type] = {status: "pending_review", findings_file: $file, completed_at: $time}' \
-       "$KS_ANALYSIS_QUEUE" > "$temp_file"
-    
-    mv "$temp_file" "$KS_ANALYSIS_QUEUE"
+    # Same lock and same-directory rename as the Go queue package (pkg/queue)
+    (
+        flock 200
+        local temp_file=$(mktemp "${KS_ANALYSIS_QUEUE}.XXXXXX")
+        jq --arg type "$analysis_type" \
+           --arg file "$findings_file" \
+           --arg time "$(ks_timestamp)" \
+           '.analyses[$type] = {status: "pending_review", findings_file: $file, completed_at: $time}' \
+           "$KS_ANALYSIS_QUEUE" > "$temp_file"
+        
+        mv "$temp_file" "$KS_ANALYSIS_QUEUE"
+    ) 200>"$(ks_lock_file "$KS_ANALYSIS_QUEUE")"
 }
 
 ks_queue_clear() {
@@ -55,12 +59,15 @@ ks_queue_clear() {
     local analysis_type="$1"
     ks_queue_init
     
-    local temp_file=$(mktemp)
-    jq --arg type "$analysis_type" \
-       'del(.analyses[$type])' \
-       "$KS_ANALYSIS_QUEUE" > "$temp_file"
-    
-    mv "$temp_file" "$KS_ANALYSIS_QUEUE"
+    (
+        flock 200
+        local temp_file=$(mktemp "${KS_ANALYSIS_QUEUE}.XXXXXX")
+        jq --arg type "$analysis_type" \
+           'del(.analyses[$type])' \
+           "$KS_ANALYSIS_QUEUE" > "$temp_file"
+        
+        mv "$temp_file" "$KS_ANALYSIS_QUEUE"
+    ) 200>"$(ks_lock_file "$KS_ANALYSIS_QUEUE")"
 }
 
 ks_queue_list_pending() {